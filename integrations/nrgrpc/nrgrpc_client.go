@@ -45,7 +45,7 @@ func startClientSegment(ctx context.Context, method, target string) (*pinpoint.E
 		seg.Procedure = method
 
 		hdrs := http.Header{}
-		txn.InsertDistributedTraceHeaders(hdrs, seg.NextSpanID)
+		seg.InsertDistributedTraceHeaders(hdrs)
 		if len(hdrs) > 0 {
 			md, ok := metadata.FromOutgoingContext(ctx)
 			if !ok {
@@ -8,9 +8,11 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/dingyalin/pinpoint-go-agent/internal"
 	pinpoint "github.com/dingyalin/pinpoint-go-agent/pinpoint"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -31,11 +33,20 @@ func startTransaction(ctx context.Context, app *pinpoint.Application, fullMethod
 	url := getURL(method, target)
 
 	webReq := pinpoint.WebRequest{
-		Header:    hdrs,
-		URL:       url,
-		Method:    method,
+		Header: hdrs,
+		URL:    url,
+		Method: method,
+		// The authority is the host the call was sent to, reported as the
+		// end point of the span like the Host header of HTTP requests.
+		Host:      target,
 		Transport: pinpoint.TransportHTTP,
 	}
+	if p, ok := peer.FromContext(ctx); ok && nil != p.Addr {
+		webReq.RemoteAddr = p.Addr.String()
+	}
+	if nil != app {
+		internal.RecordService(app.Private, "grpc", method)
+	}
 	txn := app.StartTransaction(method)
 	txn.SetWebRequest(webReq)
 
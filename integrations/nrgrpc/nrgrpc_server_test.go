@@ -103,6 +103,7 @@ func TestUnaryServerInterceptor(t *testing.T) {
 			"httpResponseCode":            0,
 			"http.statusCode":             0,
 			"request.headers.contentType": "application/grpc",
+			"request.headers.host":        "bufnet",
 			"request.method":              "TestApplication/DoUnaryUnary",
 			"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryUnary",
 		},
@@ -136,6 +137,7 @@ func TestUnaryServerInterceptor(t *testing.T) {
 				"parent.transportType":        "HTTP",
 				"parent.type":                 "App",
 				"request.headers.contentType": "application/grpc",
+				"request.headers.host":        "bufnet",
 				"request.method":              "TestApplication/DoUnaryUnary",
 				"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryUnary",
 			},
@@ -186,6 +188,7 @@ func TestUnaryServerInterceptorError(t *testing.T) {
 			"httpResponseCode":            15,
 			"http.statusCode":             15,
 			"request.headers.contentType": "application/grpc",
+			"request.headers.host":        "bufnet",
 			"request.method":              "TestApplication/DoUnaryUnaryError",
 			"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryUnaryError",
 		},
@@ -207,6 +210,7 @@ func TestUnaryServerInterceptorError(t *testing.T) {
 			"request.headers.User-Agent":  internal.MatchAnything,
 			"request.headers.userAgent":   internal.MatchAnything,
 			"request.headers.contentType": "application/grpc",
+			"request.headers.host":        "bufnet",
 			"request.method":              "TestApplication/DoUnaryUnaryError",
 			"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryUnaryError",
 		},
@@ -280,6 +284,7 @@ func TestUnaryStreamServerInterceptor(t *testing.T) {
 			"httpResponseCode":            0,
 			"http.statusCode":             0,
 			"request.headers.contentType": "application/grpc",
+			"request.headers.host":        "bufnet",
 			"request.method":              "TestApplication/DoUnaryStream",
 			"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryStream",
 		},
@@ -313,6 +318,7 @@ func TestUnaryStreamServerInterceptor(t *testing.T) {
 				"parent.transportType":        "HTTP",
 				"parent.type":                 "App",
 				"request.headers.contentType": "application/grpc",
+				"request.headers.host":        "bufnet",
 				"request.method":              "TestApplication/DoUnaryStream",
 				"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryStream",
 			},
@@ -384,6 +390,7 @@ func TestStreamUnaryServerInterceptor(t *testing.T) {
 			"httpResponseCode":            0,
 			"http.statusCode":             0,
 			"request.headers.contentType": "application/grpc",
+			"request.headers.host":        "bufnet",
 			"request.method":              "TestApplication/DoStreamUnary",
 			"request.uri":                 "grpc://bufnet/TestApplication/DoStreamUnary",
 		},
@@ -417,6 +424,7 @@ func TestStreamUnaryServerInterceptor(t *testing.T) {
 				"parent.transportType":        "HTTP",
 				"parent.type":                 "App",
 				"request.headers.contentType": "application/grpc",
+				"request.headers.host":        "bufnet",
 				"request.method":              "TestApplication/DoStreamUnary",
 				"request.uri":                 "grpc://bufnet/TestApplication/DoStreamUnary",
 			},
@@ -501,6 +509,7 @@ func TestStreamStreamServerInterceptor(t *testing.T) {
 			"httpResponseCode":            0,
 			"http.statusCode":             0,
 			"request.headers.contentType": "application/grpc",
+			"request.headers.host":        "bufnet",
 			"request.method":              "TestApplication/DoStreamStream",
 			"request.uri":                 "grpc://bufnet/TestApplication/DoStreamStream",
 		},
@@ -534,6 +543,7 @@ func TestStreamStreamServerInterceptor(t *testing.T) {
 				"parent.transportType":        "HTTP",
 				"parent.type":                 "App",
 				"request.headers.contentType": "application/grpc",
+				"request.headers.host":        "bufnet",
 				"request.method":              "TestApplication/DoStreamStream",
 				"request.uri":                 "grpc://bufnet/TestApplication/DoStreamStream",
 			},
@@ -588,6 +598,7 @@ func TestStreamServerInterceptorError(t *testing.T) {
 			"httpResponseCode":            15,
 			"http.statusCode":             15,
 			"request.headers.contentType": "application/grpc",
+			"request.headers.host":        "bufnet",
 			"request.method":              "TestApplication/DoUnaryStreamError",
 			"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryStreamError",
 		},
@@ -609,6 +620,7 @@ func TestStreamServerInterceptorError(t *testing.T) {
 			"request.headers.User-Agent":  internal.MatchAnything,
 			"request.headers.userAgent":   internal.MatchAnything,
 			"request.headers.contentType": "application/grpc",
+			"request.headers.host":        "bufnet",
 			"request.method":              "TestApplication/DoUnaryStreamError",
 			"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryStreamError",
 		},
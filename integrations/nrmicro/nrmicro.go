@@ -36,7 +36,9 @@ func startExternal(ctx context.Context, procedure, host string) (context.Context
 			Host:       host,
 			NextSpanID: txn.NextSpanID(),
 		}
-		ctx = addDTPayloadToContext(ctx, txn, seg.NextSpanID)
+		hdrs := http.Header{}
+		seg.InsertDistributedTraceHeaders(hdrs)
+		ctx = addDTPayloadToContext(ctx, hdrs)
 	}
 	return ctx, seg
 }
@@ -50,14 +52,14 @@ func startMessage(ctx context.Context, topic string) (context.Context, *pinpoint
 			DestinationType: pinpoint.MessageTopic,
 			DestinationName: topic,
 		}
-		ctx = addDTPayloadToContext(ctx, txn, 0)
+		hdrs := http.Header{}
+		txn.InsertDistributedTraceHeaders(hdrs, 0)
+		ctx = addDTPayloadToContext(ctx, hdrs)
 	}
 	return ctx, seg
 }
 
-func addDTPayloadToContext(ctx context.Context, txn *pinpoint.Transaction, nextSpanID int64) context.Context {
-	hdrs := http.Header{}
-	txn.InsertDistributedTraceHeaders(hdrs, nextSpanID)
+func addDTPayloadToContext(ctx context.Context, hdrs http.Header) context.Context {
 	if len(hdrs) > 0 {
 		md, _ := metadata.FromContext(ctx)
 		md = metadata.Copy(md)
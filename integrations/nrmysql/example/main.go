@@ -29,6 +29,7 @@ func main() {
 	if nil != err {
 		panic(err)
 	}
+	app.RegisterSQLDB(db, pinpoint.DatastoreMySQL, "information_schema", "root:123456@(127.0.0.1:3306)/information_schema")
 	app.WaitForConnection(5 * time.Second)
 	txn := app.StartTransaction("mysqlQuery")
 
@@ -20,5 +20,7 @@ const (
 	PinpointPspanidName  = "Pinpoint-Pspanid"
 	PinpointSpanidName   = "Pinpoint-Spanid"
 	PinpointFlagsName    = "Pinpoint-Flags"
+	PinpointSampledName  = "Pinpoint-Sampled"
+	PinpointHostName     = "Pinpoint-Host"
 	// PinpointResponseTraceidName = "detector_txd"
 )
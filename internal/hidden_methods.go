@@ -39,3 +39,16 @@ func AddAgentSpanAttribute(txn interface{}, key string, val string) {
 		aa.AddAgentSpanAttribute(key, val)
 	}
 }
+
+// ServiceRecorder is implemented by pinpoint.Application.
+type ServiceRecorder interface {
+	RecordService(kind string, name string)
+}
+
+// RecordService allows instrumentation packages to register the service
+// entry points of the application, such as the methods of a gRPC server.
+func RecordService(app interface{}, kind string, name string) {
+	if r, ok := app.(ServiceRecorder); ok {
+		r.RecordService(kind, name)
+	}
+}
@@ -0,0 +1,3203 @@
+//go:build go1.9
+// +build go1.9
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: internal/pinpoint_grpc_v1/v1.proto
+
+package pinpoint_grpc_v1
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	empty "github.com/golang/protobuf/ptypes/empty"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type PJvmGcType int32
+
+const (
+	PJvmGcType_JVM_GC_TYPE_UNKNOWN  PJvmGcType = 0
+	PJvmGcType_JVM_GC_TYPE_SERIAL   PJvmGcType = 1
+	PJvmGcType_JVM_GC_TYPE_PARALLEL PJvmGcType = 2
+	PJvmGcType_JVM_GC_TYPE_CMS      PJvmGcType = 3
+	PJvmGcType_JVM_GC_TYPE_G1       PJvmGcType = 4
+)
+
+var PJvmGcType_name = map[int32]string{
+	0: "JVM_GC_TYPE_UNKNOWN",
+	1: "JVM_GC_TYPE_SERIAL",
+	2: "JVM_GC_TYPE_PARALLEL",
+	3: "JVM_GC_TYPE_CMS",
+	4: "JVM_GC_TYPE_G1",
+}
+
+var PJvmGcType_value = map[string]int32{
+	"JVM_GC_TYPE_UNKNOWN":  0,
+	"JVM_GC_TYPE_SERIAL":   1,
+	"JVM_GC_TYPE_PARALLEL": 2,
+	"JVM_GC_TYPE_CMS":      3,
+	"JVM_GC_TYPE_G1":       4,
+}
+
+func (x PJvmGcType) String() string {
+	return proto.EnumName(PJvmGcType_name, int32(x))
+}
+
+func (PJvmGcType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{0}
+}
+
+type PResult struct {
+	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PResult) Reset()         { *m = PResult{} }
+func (m *PResult) String() string { return proto.CompactTextString(m) }
+func (*PResult) ProtoMessage()    {}
+func (*PResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{0}
+}
+
+func (m *PResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PResult.Unmarshal(m, b)
+}
+func (m *PResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PResult.Marshal(b, m, deterministic)
+}
+func (m *PResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PResult.Merge(m, src)
+}
+func (m *PResult) XXX_Size() int {
+	return xxx_messageInfo_PResult.Size(m)
+}
+func (m *PResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_PResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PResult proto.InternalMessageInfo
+
+func (m *PResult) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *PResult) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type PPing struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PPing) Reset()         { *m = PPing{} }
+func (m *PPing) String() string { return proto.CompactTextString(m) }
+func (*PPing) ProtoMessage()    {}
+func (*PPing) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{1}
+}
+
+func (m *PPing) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PPing.Unmarshal(m, b)
+}
+func (m *PPing) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PPing.Marshal(b, m, deterministic)
+}
+func (m *PPing) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PPing.Merge(m, src)
+}
+func (m *PPing) XXX_Size() int {
+	return xxx_messageInfo_PPing.Size(m)
+}
+func (m *PPing) XXX_DiscardUnknown() {
+	xxx_messageInfo_PPing.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PPing proto.InternalMessageInfo
+
+type PAgentInfo struct {
+	Hostname             string           `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Ip                   string           `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Ports                string           `protobuf:"bytes,3,opt,name=ports,proto3" json:"ports,omitempty"`
+	ServiceType          int32            `protobuf:"varint,4,opt,name=serviceType,proto3" json:"serviceType,omitempty"`
+	Pid                  int32            `protobuf:"varint,5,opt,name=pid,proto3" json:"pid,omitempty"`
+	AgentVersion         string           `protobuf:"bytes,6,opt,name=agentVersion,proto3" json:"agentVersion,omitempty"`
+	VmVersion            string           `protobuf:"bytes,7,opt,name=vmVersion,proto3" json:"vmVersion,omitempty"`
+	EndTimestamp         int64            `protobuf:"varint,8,opt,name=endTimestamp,proto3" json:"endTimestamp,omitempty"`
+	EndStatus            int32            `protobuf:"varint,9,opt,name=endStatus,proto3" json:"endStatus,omitempty"`
+	ServerMetaData       *PServerMetaData `protobuf:"bytes,10,opt,name=serverMetaData,proto3" json:"serverMetaData,omitempty"`
+	Container            bool             `protobuf:"varint,12,opt,name=container,proto3" json:"container,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PAgentInfo) Reset()         { *m = PAgentInfo{} }
+func (m *PAgentInfo) String() string { return proto.CompactTextString(m) }
+func (*PAgentInfo) ProtoMessage()    {}
+func (*PAgentInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{2}
+}
+
+func (m *PAgentInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PAgentInfo.Unmarshal(m, b)
+}
+func (m *PAgentInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PAgentInfo.Marshal(b, m, deterministic)
+}
+func (m *PAgentInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PAgentInfo.Merge(m, src)
+}
+func (m *PAgentInfo) XXX_Size() int {
+	return xxx_messageInfo_PAgentInfo.Size(m)
+}
+func (m *PAgentInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_PAgentInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PAgentInfo proto.InternalMessageInfo
+
+func (m *PAgentInfo) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
+func (m *PAgentInfo) GetIp() string {
+	if m != nil {
+		return m.Ip
+	}
+	return ""
+}
+
+func (m *PAgentInfo) GetPorts() string {
+	if m != nil {
+		return m.Ports
+	}
+	return ""
+}
+
+func (m *PAgentInfo) GetServiceType() int32 {
+	if m != nil {
+		return m.ServiceType
+	}
+	return 0
+}
+
+func (m *PAgentInfo) GetPid() int32 {
+	if m != nil {
+		return m.Pid
+	}
+	return 0
+}
+
+func (m *PAgentInfo) GetAgentVersion() string {
+	if m != nil {
+		return m.AgentVersion
+	}
+	return ""
+}
+
+func (m *PAgentInfo) GetVmVersion() string {
+	if m != nil {
+		return m.VmVersion
+	}
+	return ""
+}
+
+func (m *PAgentInfo) GetEndTimestamp() int64 {
+	if m != nil {
+		return m.EndTimestamp
+	}
+	return 0
+}
+
+func (m *PAgentInfo) GetEndStatus() int32 {
+	if m != nil {
+		return m.EndStatus
+	}
+	return 0
+}
+
+func (m *PAgentInfo) GetServerMetaData() *PServerMetaData {
+	if m != nil {
+		return m.ServerMetaData
+	}
+	return nil
+}
+
+func (m *PAgentInfo) GetContainer() bool {
+	if m != nil {
+		return m.Container
+	}
+	return false
+}
+
+type PServerMetaData struct {
+	ServerInfo           string          `protobuf:"bytes,1,opt,name=serverInfo,proto3" json:"serverInfo,omitempty"`
+	VmArg                []string        `protobuf:"bytes,2,rep,name=vmArg,proto3" json:"vmArg,omitempty"`
+	ServiceInfo          []*PServiceInfo `protobuf:"bytes,3,rep,name=serviceInfo,proto3" json:"serviceInfo,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *PServerMetaData) Reset()         { *m = PServerMetaData{} }
+func (m *PServerMetaData) String() string { return proto.CompactTextString(m) }
+func (*PServerMetaData) ProtoMessage()    {}
+func (*PServerMetaData) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{3}
+}
+
+func (m *PServerMetaData) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PServerMetaData.Unmarshal(m, b)
+}
+func (m *PServerMetaData) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PServerMetaData.Marshal(b, m, deterministic)
+}
+func (m *PServerMetaData) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PServerMetaData.Merge(m, src)
+}
+func (m *PServerMetaData) XXX_Size() int {
+	return xxx_messageInfo_PServerMetaData.Size(m)
+}
+func (m *PServerMetaData) XXX_DiscardUnknown() {
+	xxx_messageInfo_PServerMetaData.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PServerMetaData proto.InternalMessageInfo
+
+func (m *PServerMetaData) GetServerInfo() string {
+	if m != nil {
+		return m.ServerInfo
+	}
+	return ""
+}
+
+func (m *PServerMetaData) GetVmArg() []string {
+	if m != nil {
+		return m.VmArg
+	}
+	return nil
+}
+
+func (m *PServerMetaData) GetServiceInfo() []*PServiceInfo {
+	if m != nil {
+		return m.ServiceInfo
+	}
+	return nil
+}
+
+type PServiceInfo struct {
+	ServiceName          string   `protobuf:"bytes,1,opt,name=serviceName,proto3" json:"serviceName,omitempty"`
+	ServiceLib           []string `protobuf:"bytes,2,rep,name=serviceLib,proto3" json:"serviceLib,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PServiceInfo) Reset()         { *m = PServiceInfo{} }
+func (m *PServiceInfo) String() string { return proto.CompactTextString(m) }
+func (*PServiceInfo) ProtoMessage()    {}
+func (*PServiceInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{4}
+}
+
+func (m *PServiceInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PServiceInfo.Unmarshal(m, b)
+}
+func (m *PServiceInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PServiceInfo.Marshal(b, m, deterministic)
+}
+func (m *PServiceInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PServiceInfo.Merge(m, src)
+}
+func (m *PServiceInfo) XXX_Size() int {
+	return xxx_messageInfo_PServiceInfo.Size(m)
+}
+func (m *PServiceInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_PServiceInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PServiceInfo proto.InternalMessageInfo
+
+func (m *PServiceInfo) GetServiceName() string {
+	if m != nil {
+		return m.ServiceName
+	}
+	return ""
+}
+
+func (m *PServiceInfo) GetServiceLib() []string {
+	if m != nil {
+		return m.ServiceLib
+	}
+	return nil
+}
+
+type PApiMetaData struct {
+	ApiId                int32    `protobuf:"varint,1,opt,name=apiId,proto3" json:"apiId,omitempty"`
+	ApiInfo              string   `protobuf:"bytes,2,opt,name=apiInfo,proto3" json:"apiInfo,omitempty"`
+	Line                 int32    `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	Type                 int32    `protobuf:"varint,4,opt,name=type,proto3" json:"type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PApiMetaData) Reset()         { *m = PApiMetaData{} }
+func (m *PApiMetaData) String() string { return proto.CompactTextString(m) }
+func (*PApiMetaData) ProtoMessage()    {}
+func (*PApiMetaData) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{5}
+}
+
+func (m *PApiMetaData) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PApiMetaData.Unmarshal(m, b)
+}
+func (m *PApiMetaData) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PApiMetaData.Marshal(b, m, deterministic)
+}
+func (m *PApiMetaData) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PApiMetaData.Merge(m, src)
+}
+func (m *PApiMetaData) XXX_Size() int {
+	return xxx_messageInfo_PApiMetaData.Size(m)
+}
+func (m *PApiMetaData) XXX_DiscardUnknown() {
+	xxx_messageInfo_PApiMetaData.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PApiMetaData proto.InternalMessageInfo
+
+func (m *PApiMetaData) GetApiId() int32 {
+	if m != nil {
+		return m.ApiId
+	}
+	return 0
+}
+
+func (m *PApiMetaData) GetApiInfo() string {
+	if m != nil {
+		return m.ApiInfo
+	}
+	return ""
+}
+
+func (m *PApiMetaData) GetLine() int32 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+func (m *PApiMetaData) GetType() int32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+type PSqlMetaData struct {
+	SqlId                int32    `protobuf:"varint,1,opt,name=sqlId,proto3" json:"sqlId,omitempty"`
+	Sql                  string   `protobuf:"bytes,2,opt,name=sql,proto3" json:"sql,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PSqlMetaData) Reset()         { *m = PSqlMetaData{} }
+func (m *PSqlMetaData) String() string { return proto.CompactTextString(m) }
+func (*PSqlMetaData) ProtoMessage()    {}
+func (*PSqlMetaData) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{6}
+}
+
+func (m *PSqlMetaData) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PSqlMetaData.Unmarshal(m, b)
+}
+func (m *PSqlMetaData) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PSqlMetaData.Marshal(b, m, deterministic)
+}
+func (m *PSqlMetaData) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PSqlMetaData.Merge(m, src)
+}
+func (m *PSqlMetaData) XXX_Size() int {
+	return xxx_messageInfo_PSqlMetaData.Size(m)
+}
+func (m *PSqlMetaData) XXX_DiscardUnknown() {
+	xxx_messageInfo_PSqlMetaData.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PSqlMetaData proto.InternalMessageInfo
+
+func (m *PSqlMetaData) GetSqlId() int32 {
+	if m != nil {
+		return m.SqlId
+	}
+	return 0
+}
+
+func (m *PSqlMetaData) GetSql() string {
+	if m != nil {
+		return m.Sql
+	}
+	return ""
+}
+
+type PStringMetaData struct {
+	StringId             int32    `protobuf:"varint,1,opt,name=stringId,proto3" json:"stringId,omitempty"`
+	StringValue          string   `protobuf:"bytes,2,opt,name=stringValue,proto3" json:"stringValue,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PStringMetaData) Reset()         { *m = PStringMetaData{} }
+func (m *PStringMetaData) String() string { return proto.CompactTextString(m) }
+func (*PStringMetaData) ProtoMessage()    {}
+func (*PStringMetaData) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{7}
+}
+
+func (m *PStringMetaData) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PStringMetaData.Unmarshal(m, b)
+}
+func (m *PStringMetaData) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PStringMetaData.Marshal(b, m, deterministic)
+}
+func (m *PStringMetaData) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PStringMetaData.Merge(m, src)
+}
+func (m *PStringMetaData) XXX_Size() int {
+	return xxx_messageInfo_PStringMetaData.Size(m)
+}
+func (m *PStringMetaData) XXX_DiscardUnknown() {
+	xxx_messageInfo_PStringMetaData.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PStringMetaData proto.InternalMessageInfo
+
+func (m *PStringMetaData) GetStringId() int32 {
+	if m != nil {
+		return m.StringId
+	}
+	return 0
+}
+
+func (m *PStringMetaData) GetStringValue() string {
+	if m != nil {
+		return m.StringValue
+	}
+	return ""
+}
+
+type PTransactionId struct {
+	AgentId              string   `protobuf:"bytes,1,opt,name=agentId,proto3" json:"agentId,omitempty"`
+	AgentStartTime       int64    `protobuf:"varint,2,opt,name=agentStartTime,proto3" json:"agentStartTime,omitempty"`
+	Sequence             int64    `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PTransactionId) Reset()         { *m = PTransactionId{} }
+func (m *PTransactionId) String() string { return proto.CompactTextString(m) }
+func (*PTransactionId) ProtoMessage()    {}
+func (*PTransactionId) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{8}
+}
+
+func (m *PTransactionId) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PTransactionId.Unmarshal(m, b)
+}
+func (m *PTransactionId) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PTransactionId.Marshal(b, m, deterministic)
+}
+func (m *PTransactionId) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PTransactionId.Merge(m, src)
+}
+func (m *PTransactionId) XXX_Size() int {
+	return xxx_messageInfo_PTransactionId.Size(m)
+}
+func (m *PTransactionId) XXX_DiscardUnknown() {
+	xxx_messageInfo_PTransactionId.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PTransactionId proto.InternalMessageInfo
+
+func (m *PTransactionId) GetAgentId() string {
+	if m != nil {
+		return m.AgentId
+	}
+	return ""
+}
+
+func (m *PTransactionId) GetAgentStartTime() int64 {
+	if m != nil {
+		return m.AgentStartTime
+	}
+	return 0
+}
+
+func (m *PTransactionId) GetSequence() int64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+type PSpanMessage struct {
+	// Types that are valid to be assigned to Field:
+	//	*PSpanMessage_Span
+	//	*PSpanMessage_SpanChunk
+	Field                isPSpanMessage_Field `protobuf_oneof:"field"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *PSpanMessage) Reset()         { *m = PSpanMessage{} }
+func (m *PSpanMessage) String() string { return proto.CompactTextString(m) }
+func (*PSpanMessage) ProtoMessage()    {}
+func (*PSpanMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{9}
+}
+
+func (m *PSpanMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PSpanMessage.Unmarshal(m, b)
+}
+func (m *PSpanMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PSpanMessage.Marshal(b, m, deterministic)
+}
+func (m *PSpanMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PSpanMessage.Merge(m, src)
+}
+func (m *PSpanMessage) XXX_Size() int {
+	return xxx_messageInfo_PSpanMessage.Size(m)
+}
+func (m *PSpanMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_PSpanMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PSpanMessage proto.InternalMessageInfo
+
+type isPSpanMessage_Field interface {
+	isPSpanMessage_Field()
+}
+
+type PSpanMessage_Span struct {
+	Span *PSpan `protobuf:"bytes,1,opt,name=span,proto3,oneof"`
+}
+
+type PSpanMessage_SpanChunk struct {
+	SpanChunk *PSpanChunk `protobuf:"bytes,2,opt,name=spanChunk,proto3,oneof"`
+}
+
+func (*PSpanMessage_Span) isPSpanMessage_Field() {}
+
+func (*PSpanMessage_SpanChunk) isPSpanMessage_Field() {}
+
+func (m *PSpanMessage) GetField() isPSpanMessage_Field {
+	if m != nil {
+		return m.Field
+	}
+	return nil
+}
+
+func (m *PSpanMessage) GetSpan() *PSpan {
+	if x, ok := m.GetField().(*PSpanMessage_Span); ok {
+		return x.Span
+	}
+	return nil
+}
+
+func (m *PSpanMessage) GetSpanChunk() *PSpanChunk {
+	if x, ok := m.GetField().(*PSpanMessage_SpanChunk); ok {
+		return x.SpanChunk
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*PSpanMessage) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*PSpanMessage_Span)(nil),
+		(*PSpanMessage_SpanChunk)(nil),
+	}
+}
+
+type PSpan struct {
+	Version                int32            `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	TransactionId          *PTransactionId  `protobuf:"bytes,2,opt,name=transactionId,proto3" json:"transactionId,omitempty"`
+	SpanId                 int64            `protobuf:"fixed64,3,opt,name=spanId,proto3" json:"spanId,omitempty"`
+	ParentSpanId           int64            `protobuf:"fixed64,4,opt,name=parentSpanId,proto3" json:"parentSpanId,omitempty"`
+	StartTime              int64            `protobuf:"varint,5,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	Elapsed                int32            `protobuf:"varint,6,opt,name=elapsed,proto3" json:"elapsed,omitempty"`
+	ApiId                  int32            `protobuf:"varint,7,opt,name=apiId,proto3" json:"apiId,omitempty"`
+	ServiceType            int32            `protobuf:"varint,8,opt,name=serviceType,proto3" json:"serviceType,omitempty"`
+	AcceptEvent            *PAcceptEvent    `protobuf:"bytes,9,opt,name=acceptEvent,proto3" json:"acceptEvent,omitempty"`
+	Annotation             []*PAnnotation   `protobuf:"bytes,10,rep,name=annotation,proto3" json:"annotation,omitempty"`
+	Flag                   int32            `protobuf:"varint,11,opt,name=flag,proto3" json:"flag,omitempty"`
+	Err                    int32            `protobuf:"zigzag32,12,opt,name=err,proto3" json:"err,omitempty"`
+	SpanEvent              []*PSpanEvent    `protobuf:"bytes,13,rep,name=spanEvent,proto3" json:"spanEvent,omitempty"`
+	ExceptionInfo          *PIntStringValue `protobuf:"bytes,14,opt,name=exceptionInfo,proto3" json:"exceptionInfo,omitempty"`
+	ApplicationServiceType int32            `protobuf:"varint,15,opt,name=applicationServiceType,proto3" json:"applicationServiceType,omitempty"`
+	LoggingTransactionInfo int32            `protobuf:"varint,16,opt,name=loggingTransactionInfo,proto3" json:"loggingTransactionInfo,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{}         `json:"-"`
+	XXX_unrecognized       []byte           `json:"-"`
+	XXX_sizecache          int32            `json:"-"`
+}
+
+func (m *PSpan) Reset()         { *m = PSpan{} }
+func (m *PSpan) String() string { return proto.CompactTextString(m) }
+func (*PSpan) ProtoMessage()    {}
+func (*PSpan) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{10}
+}
+
+func (m *PSpan) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PSpan.Unmarshal(m, b)
+}
+func (m *PSpan) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PSpan.Marshal(b, m, deterministic)
+}
+func (m *PSpan) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PSpan.Merge(m, src)
+}
+func (m *PSpan) XXX_Size() int {
+	return xxx_messageInfo_PSpan.Size(m)
+}
+func (m *PSpan) XXX_DiscardUnknown() {
+	xxx_messageInfo_PSpan.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PSpan proto.InternalMessageInfo
+
+func (m *PSpan) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *PSpan) GetTransactionId() *PTransactionId {
+	if m != nil {
+		return m.TransactionId
+	}
+	return nil
+}
+
+func (m *PSpan) GetSpanId() int64 {
+	if m != nil {
+		return m.SpanId
+	}
+	return 0
+}
+
+func (m *PSpan) GetParentSpanId() int64 {
+	if m != nil {
+		return m.ParentSpanId
+	}
+	return 0
+}
+
+func (m *PSpan) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *PSpan) GetElapsed() int32 {
+	if m != nil {
+		return m.Elapsed
+	}
+	return 0
+}
+
+func (m *PSpan) GetApiId() int32 {
+	if m != nil {
+		return m.ApiId
+	}
+	return 0
+}
+
+func (m *PSpan) GetServiceType() int32 {
+	if m != nil {
+		return m.ServiceType
+	}
+	return 0
+}
+
+func (m *PSpan) GetAcceptEvent() *PAcceptEvent {
+	if m != nil {
+		return m.AcceptEvent
+	}
+	return nil
+}
+
+func (m *PSpan) GetAnnotation() []*PAnnotation {
+	if m != nil {
+		return m.Annotation
+	}
+	return nil
+}
+
+func (m *PSpan) GetFlag() int32 {
+	if m != nil {
+		return m.Flag
+	}
+	return 0
+}
+
+func (m *PSpan) GetErr() int32 {
+	if m != nil {
+		return m.Err
+	}
+	return 0
+}
+
+func (m *PSpan) GetSpanEvent() []*PSpanEvent {
+	if m != nil {
+		return m.SpanEvent
+	}
+	return nil
+}
+
+func (m *PSpan) GetExceptionInfo() *PIntStringValue {
+	if m != nil {
+		return m.ExceptionInfo
+	}
+	return nil
+}
+
+func (m *PSpan) GetApplicationServiceType() int32 {
+	if m != nil {
+		return m.ApplicationServiceType
+	}
+	return 0
+}
+
+func (m *PSpan) GetLoggingTransactionInfo() int32 {
+	if m != nil {
+		return m.LoggingTransactionInfo
+	}
+	return 0
+}
+
+type PAcceptEvent struct {
+	Rpc                  string       `protobuf:"bytes,1,opt,name=rpc,proto3" json:"rpc,omitempty"`
+	EndPoint             string       `protobuf:"bytes,2,opt,name=endPoint,proto3" json:"endPoint,omitempty"`
+	RemoteAddr           string       `protobuf:"bytes,3,opt,name=remoteAddr,proto3" json:"remoteAddr,omitempty"`
+	ParentInfo           *PParentInfo `protobuf:"bytes,4,opt,name=parentInfo,proto3" json:"parentInfo,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *PAcceptEvent) Reset()         { *m = PAcceptEvent{} }
+func (m *PAcceptEvent) String() string { return proto.CompactTextString(m) }
+func (*PAcceptEvent) ProtoMessage()    {}
+func (*PAcceptEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{11}
+}
+
+func (m *PAcceptEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PAcceptEvent.Unmarshal(m, b)
+}
+func (m *PAcceptEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PAcceptEvent.Marshal(b, m, deterministic)
+}
+func (m *PAcceptEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PAcceptEvent.Merge(m, src)
+}
+func (m *PAcceptEvent) XXX_Size() int {
+	return xxx_messageInfo_PAcceptEvent.Size(m)
+}
+func (m *PAcceptEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_PAcceptEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PAcceptEvent proto.InternalMessageInfo
+
+func (m *PAcceptEvent) GetRpc() string {
+	if m != nil {
+		return m.Rpc
+	}
+	return ""
+}
+
+func (m *PAcceptEvent) GetEndPoint() string {
+	if m != nil {
+		return m.EndPoint
+	}
+	return ""
+}
+
+func (m *PAcceptEvent) GetRemoteAddr() string {
+	if m != nil {
+		return m.RemoteAddr
+	}
+	return ""
+}
+
+func (m *PAcceptEvent) GetParentInfo() *PParentInfo {
+	if m != nil {
+		return m.ParentInfo
+	}
+	return nil
+}
+
+type PParentInfo struct {
+	ParentApplicationName string   `protobuf:"bytes,1,opt,name=parentApplicationName,proto3" json:"parentApplicationName,omitempty"`
+	ParentApplicationType int32    `protobuf:"varint,2,opt,name=parentApplicationType,proto3" json:"parentApplicationType,omitempty"`
+	AcceptorHost          string   `protobuf:"bytes,3,opt,name=acceptorHost,proto3" json:"acceptorHost,omitempty"`
+	XXX_NoUnkeyedLiteral  struct{} `json:"-"`
+	XXX_unrecognized      []byte   `json:"-"`
+	XXX_sizecache         int32    `json:"-"`
+}
+
+func (m *PParentInfo) Reset()         { *m = PParentInfo{} }
+func (m *PParentInfo) String() string { return proto.CompactTextString(m) }
+func (*PParentInfo) ProtoMessage()    {}
+func (*PParentInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{12}
+}
+
+func (m *PParentInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PParentInfo.Unmarshal(m, b)
+}
+func (m *PParentInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PParentInfo.Marshal(b, m, deterministic)
+}
+func (m *PParentInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PParentInfo.Merge(m, src)
+}
+func (m *PParentInfo) XXX_Size() int {
+	return xxx_messageInfo_PParentInfo.Size(m)
+}
+func (m *PParentInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_PParentInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PParentInfo proto.InternalMessageInfo
+
+func (m *PParentInfo) GetParentApplicationName() string {
+	if m != nil {
+		return m.ParentApplicationName
+	}
+	return ""
+}
+
+func (m *PParentInfo) GetParentApplicationType() int32 {
+	if m != nil {
+		return m.ParentApplicationType
+	}
+	return 0
+}
+
+func (m *PParentInfo) GetAcceptorHost() string {
+	if m != nil {
+		return m.AcceptorHost
+	}
+	return ""
+}
+
+type PSpanChunk struct {
+	Version                int32           `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	TransactionId          *PTransactionId `protobuf:"bytes,2,opt,name=transactionId,proto3" json:"transactionId,omitempty"`
+	SpanId                 int64           `protobuf:"fixed64,3,opt,name=spanId,proto3" json:"spanId,omitempty"`
+	EndPoint               string          `protobuf:"bytes,4,opt,name=endPoint,proto3" json:"endPoint,omitempty"`
+	SpanEvent              []*PSpanEvent   `protobuf:"bytes,5,rep,name=spanEvent,proto3" json:"spanEvent,omitempty"`
+	ApplicationServiceType int32           `protobuf:"varint,6,opt,name=applicationServiceType,proto3" json:"applicationServiceType,omitempty"`
+	KeyTime                int64           `protobuf:"varint,7,opt,name=keyTime,proto3" json:"keyTime,omitempty"`
+	LocalAsyncId           *PLocalAsyncId  `protobuf:"bytes,8,opt,name=localAsyncId,proto3" json:"localAsyncId,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{}        `json:"-"`
+	XXX_unrecognized       []byte          `json:"-"`
+	XXX_sizecache          int32           `json:"-"`
+}
+
+func (m *PSpanChunk) Reset()         { *m = PSpanChunk{} }
+func (m *PSpanChunk) String() string { return proto.CompactTextString(m) }
+func (*PSpanChunk) ProtoMessage()    {}
+func (*PSpanChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{13}
+}
+
+func (m *PSpanChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PSpanChunk.Unmarshal(m, b)
+}
+func (m *PSpanChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PSpanChunk.Marshal(b, m, deterministic)
+}
+func (m *PSpanChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PSpanChunk.Merge(m, src)
+}
+func (m *PSpanChunk) XXX_Size() int {
+	return xxx_messageInfo_PSpanChunk.Size(m)
+}
+func (m *PSpanChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_PSpanChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PSpanChunk proto.InternalMessageInfo
+
+func (m *PSpanChunk) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *PSpanChunk) GetTransactionId() *PTransactionId {
+	if m != nil {
+		return m.TransactionId
+	}
+	return nil
+}
+
+func (m *PSpanChunk) GetSpanId() int64 {
+	if m != nil {
+		return m.SpanId
+	}
+	return 0
+}
+
+func (m *PSpanChunk) GetEndPoint() string {
+	if m != nil {
+		return m.EndPoint
+	}
+	return ""
+}
+
+func (m *PSpanChunk) GetSpanEvent() []*PSpanEvent {
+	if m != nil {
+		return m.SpanEvent
+	}
+	return nil
+}
+
+func (m *PSpanChunk) GetApplicationServiceType() int32 {
+	if m != nil {
+		return m.ApplicationServiceType
+	}
+	return 0
+}
+
+func (m *PSpanChunk) GetKeyTime() int64 {
+	if m != nil {
+		return m.KeyTime
+	}
+	return 0
+}
+
+func (m *PSpanChunk) GetLocalAsyncId() *PLocalAsyncId {
+	if m != nil {
+		return m.LocalAsyncId
+	}
+	return nil
+}
+
+type PLocalAsyncId struct {
+	AsyncId              int32    `protobuf:"varint,1,opt,name=asyncId,proto3" json:"asyncId,omitempty"`
+	Sequence             int32    `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PLocalAsyncId) Reset()         { *m = PLocalAsyncId{} }
+func (m *PLocalAsyncId) String() string { return proto.CompactTextString(m) }
+func (*PLocalAsyncId) ProtoMessage()    {}
+func (*PLocalAsyncId) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{14}
+}
+
+func (m *PLocalAsyncId) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PLocalAsyncId.Unmarshal(m, b)
+}
+func (m *PLocalAsyncId) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PLocalAsyncId.Marshal(b, m, deterministic)
+}
+func (m *PLocalAsyncId) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PLocalAsyncId.Merge(m, src)
+}
+func (m *PLocalAsyncId) XXX_Size() int {
+	return xxx_messageInfo_PLocalAsyncId.Size(m)
+}
+func (m *PLocalAsyncId) XXX_DiscardUnknown() {
+	xxx_messageInfo_PLocalAsyncId.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PLocalAsyncId proto.InternalMessageInfo
+
+func (m *PLocalAsyncId) GetAsyncId() int32 {
+	if m != nil {
+		return m.AsyncId
+	}
+	return 0
+}
+
+func (m *PLocalAsyncId) GetSequence() int32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+type PSpanEvent struct {
+	Sequence             int32            `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Depth                int32            `protobuf:"varint,2,opt,name=depth,proto3" json:"depth,omitempty"`
+	StartElapsed         int32            `protobuf:"varint,3,opt,name=startElapsed,proto3" json:"startElapsed,omitempty"`
+	EndElapsed           int32            `protobuf:"varint,4,opt,name=endElapsed,proto3" json:"endElapsed,omitempty"`
+	ServiceType          int32            `protobuf:"varint,5,opt,name=serviceType,proto3" json:"serviceType,omitempty"`
+	Annotation           []*PAnnotation   `protobuf:"bytes,6,rep,name=annotation,proto3" json:"annotation,omitempty"`
+	ApiId                int32            `protobuf:"varint,10,opt,name=apiId,proto3" json:"apiId,omitempty"`
+	ExceptionInfo        *PIntStringValue `protobuf:"bytes,11,opt,name=exceptionInfo,proto3" json:"exceptionInfo,omitempty"`
+	NextEvent            *PNextEvent      `protobuf:"bytes,12,opt,name=nextEvent,proto3" json:"nextEvent,omitempty"`
+	AsyncEvent           int32            `protobuf:"varint,13,opt,name=asyncEvent,proto3" json:"asyncEvent,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PSpanEvent) Reset()         { *m = PSpanEvent{} }
+func (m *PSpanEvent) String() string { return proto.CompactTextString(m) }
+func (*PSpanEvent) ProtoMessage()    {}
+func (*PSpanEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{15}
+}
+
+func (m *PSpanEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PSpanEvent.Unmarshal(m, b)
+}
+func (m *PSpanEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PSpanEvent.Marshal(b, m, deterministic)
+}
+func (m *PSpanEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PSpanEvent.Merge(m, src)
+}
+func (m *PSpanEvent) XXX_Size() int {
+	return xxx_messageInfo_PSpanEvent.Size(m)
+}
+func (m *PSpanEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_PSpanEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PSpanEvent proto.InternalMessageInfo
+
+func (m *PSpanEvent) GetSequence() int32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *PSpanEvent) GetDepth() int32 {
+	if m != nil {
+		return m.Depth
+	}
+	return 0
+}
+
+func (m *PSpanEvent) GetStartElapsed() int32 {
+	if m != nil {
+		return m.StartElapsed
+	}
+	return 0
+}
+
+func (m *PSpanEvent) GetEndElapsed() int32 {
+	if m != nil {
+		return m.EndElapsed
+	}
+	return 0
+}
+
+func (m *PSpanEvent) GetServiceType() int32 {
+	if m != nil {
+		return m.ServiceType
+	}
+	return 0
+}
+
+func (m *PSpanEvent) GetAnnotation() []*PAnnotation {
+	if m != nil {
+		return m.Annotation
+	}
+	return nil
+}
+
+func (m *PSpanEvent) GetApiId() int32 {
+	if m != nil {
+		return m.ApiId
+	}
+	return 0
+}
+
+func (m *PSpanEvent) GetExceptionInfo() *PIntStringValue {
+	if m != nil {
+		return m.ExceptionInfo
+	}
+	return nil
+}
+
+func (m *PSpanEvent) GetNextEvent() *PNextEvent {
+	if m != nil {
+		return m.NextEvent
+	}
+	return nil
+}
+
+func (m *PSpanEvent) GetAsyncEvent() int32 {
+	if m != nil {
+		return m.AsyncEvent
+	}
+	return 0
+}
+
+type PNextEvent struct {
+	// Types that are valid to be assigned to Field:
+	//	*PNextEvent_MessageEvent
+	Field                isPNextEvent_Field `protobuf_oneof:"field"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *PNextEvent) Reset()         { *m = PNextEvent{} }
+func (m *PNextEvent) String() string { return proto.CompactTextString(m) }
+func (*PNextEvent) ProtoMessage()    {}
+func (*PNextEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{16}
+}
+
+func (m *PNextEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PNextEvent.Unmarshal(m, b)
+}
+func (m *PNextEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PNextEvent.Marshal(b, m, deterministic)
+}
+func (m *PNextEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PNextEvent.Merge(m, src)
+}
+func (m *PNextEvent) XXX_Size() int {
+	return xxx_messageInfo_PNextEvent.Size(m)
+}
+func (m *PNextEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_PNextEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PNextEvent proto.InternalMessageInfo
+
+type isPNextEvent_Field interface {
+	isPNextEvent_Field()
+}
+
+type PNextEvent_MessageEvent struct {
+	MessageEvent *PMessageEvent `protobuf:"bytes,1,opt,name=messageEvent,proto3,oneof"`
+}
+
+func (*PNextEvent_MessageEvent) isPNextEvent_Field() {}
+
+func (m *PNextEvent) GetField() isPNextEvent_Field {
+	if m != nil {
+		return m.Field
+	}
+	return nil
+}
+
+func (m *PNextEvent) GetMessageEvent() *PMessageEvent {
+	if x, ok := m.GetField().(*PNextEvent_MessageEvent); ok {
+		return x.MessageEvent
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*PNextEvent) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*PNextEvent_MessageEvent)(nil),
+	}
+}
+
+type PMessageEvent struct {
+	NextSpanId           int64    `protobuf:"fixed64,1,opt,name=nextSpanId,proto3" json:"nextSpanId,omitempty"`
+	EndPoint             string   `protobuf:"bytes,2,opt,name=endPoint,proto3" json:"endPoint,omitempty"`
+	DestinationId        string   `protobuf:"bytes,3,opt,name=destinationId,proto3" json:"destinationId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PMessageEvent) Reset()         { *m = PMessageEvent{} }
+func (m *PMessageEvent) String() string { return proto.CompactTextString(m) }
+func (*PMessageEvent) ProtoMessage()    {}
+func (*PMessageEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{17}
+}
+
+func (m *PMessageEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PMessageEvent.Unmarshal(m, b)
+}
+func (m *PMessageEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PMessageEvent.Marshal(b, m, deterministic)
+}
+func (m *PMessageEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PMessageEvent.Merge(m, src)
+}
+func (m *PMessageEvent) XXX_Size() int {
+	return xxx_messageInfo_PMessageEvent.Size(m)
+}
+func (m *PMessageEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_PMessageEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PMessageEvent proto.InternalMessageInfo
+
+func (m *PMessageEvent) GetNextSpanId() int64 {
+	if m != nil {
+		return m.NextSpanId
+	}
+	return 0
+}
+
+func (m *PMessageEvent) GetEndPoint() string {
+	if m != nil {
+		return m.EndPoint
+	}
+	return ""
+}
+
+func (m *PMessageEvent) GetDestinationId() string {
+	if m != nil {
+		return m.DestinationId
+	}
+	return ""
+}
+
+type PAnnotation struct {
+	Key                  int32             `protobuf:"varint,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                *PAnnotationValue `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *PAnnotation) Reset()         { *m = PAnnotation{} }
+func (m *PAnnotation) String() string { return proto.CompactTextString(m) }
+func (*PAnnotation) ProtoMessage()    {}
+func (*PAnnotation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{18}
+}
+
+func (m *PAnnotation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PAnnotation.Unmarshal(m, b)
+}
+func (m *PAnnotation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PAnnotation.Marshal(b, m, deterministic)
+}
+func (m *PAnnotation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PAnnotation.Merge(m, src)
+}
+func (m *PAnnotation) XXX_Size() int {
+	return xxx_messageInfo_PAnnotation.Size(m)
+}
+func (m *PAnnotation) XXX_DiscardUnknown() {
+	xxx_messageInfo_PAnnotation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PAnnotation proto.InternalMessageInfo
+
+func (m *PAnnotation) GetKey() int32 {
+	if m != nil {
+		return m.Key
+	}
+	return 0
+}
+
+func (m *PAnnotation) GetValue() *PAnnotationValue {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+type PAnnotationValue struct {
+	// Types that are valid to be assigned to Field:
+	//	*PAnnotationValue_StringValue
+	//	*PAnnotationValue_BoolValue
+	//	*PAnnotationValue_IntValue
+	//	*PAnnotationValue_LongValue
+	//	*PAnnotationValue_ShortValue
+	//	*PAnnotationValue_DoubleValue
+	//	*PAnnotationValue_BinaryValue
+	//	*PAnnotationValue_ByteValue
+	//	*PAnnotationValue_IntStringValue
+	//	*PAnnotationValue_StringStringValue
+	//	*PAnnotationValue_IntStringStringValue
+	Field                isPAnnotationValue_Field `protobuf_oneof:"field"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *PAnnotationValue) Reset()         { *m = PAnnotationValue{} }
+func (m *PAnnotationValue) String() string { return proto.CompactTextString(m) }
+func (*PAnnotationValue) ProtoMessage()    {}
+func (*PAnnotationValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{19}
+}
+
+func (m *PAnnotationValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PAnnotationValue.Unmarshal(m, b)
+}
+func (m *PAnnotationValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PAnnotationValue.Marshal(b, m, deterministic)
+}
+func (m *PAnnotationValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PAnnotationValue.Merge(m, src)
+}
+func (m *PAnnotationValue) XXX_Size() int {
+	return xxx_messageInfo_PAnnotationValue.Size(m)
+}
+func (m *PAnnotationValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_PAnnotationValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PAnnotationValue proto.InternalMessageInfo
+
+type isPAnnotationValue_Field interface {
+	isPAnnotationValue_Field()
+}
+
+type PAnnotationValue_StringValue struct {
+	StringValue string `protobuf:"bytes,1,opt,name=stringValue,proto3,oneof"`
+}
+
+type PAnnotationValue_BoolValue struct {
+	BoolValue bool `protobuf:"varint,2,opt,name=boolValue,proto3,oneof"`
+}
+
+type PAnnotationValue_IntValue struct {
+	IntValue int32 `protobuf:"varint,3,opt,name=intValue,proto3,oneof"`
+}
+
+type PAnnotationValue_LongValue struct {
+	LongValue int64 `protobuf:"varint,4,opt,name=longValue,proto3,oneof"`
+}
+
+type PAnnotationValue_ShortValue struct {
+	ShortValue int32 `protobuf:"zigzag32,5,opt,name=shortValue,proto3,oneof"`
+}
+
+type PAnnotationValue_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,6,opt,name=doubleValue,proto3,oneof"`
+}
+
+type PAnnotationValue_BinaryValue struct {
+	BinaryValue []byte `protobuf:"bytes,7,opt,name=binaryValue,proto3,oneof"`
+}
+
+type PAnnotationValue_ByteValue struct {
+	ByteValue int32 `protobuf:"zigzag32,8,opt,name=byteValue,proto3,oneof"`
+}
+
+type PAnnotationValue_IntStringValue struct {
+	IntStringValue *PIntStringValue `protobuf:"bytes,9,opt,name=intStringValue,proto3,oneof"`
+}
+
+type PAnnotationValue_StringStringValue struct {
+	StringStringValue *PStringStringValue `protobuf:"bytes,10,opt,name=stringStringValue,proto3,oneof"`
+}
+
+type PAnnotationValue_IntStringStringValue struct {
+	IntStringStringValue *PIntStringStringValue `protobuf:"bytes,11,opt,name=intStringStringValue,proto3,oneof"`
+}
+
+func (*PAnnotationValue_StringValue) isPAnnotationValue_Field() {}
+
+func (*PAnnotationValue_BoolValue) isPAnnotationValue_Field() {}
+
+func (*PAnnotationValue_IntValue) isPAnnotationValue_Field() {}
+
+func (*PAnnotationValue_LongValue) isPAnnotationValue_Field() {}
+
+func (*PAnnotationValue_ShortValue) isPAnnotationValue_Field() {}
+
+func (*PAnnotationValue_DoubleValue) isPAnnotationValue_Field() {}
+
+func (*PAnnotationValue_BinaryValue) isPAnnotationValue_Field() {}
+
+func (*PAnnotationValue_ByteValue) isPAnnotationValue_Field() {}
+
+func (*PAnnotationValue_IntStringValue) isPAnnotationValue_Field() {}
+
+func (*PAnnotationValue_StringStringValue) isPAnnotationValue_Field() {}
+
+func (*PAnnotationValue_IntStringStringValue) isPAnnotationValue_Field() {}
+
+func (m *PAnnotationValue) GetField() isPAnnotationValue_Field {
+	if m != nil {
+		return m.Field
+	}
+	return nil
+}
+
+func (m *PAnnotationValue) GetStringValue() string {
+	if x, ok := m.GetField().(*PAnnotationValue_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (m *PAnnotationValue) GetBoolValue() bool {
+	if x, ok := m.GetField().(*PAnnotationValue_BoolValue); ok {
+		return x.BoolValue
+	}
+	return false
+}
+
+func (m *PAnnotationValue) GetIntValue() int32 {
+	if x, ok := m.GetField().(*PAnnotationValue_IntValue); ok {
+		return x.IntValue
+	}
+	return 0
+}
+
+func (m *PAnnotationValue) GetLongValue() int64 {
+	if x, ok := m.GetField().(*PAnnotationValue_LongValue); ok {
+		return x.LongValue
+	}
+	return 0
+}
+
+func (m *PAnnotationValue) GetShortValue() int32 {
+	if x, ok := m.GetField().(*PAnnotationValue_ShortValue); ok {
+		return x.ShortValue
+	}
+	return 0
+}
+
+func (m *PAnnotationValue) GetDoubleValue() float64 {
+	if x, ok := m.GetField().(*PAnnotationValue_DoubleValue); ok {
+		return x.DoubleValue
+	}
+	return 0
+}
+
+func (m *PAnnotationValue) GetBinaryValue() []byte {
+	if x, ok := m.GetField().(*PAnnotationValue_BinaryValue); ok {
+		return x.BinaryValue
+	}
+	return nil
+}
+
+func (m *PAnnotationValue) GetByteValue() int32 {
+	if x, ok := m.GetField().(*PAnnotationValue_ByteValue); ok {
+		return x.ByteValue
+	}
+	return 0
+}
+
+func (m *PAnnotationValue) GetIntStringValue() *PIntStringValue {
+	if x, ok := m.GetField().(*PAnnotationValue_IntStringValue); ok {
+		return x.IntStringValue
+	}
+	return nil
+}
+
+func (m *PAnnotationValue) GetStringStringValue() *PStringStringValue {
+	if x, ok := m.GetField().(*PAnnotationValue_StringStringValue); ok {
+		return x.StringStringValue
+	}
+	return nil
+}
+
+func (m *PAnnotationValue) GetIntStringStringValue() *PIntStringStringValue {
+	if x, ok := m.GetField().(*PAnnotationValue_IntStringStringValue); ok {
+		return x.IntStringStringValue
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*PAnnotationValue) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*PAnnotationValue_StringValue)(nil),
+		(*PAnnotationValue_BoolValue)(nil),
+		(*PAnnotationValue_IntValue)(nil),
+		(*PAnnotationValue_LongValue)(nil),
+		(*PAnnotationValue_ShortValue)(nil),
+		(*PAnnotationValue_DoubleValue)(nil),
+		(*PAnnotationValue_BinaryValue)(nil),
+		(*PAnnotationValue_ByteValue)(nil),
+		(*PAnnotationValue_IntStringValue)(nil),
+		(*PAnnotationValue_StringStringValue)(nil),
+		(*PAnnotationValue_IntStringStringValue)(nil),
+	}
+}
+
+type PIntStringValue struct {
+	IntValue             int32                 `protobuf:"varint,1,opt,name=intValue,proto3" json:"intValue,omitempty"`
+	StringValue          *wrappers.StringValue `protobuf:"bytes,2,opt,name=stringValue,proto3" json:"stringValue,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *PIntStringValue) Reset()         { *m = PIntStringValue{} }
+func (m *PIntStringValue) String() string { return proto.CompactTextString(m) }
+func (*PIntStringValue) ProtoMessage()    {}
+func (*PIntStringValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{20}
+}
+
+func (m *PIntStringValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PIntStringValue.Unmarshal(m, b)
+}
+func (m *PIntStringValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PIntStringValue.Marshal(b, m, deterministic)
+}
+func (m *PIntStringValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PIntStringValue.Merge(m, src)
+}
+func (m *PIntStringValue) XXX_Size() int {
+	return xxx_messageInfo_PIntStringValue.Size(m)
+}
+func (m *PIntStringValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_PIntStringValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PIntStringValue proto.InternalMessageInfo
+
+func (m *PIntStringValue) GetIntValue() int32 {
+	if m != nil {
+		return m.IntValue
+	}
+	return 0
+}
+
+func (m *PIntStringValue) GetStringValue() *wrappers.StringValue {
+	if m != nil {
+		return m.StringValue
+	}
+	return nil
+}
+
+type PStringStringValue struct {
+	StringValue1         *wrappers.StringValue `protobuf:"bytes,1,opt,name=stringValue1,proto3" json:"stringValue1,omitempty"`
+	StringValue2         *wrappers.StringValue `protobuf:"bytes,2,opt,name=stringValue2,proto3" json:"stringValue2,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *PStringStringValue) Reset()         { *m = PStringStringValue{} }
+func (m *PStringStringValue) String() string { return proto.CompactTextString(m) }
+func (*PStringStringValue) ProtoMessage()    {}
+func (*PStringStringValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{21}
+}
+
+func (m *PStringStringValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PStringStringValue.Unmarshal(m, b)
+}
+func (m *PStringStringValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PStringStringValue.Marshal(b, m, deterministic)
+}
+func (m *PStringStringValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PStringStringValue.Merge(m, src)
+}
+func (m *PStringStringValue) XXX_Size() int {
+	return xxx_messageInfo_PStringStringValue.Size(m)
+}
+func (m *PStringStringValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_PStringStringValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PStringStringValue proto.InternalMessageInfo
+
+func (m *PStringStringValue) GetStringValue1() *wrappers.StringValue {
+	if m != nil {
+		return m.StringValue1
+	}
+	return nil
+}
+
+func (m *PStringStringValue) GetStringValue2() *wrappers.StringValue {
+	if m != nil {
+		return m.StringValue2
+	}
+	return nil
+}
+
+type PIntStringStringValue struct {
+	IntValue             int32                 `protobuf:"varint,1,opt,name=intValue,proto3" json:"intValue,omitempty"`
+	StringValue1         *wrappers.StringValue `protobuf:"bytes,2,opt,name=stringValue1,proto3" json:"stringValue1,omitempty"`
+	StringValue2         *wrappers.StringValue `protobuf:"bytes,3,opt,name=stringValue2,proto3" json:"stringValue2,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *PIntStringStringValue) Reset()         { *m = PIntStringStringValue{} }
+func (m *PIntStringStringValue) String() string { return proto.CompactTextString(m) }
+func (*PIntStringStringValue) ProtoMessage()    {}
+func (*PIntStringStringValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{22}
+}
+
+func (m *PIntStringStringValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PIntStringStringValue.Unmarshal(m, b)
+}
+func (m *PIntStringStringValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PIntStringStringValue.Marshal(b, m, deterministic)
+}
+func (m *PIntStringStringValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PIntStringStringValue.Merge(m, src)
+}
+func (m *PIntStringStringValue) XXX_Size() int {
+	return xxx_messageInfo_PIntStringStringValue.Size(m)
+}
+func (m *PIntStringStringValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_PIntStringStringValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PIntStringStringValue proto.InternalMessageInfo
+
+func (m *PIntStringStringValue) GetIntValue() int32 {
+	if m != nil {
+		return m.IntValue
+	}
+	return 0
+}
+
+func (m *PIntStringStringValue) GetStringValue1() *wrappers.StringValue {
+	if m != nil {
+		return m.StringValue1
+	}
+	return nil
+}
+
+func (m *PIntStringStringValue) GetStringValue2() *wrappers.StringValue {
+	if m != nil {
+		return m.StringValue2
+	}
+	return nil
+}
+
+type PStatMessage struct {
+	// Types that are valid to be assigned to Field:
+	//	*PStatMessage_AgentStat
+	//	*PStatMessage_AgentStatBatch
+	Field                isPStatMessage_Field `protobuf_oneof:"field"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *PStatMessage) Reset()         { *m = PStatMessage{} }
+func (m *PStatMessage) String() string { return proto.CompactTextString(m) }
+func (*PStatMessage) ProtoMessage()    {}
+func (*PStatMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{23}
+}
+
+func (m *PStatMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PStatMessage.Unmarshal(m, b)
+}
+func (m *PStatMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PStatMessage.Marshal(b, m, deterministic)
+}
+func (m *PStatMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PStatMessage.Merge(m, src)
+}
+func (m *PStatMessage) XXX_Size() int {
+	return xxx_messageInfo_PStatMessage.Size(m)
+}
+func (m *PStatMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_PStatMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PStatMessage proto.InternalMessageInfo
+
+type isPStatMessage_Field interface {
+	isPStatMessage_Field()
+}
+
+type PStatMessage_AgentStat struct {
+	AgentStat *PAgentStat `protobuf:"bytes,1,opt,name=agentStat,proto3,oneof"`
+}
+
+type PStatMessage_AgentStatBatch struct {
+	AgentStatBatch *PAgentStatBatch `protobuf:"bytes,2,opt,name=agentStatBatch,proto3,oneof"`
+}
+
+func (*PStatMessage_AgentStat) isPStatMessage_Field() {}
+
+func (*PStatMessage_AgentStatBatch) isPStatMessage_Field() {}
+
+func (m *PStatMessage) GetField() isPStatMessage_Field {
+	if m != nil {
+		return m.Field
+	}
+	return nil
+}
+
+func (m *PStatMessage) GetAgentStat() *PAgentStat {
+	if x, ok := m.GetField().(*PStatMessage_AgentStat); ok {
+		return x.AgentStat
+	}
+	return nil
+}
+
+func (m *PStatMessage) GetAgentStatBatch() *PAgentStatBatch {
+	if x, ok := m.GetField().(*PStatMessage_AgentStatBatch); ok {
+		return x.AgentStatBatch
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*PStatMessage) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*PStatMessage_AgentStat)(nil),
+		(*PStatMessage_AgentStatBatch)(nil),
+	}
+}
+
+type PAgentStat struct {
+	Timestamp            int64            `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	CollectInterval      int64            `protobuf:"varint,2,opt,name=collectInterval,proto3" json:"collectInterval,omitempty"`
+	Gc                   *PJvmGc          `protobuf:"bytes,3,opt,name=gc,proto3" json:"gc,omitempty"`
+	CpuLoad              *PCpuLoad        `protobuf:"bytes,4,opt,name=cpuLoad,proto3" json:"cpuLoad,omitempty"`
+	Transaction          *PTransaction    `protobuf:"bytes,5,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	ActiveTrace          *PActiveTrace    `protobuf:"bytes,6,opt,name=activeTrace,proto3" json:"activeTrace,omitempty"`
+	DataSourceList       *PDataSourceList `protobuf:"bytes,7,opt,name=dataSourceList,proto3" json:"dataSourceList,omitempty"`
+	ResponseTime         *PResponseTime   `protobuf:"bytes,8,opt,name=responseTime,proto3" json:"responseTime,omitempty"`
+	Metadata             string           `protobuf:"bytes,12,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PAgentStat) Reset()         { *m = PAgentStat{} }
+func (m *PAgentStat) String() string { return proto.CompactTextString(m) }
+func (*PAgentStat) ProtoMessage()    {}
+func (*PAgentStat) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{24}
+}
+
+func (m *PAgentStat) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PAgentStat.Unmarshal(m, b)
+}
+func (m *PAgentStat) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PAgentStat.Marshal(b, m, deterministic)
+}
+func (m *PAgentStat) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PAgentStat.Merge(m, src)
+}
+func (m *PAgentStat) XXX_Size() int {
+	return xxx_messageInfo_PAgentStat.Size(m)
+}
+func (m *PAgentStat) XXX_DiscardUnknown() {
+	xxx_messageInfo_PAgentStat.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PAgentStat proto.InternalMessageInfo
+
+func (m *PAgentStat) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *PAgentStat) GetCollectInterval() int64 {
+	if m != nil {
+		return m.CollectInterval
+	}
+	return 0
+}
+
+func (m *PAgentStat) GetGc() *PJvmGc {
+	if m != nil {
+		return m.Gc
+	}
+	return nil
+}
+
+func (m *PAgentStat) GetCpuLoad() *PCpuLoad {
+	if m != nil {
+		return m.CpuLoad
+	}
+	return nil
+}
+
+func (m *PAgentStat) GetTransaction() *PTransaction {
+	if m != nil {
+		return m.Transaction
+	}
+	return nil
+}
+
+func (m *PAgentStat) GetActiveTrace() *PActiveTrace {
+	if m != nil {
+		return m.ActiveTrace
+	}
+	return nil
+}
+
+func (m *PAgentStat) GetDataSourceList() *PDataSourceList {
+	if m != nil {
+		return m.DataSourceList
+	}
+	return nil
+}
+
+func (m *PAgentStat) GetResponseTime() *PResponseTime {
+	if m != nil {
+		return m.ResponseTime
+	}
+	return nil
+}
+
+func (m *PAgentStat) GetMetadata() string {
+	if m != nil {
+		return m.Metadata
+	}
+	return ""
+}
+
+type PAgentStatBatch struct {
+	AgentStat            []*PAgentStat `protobuf:"bytes,1,rep,name=agentStat,proto3" json:"agentStat,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *PAgentStatBatch) Reset()         { *m = PAgentStatBatch{} }
+func (m *PAgentStatBatch) String() string { return proto.CompactTextString(m) }
+func (*PAgentStatBatch) ProtoMessage()    {}
+func (*PAgentStatBatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{25}
+}
+
+func (m *PAgentStatBatch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PAgentStatBatch.Unmarshal(m, b)
+}
+func (m *PAgentStatBatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PAgentStatBatch.Marshal(b, m, deterministic)
+}
+func (m *PAgentStatBatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PAgentStatBatch.Merge(m, src)
+}
+func (m *PAgentStatBatch) XXX_Size() int {
+	return xxx_messageInfo_PAgentStatBatch.Size(m)
+}
+func (m *PAgentStatBatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_PAgentStatBatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PAgentStatBatch proto.InternalMessageInfo
+
+func (m *PAgentStatBatch) GetAgentStat() []*PAgentStat {
+	if m != nil {
+		return m.AgentStat
+	}
+	return nil
+}
+
+type PJvmGc struct {
+	Type                 PJvmGcType      `protobuf:"varint,1,opt,name=type,proto3,enum=v1.PJvmGcType" json:"type,omitempty"`
+	JvmMemoryHeapUsed    int64           `protobuf:"varint,2,opt,name=jvmMemoryHeapUsed,proto3" json:"jvmMemoryHeapUsed,omitempty"`
+	JvmMemoryHeapMax     int64           `protobuf:"varint,3,opt,name=jvmMemoryHeapMax,proto3" json:"jvmMemoryHeapMax,omitempty"`
+	JvmMemoryNonHeapUsed int64           `protobuf:"varint,4,opt,name=jvmMemoryNonHeapUsed,proto3" json:"jvmMemoryNonHeapUsed,omitempty"`
+	JvmMemoryNonHeapMax  int64           `protobuf:"varint,5,opt,name=jvmMemoryNonHeapMax,proto3" json:"jvmMemoryNonHeapMax,omitempty"`
+	JvmGcOldCount        int64           `protobuf:"varint,6,opt,name=jvmGcOldCount,proto3" json:"jvmGcOldCount,omitempty"`
+	JvmGcOldTime         int64           `protobuf:"varint,7,opt,name=jvmGcOldTime,proto3" json:"jvmGcOldTime,omitempty"`
+	JvmGcDetailed        *PJvmGcDetailed `protobuf:"bytes,8,opt,name=jvmGcDetailed,proto3" json:"jvmGcDetailed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *PJvmGc) Reset()         { *m = PJvmGc{} }
+func (m *PJvmGc) String() string { return proto.CompactTextString(m) }
+func (*PJvmGc) ProtoMessage()    {}
+func (*PJvmGc) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{26}
+}
+
+func (m *PJvmGc) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PJvmGc.Unmarshal(m, b)
+}
+func (m *PJvmGc) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PJvmGc.Marshal(b, m, deterministic)
+}
+func (m *PJvmGc) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PJvmGc.Merge(m, src)
+}
+func (m *PJvmGc) XXX_Size() int {
+	return xxx_messageInfo_PJvmGc.Size(m)
+}
+func (m *PJvmGc) XXX_DiscardUnknown() {
+	xxx_messageInfo_PJvmGc.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PJvmGc proto.InternalMessageInfo
+
+func (m *PJvmGc) GetType() PJvmGcType {
+	if m != nil {
+		return m.Type
+	}
+	return PJvmGcType_JVM_GC_TYPE_UNKNOWN
+}
+
+func (m *PJvmGc) GetJvmMemoryHeapUsed() int64 {
+	if m != nil {
+		return m.JvmMemoryHeapUsed
+	}
+	return 0
+}
+
+func (m *PJvmGc) GetJvmMemoryHeapMax() int64 {
+	if m != nil {
+		return m.JvmMemoryHeapMax
+	}
+	return 0
+}
+
+func (m *PJvmGc) GetJvmMemoryNonHeapUsed() int64 {
+	if m != nil {
+		return m.JvmMemoryNonHeapUsed
+	}
+	return 0
+}
+
+func (m *PJvmGc) GetJvmMemoryNonHeapMax() int64 {
+	if m != nil {
+		return m.JvmMemoryNonHeapMax
+	}
+	return 0
+}
+
+func (m *PJvmGc) GetJvmGcOldCount() int64 {
+	if m != nil {
+		return m.JvmGcOldCount
+	}
+	return 0
+}
+
+func (m *PJvmGc) GetJvmGcOldTime() int64 {
+	if m != nil {
+		return m.JvmGcOldTime
+	}
+	return 0
+}
+
+func (m *PJvmGc) GetJvmGcDetailed() *PJvmGcDetailed {
+	if m != nil {
+		return m.JvmGcDetailed
+	}
+	return nil
+}
+
+type PJvmGcDetailed struct {
+	JvmGcNewCount            int64    `protobuf:"varint,1,opt,name=jvmGcNewCount,proto3" json:"jvmGcNewCount,omitempty"`
+	JvmGcNewTime             int64    `protobuf:"varint,2,opt,name=jvmGcNewTime,proto3" json:"jvmGcNewTime,omitempty"`
+	JvmPoolCodeCacheUsed     float64  `protobuf:"fixed64,3,opt,name=jvmPoolCodeCacheUsed,proto3" json:"jvmPoolCodeCacheUsed,omitempty"`
+	JvmPoolNewGenUsed        float64  `protobuf:"fixed64,4,opt,name=jvmPoolNewGenUsed,proto3" json:"jvmPoolNewGenUsed,omitempty"`
+	JvmPoolOldGenUsed        float64  `protobuf:"fixed64,5,opt,name=jvmPoolOldGenUsed,proto3" json:"jvmPoolOldGenUsed,omitempty"`
+	JvmPoolSurvivorSpaceUsed float64  `protobuf:"fixed64,6,opt,name=jvmPoolSurvivorSpaceUsed,proto3" json:"jvmPoolSurvivorSpaceUsed,omitempty"`
+	JvmPoolPermGenUsed       float64  `protobuf:"fixed64,7,opt,name=jvmPoolPermGenUsed,proto3" json:"jvmPoolPermGenUsed,omitempty"`
+	JvmPoolMetaspaceUsed     float64  `protobuf:"fixed64,8,opt,name=jvmPoolMetaspaceUsed,proto3" json:"jvmPoolMetaspaceUsed,omitempty"`
+	XXX_NoUnkeyedLiteral     struct{} `json:"-"`
+	XXX_unrecognized         []byte   `json:"-"`
+	XXX_sizecache            int32    `json:"-"`
+}
+
+func (m *PJvmGcDetailed) Reset()         { *m = PJvmGcDetailed{} }
+func (m *PJvmGcDetailed) String() string { return proto.CompactTextString(m) }
+func (*PJvmGcDetailed) ProtoMessage()    {}
+func (*PJvmGcDetailed) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{27}
+}
+
+func (m *PJvmGcDetailed) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PJvmGcDetailed.Unmarshal(m, b)
+}
+func (m *PJvmGcDetailed) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PJvmGcDetailed.Marshal(b, m, deterministic)
+}
+func (m *PJvmGcDetailed) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PJvmGcDetailed.Merge(m, src)
+}
+func (m *PJvmGcDetailed) XXX_Size() int {
+	return xxx_messageInfo_PJvmGcDetailed.Size(m)
+}
+func (m *PJvmGcDetailed) XXX_DiscardUnknown() {
+	xxx_messageInfo_PJvmGcDetailed.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PJvmGcDetailed proto.InternalMessageInfo
+
+func (m *PJvmGcDetailed) GetJvmGcNewCount() int64 {
+	if m != nil {
+		return m.JvmGcNewCount
+	}
+	return 0
+}
+
+func (m *PJvmGcDetailed) GetJvmGcNewTime() int64 {
+	if m != nil {
+		return m.JvmGcNewTime
+	}
+	return 0
+}
+
+func (m *PJvmGcDetailed) GetJvmPoolCodeCacheUsed() float64 {
+	if m != nil {
+		return m.JvmPoolCodeCacheUsed
+	}
+	return 0
+}
+
+func (m *PJvmGcDetailed) GetJvmPoolNewGenUsed() float64 {
+	if m != nil {
+		return m.JvmPoolNewGenUsed
+	}
+	return 0
+}
+
+func (m *PJvmGcDetailed) GetJvmPoolOldGenUsed() float64 {
+	if m != nil {
+		return m.JvmPoolOldGenUsed
+	}
+	return 0
+}
+
+func (m *PJvmGcDetailed) GetJvmPoolSurvivorSpaceUsed() float64 {
+	if m != nil {
+		return m.JvmPoolSurvivorSpaceUsed
+	}
+	return 0
+}
+
+func (m *PJvmGcDetailed) GetJvmPoolPermGenUsed() float64 {
+	if m != nil {
+		return m.JvmPoolPermGenUsed
+	}
+	return 0
+}
+
+func (m *PJvmGcDetailed) GetJvmPoolMetaspaceUsed() float64 {
+	if m != nil {
+		return m.JvmPoolMetaspaceUsed
+	}
+	return 0
+}
+
+type PCpuLoad struct {
+	JvmCpuLoad           float64  `protobuf:"fixed64,1,opt,name=jvmCpuLoad,proto3" json:"jvmCpuLoad,omitempty"`
+	SystemCpuLoad        float64  `protobuf:"fixed64,2,opt,name=systemCpuLoad,proto3" json:"systemCpuLoad,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PCpuLoad) Reset()         { *m = PCpuLoad{} }
+func (m *PCpuLoad) String() string { return proto.CompactTextString(m) }
+func (*PCpuLoad) ProtoMessage()    {}
+func (*PCpuLoad) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{28}
+}
+
+func (m *PCpuLoad) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PCpuLoad.Unmarshal(m, b)
+}
+func (m *PCpuLoad) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PCpuLoad.Marshal(b, m, deterministic)
+}
+func (m *PCpuLoad) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PCpuLoad.Merge(m, src)
+}
+func (m *PCpuLoad) XXX_Size() int {
+	return xxx_messageInfo_PCpuLoad.Size(m)
+}
+func (m *PCpuLoad) XXX_DiscardUnknown() {
+	xxx_messageInfo_PCpuLoad.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PCpuLoad proto.InternalMessageInfo
+
+func (m *PCpuLoad) GetJvmCpuLoad() float64 {
+	if m != nil {
+		return m.JvmCpuLoad
+	}
+	return 0
+}
+
+func (m *PCpuLoad) GetSystemCpuLoad() float64 {
+	if m != nil {
+		return m.SystemCpuLoad
+	}
+	return 0
+}
+
+type PTransaction struct {
+	SampledNewCount            int64    `protobuf:"varint,2,opt,name=sampledNewCount,proto3" json:"sampledNewCount,omitempty"`
+	SampledContinuationCount   int64    `protobuf:"varint,3,opt,name=sampledContinuationCount,proto3" json:"sampledContinuationCount,omitempty"`
+	UnsampledNewCount          int64    `protobuf:"varint,4,opt,name=unsampledNewCount,proto3" json:"unsampledNewCount,omitempty"`
+	UnsampledContinuationCount int64    `protobuf:"varint,5,opt,name=unsampledContinuationCount,proto3" json:"unsampledContinuationCount,omitempty"`
+	XXX_NoUnkeyedLiteral       struct{} `json:"-"`
+	XXX_unrecognized           []byte   `json:"-"`
+	XXX_sizecache              int32    `json:"-"`
+}
+
+func (m *PTransaction) Reset()         { *m = PTransaction{} }
+func (m *PTransaction) String() string { return proto.CompactTextString(m) }
+func (*PTransaction) ProtoMessage()    {}
+func (*PTransaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{29}
+}
+
+func (m *PTransaction) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PTransaction.Unmarshal(m, b)
+}
+func (m *PTransaction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PTransaction.Marshal(b, m, deterministic)
+}
+func (m *PTransaction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PTransaction.Merge(m, src)
+}
+func (m *PTransaction) XXX_Size() int {
+	return xxx_messageInfo_PTransaction.Size(m)
+}
+func (m *PTransaction) XXX_DiscardUnknown() {
+	xxx_messageInfo_PTransaction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PTransaction proto.InternalMessageInfo
+
+func (m *PTransaction) GetSampledNewCount() int64 {
+	if m != nil {
+		return m.SampledNewCount
+	}
+	return 0
+}
+
+func (m *PTransaction) GetSampledContinuationCount() int64 {
+	if m != nil {
+		return m.SampledContinuationCount
+	}
+	return 0
+}
+
+func (m *PTransaction) GetUnsampledNewCount() int64 {
+	if m != nil {
+		return m.UnsampledNewCount
+	}
+	return 0
+}
+
+func (m *PTransaction) GetUnsampledContinuationCount() int64 {
+	if m != nil {
+		return m.UnsampledContinuationCount
+	}
+	return 0
+}
+
+type PActiveTraceHistogram struct {
+	Version              int32    `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	HistogramSchemaType  int32    `protobuf:"varint,2,opt,name=histogramSchemaType,proto3" json:"histogramSchemaType,omitempty"`
+	ActiveTraceCount     []int32  `protobuf:"varint,3,rep,packed,name=activeTraceCount,proto3" json:"activeTraceCount,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PActiveTraceHistogram) Reset()         { *m = PActiveTraceHistogram{} }
+func (m *PActiveTraceHistogram) String() string { return proto.CompactTextString(m) }
+func (*PActiveTraceHistogram) ProtoMessage()    {}
+func (*PActiveTraceHistogram) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{30}
+}
+
+func (m *PActiveTraceHistogram) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PActiveTraceHistogram.Unmarshal(m, b)
+}
+func (m *PActiveTraceHistogram) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PActiveTraceHistogram.Marshal(b, m, deterministic)
+}
+func (m *PActiveTraceHistogram) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PActiveTraceHistogram.Merge(m, src)
+}
+func (m *PActiveTraceHistogram) XXX_Size() int {
+	return xxx_messageInfo_PActiveTraceHistogram.Size(m)
+}
+func (m *PActiveTraceHistogram) XXX_DiscardUnknown() {
+	xxx_messageInfo_PActiveTraceHistogram.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PActiveTraceHistogram proto.InternalMessageInfo
+
+func (m *PActiveTraceHistogram) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *PActiveTraceHistogram) GetHistogramSchemaType() int32 {
+	if m != nil {
+		return m.HistogramSchemaType
+	}
+	return 0
+}
+
+func (m *PActiveTraceHistogram) GetActiveTraceCount() []int32 {
+	if m != nil {
+		return m.ActiveTraceCount
+	}
+	return nil
+}
+
+type PActiveTrace struct {
+	Histogram            *PActiveTraceHistogram `protobuf:"bytes,1,opt,name=histogram,proto3" json:"histogram,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *PActiveTrace) Reset()         { *m = PActiveTrace{} }
+func (m *PActiveTrace) String() string { return proto.CompactTextString(m) }
+func (*PActiveTrace) ProtoMessage()    {}
+func (*PActiveTrace) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{31}
+}
+
+func (m *PActiveTrace) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PActiveTrace.Unmarshal(m, b)
+}
+func (m *PActiveTrace) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PActiveTrace.Marshal(b, m, deterministic)
+}
+func (m *PActiveTrace) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PActiveTrace.Merge(m, src)
+}
+func (m *PActiveTrace) XXX_Size() int {
+	return xxx_messageInfo_PActiveTrace.Size(m)
+}
+func (m *PActiveTrace) XXX_DiscardUnknown() {
+	xxx_messageInfo_PActiveTrace.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PActiveTrace proto.InternalMessageInfo
+
+func (m *PActiveTrace) GetHistogram() *PActiveTraceHistogram {
+	if m != nil {
+		return m.Histogram
+	}
+	return nil
+}
+
+type PDataSource struct {
+	Id                   int32    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ServiceTypeCode      int32    `protobuf:"varint,2,opt,name=serviceTypeCode,proto3" json:"serviceTypeCode,omitempty"`
+	DatabaseName         string   `protobuf:"bytes,3,opt,name=databaseName,proto3" json:"databaseName,omitempty"`
+	Url                  string   `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	ActiveConnectionSize int32    `protobuf:"varint,5,opt,name=activeConnectionSize,proto3" json:"activeConnectionSize,omitempty"`
+	MaxConnectionSize    int32    `protobuf:"varint,6,opt,name=maxConnectionSize,proto3" json:"maxConnectionSize,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PDataSource) Reset()         { *m = PDataSource{} }
+func (m *PDataSource) String() string { return proto.CompactTextString(m) }
+func (*PDataSource) ProtoMessage()    {}
+func (*PDataSource) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{32}
+}
+
+func (m *PDataSource) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PDataSource.Unmarshal(m, b)
+}
+func (m *PDataSource) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PDataSource.Marshal(b, m, deterministic)
+}
+func (m *PDataSource) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PDataSource.Merge(m, src)
+}
+func (m *PDataSource) XXX_Size() int {
+	return xxx_messageInfo_PDataSource.Size(m)
+}
+func (m *PDataSource) XXX_DiscardUnknown() {
+	xxx_messageInfo_PDataSource.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PDataSource proto.InternalMessageInfo
+
+func (m *PDataSource) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *PDataSource) GetServiceTypeCode() int32 {
+	if m != nil {
+		return m.ServiceTypeCode
+	}
+	return 0
+}
+
+func (m *PDataSource) GetDatabaseName() string {
+	if m != nil {
+		return m.DatabaseName
+	}
+	return ""
+}
+
+func (m *PDataSource) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *PDataSource) GetActiveConnectionSize() int32 {
+	if m != nil {
+		return m.ActiveConnectionSize
+	}
+	return 0
+}
+
+func (m *PDataSource) GetMaxConnectionSize() int32 {
+	if m != nil {
+		return m.MaxConnectionSize
+	}
+	return 0
+}
+
+type PDataSourceList struct {
+	DataSource           []*PDataSource `protobuf:"bytes,1,rep,name=dataSource,proto3" json:"dataSource,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *PDataSourceList) Reset()         { *m = PDataSourceList{} }
+func (m *PDataSourceList) String() string { return proto.CompactTextString(m) }
+func (*PDataSourceList) ProtoMessage()    {}
+func (*PDataSourceList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{33}
+}
+
+func (m *PDataSourceList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PDataSourceList.Unmarshal(m, b)
+}
+func (m *PDataSourceList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PDataSourceList.Marshal(b, m, deterministic)
+}
+func (m *PDataSourceList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PDataSourceList.Merge(m, src)
+}
+func (m *PDataSourceList) XXX_Size() int {
+	return xxx_messageInfo_PDataSourceList.Size(m)
+}
+func (m *PDataSourceList) XXX_DiscardUnknown() {
+	xxx_messageInfo_PDataSourceList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PDataSourceList proto.InternalMessageInfo
+
+func (m *PDataSourceList) GetDataSource() []*PDataSource {
+	if m != nil {
+		return m.DataSource
+	}
+	return nil
+}
+
+type PResponseTime struct {
+	Avg                  int64    `protobuf:"varint,1,opt,name=avg,proto3" json:"avg,omitempty"`
+	Max                  int64    `protobuf:"varint,2,opt,name=max,proto3" json:"max,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PResponseTime) Reset()         { *m = PResponseTime{} }
+func (m *PResponseTime) String() string { return proto.CompactTextString(m) }
+func (*PResponseTime) ProtoMessage()    {}
+func (*PResponseTime) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014561b6f5c66010, []int{34}
+}
+
+func (m *PResponseTime) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PResponseTime.Unmarshal(m, b)
+}
+func (m *PResponseTime) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PResponseTime.Marshal(b, m, deterministic)
+}
+func (m *PResponseTime) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PResponseTime.Merge(m, src)
+}
+func (m *PResponseTime) XXX_Size() int {
+	return xxx_messageInfo_PResponseTime.Size(m)
+}
+func (m *PResponseTime) XXX_DiscardUnknown() {
+	xxx_messageInfo_PResponseTime.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PResponseTime proto.InternalMessageInfo
+
+func (m *PResponseTime) GetAvg() int64 {
+	if m != nil {
+		return m.Avg
+	}
+	return 0
+}
+
+func (m *PResponseTime) GetMax() int64 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("v1.PJvmGcType", PJvmGcType_name, PJvmGcType_value)
+	proto.RegisterType((*PResult)(nil), "v1.PResult")
+	proto.RegisterType((*PPing)(nil), "v1.PPing")
+	proto.RegisterType((*PAgentInfo)(nil), "v1.PAgentInfo")
+	proto.RegisterType((*PServerMetaData)(nil), "v1.PServerMetaData")
+	proto.RegisterType((*PServiceInfo)(nil), "v1.PServiceInfo")
+	proto.RegisterType((*PApiMetaData)(nil), "v1.PApiMetaData")
+	proto.RegisterType((*PSqlMetaData)(nil), "v1.PSqlMetaData")
+	proto.RegisterType((*PStringMetaData)(nil), "v1.PStringMetaData")
+	proto.RegisterType((*PTransactionId)(nil), "v1.PTransactionId")
+	proto.RegisterType((*PSpanMessage)(nil), "v1.PSpanMessage")
+	proto.RegisterType((*PSpan)(nil), "v1.PSpan")
+	proto.RegisterType((*PAcceptEvent)(nil), "v1.PAcceptEvent")
+	proto.RegisterType((*PParentInfo)(nil), "v1.PParentInfo")
+	proto.RegisterType((*PSpanChunk)(nil), "v1.PSpanChunk")
+	proto.RegisterType((*PLocalAsyncId)(nil), "v1.PLocalAsyncId")
+	proto.RegisterType((*PSpanEvent)(nil), "v1.PSpanEvent")
+	proto.RegisterType((*PNextEvent)(nil), "v1.PNextEvent")
+	proto.RegisterType((*PMessageEvent)(nil), "v1.PMessageEvent")
+	proto.RegisterType((*PAnnotation)(nil), "v1.PAnnotation")
+	proto.RegisterType((*PAnnotationValue)(nil), "v1.PAnnotationValue")
+	proto.RegisterType((*PIntStringValue)(nil), "v1.PIntStringValue")
+	proto.RegisterType((*PStringStringValue)(nil), "v1.PStringStringValue")
+	proto.RegisterType((*PIntStringStringValue)(nil), "v1.PIntStringStringValue")
+	proto.RegisterType((*PStatMessage)(nil), "v1.PStatMessage")
+	proto.RegisterType((*PAgentStat)(nil), "v1.PAgentStat")
+	proto.RegisterType((*PAgentStatBatch)(nil), "v1.PAgentStatBatch")
+	proto.RegisterType((*PJvmGc)(nil), "v1.PJvmGc")
+	proto.RegisterType((*PJvmGcDetailed)(nil), "v1.PJvmGcDetailed")
+	proto.RegisterType((*PCpuLoad)(nil), "v1.PCpuLoad")
+	proto.RegisterType((*PTransaction)(nil), "v1.PTransaction")
+	proto.RegisterType((*PActiveTraceHistogram)(nil), "v1.PActiveTraceHistogram")
+	proto.RegisterType((*PActiveTrace)(nil), "v1.PActiveTrace")
+	proto.RegisterType((*PDataSource)(nil), "v1.PDataSource")
+	proto.RegisterType((*PDataSourceList)(nil), "v1.PDataSourceList")
+	proto.RegisterType((*PResponseTime)(nil), "v1.PResponseTime")
+}
+
+func init() {
+	proto.RegisterFile("internal/pinpoint_grpc_v1/v1.proto", fileDescriptor_014561b6f5c66010)
+}
+
+var fileDescriptor_014561b6f5c66010 = []byte{
+	// 2453 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x59, 0x4f, 0x73, 0x1b, 0x49,
+	0x15, 0xf7, 0x48, 0x96, 0x2d, 0x3d, 0xf9, 0x8f, 0xd2, 0x76, 0xc2, 0x60, 0x52, 0x41, 0x35, 0x45,
+	0x2d, 0xae, 0x54, 0xd6, 0x8e, 0x15, 0xc8, 0xb2, 0x6c, 0x6d, 0x58, 0xc5, 0x31, 0x91, 0x77, 0x6d,
+	0x47, 0xd5, 0x4a, 0x42, 0xc1, 0x25, 0xd5, 0x9e, 0xe9, 0xc8, 0x93, 0x8c, 0x7a, 0x26, 0x33, 0x23,
+	0x25, 0x86, 0x0b, 0x27, 0x0e, 0x54, 0x51, 0x70, 0xe4, 0xc2, 0x67, 0xe0, 0xc8, 0x87, 0xe0, 0x43,
+	0x50, 0x70, 0xe5, 0xc2, 0x81, 0x3b, 0xd4, 0xeb, 0xee, 0x99, 0xe9, 0x19, 0xc9, 0xd9, 0x50, 0x54,
+	0xed, 0x49, 0xdd, 0xbf, 0xf7, 0xfa, 0xf5, 0xeb, 0xf7, 0xaf, 0x5f, 0x8f, 0xc0, 0xf1, 0x45, 0xca,
+	0x63, 0xc1, 0x82, 0xfd, 0xc8, 0x17, 0x51, 0xe8, 0x8b, 0xf4, 0xc5, 0x38, 0x8e, 0xdc, 0x17, 0xb3,
+	0x83, 0xfd, 0xd9, 0xc1, 0x5e, 0x14, 0x87, 0x69, 0x48, 0x6a, 0xb3, 0x83, 0x9d, 0xef, 0x8c, 0xc3,
+	0x70, 0x1c, 0xf0, 0x7d, 0x89, 0x9c, 0x4f, 0x5f, 0xee, 0xf3, 0x49, 0x94, 0x5e, 0x2a, 0x86, 0x9d,
+	0x5b, 0x55, 0xe2, 0xdb, 0x98, 0x45, 0x11, 0x8f, 0x13, 0x45, 0x77, 0x3e, 0x87, 0xd5, 0x21, 0xe5,
+	0xc9, 0x34, 0x48, 0x89, 0x0d, 0xab, 0xc9, 0xd4, 0x75, 0x79, 0x92, 0xd8, 0x56, 0xd7, 0xda, 0x6d,
+	0xd2, 0x6c, 0x8a, 0x94, 0x09, 0x4f, 0x12, 0x36, 0xe6, 0x76, 0xad, 0x6b, 0xed, 0xb6, 0x68, 0x36,
+	0x75, 0x56, 0xa1, 0x31, 0x1c, 0xfa, 0x62, 0xec, 0xfc, 0xbd, 0x06, 0x30, 0xec, 0x8f, 0xb9, 0x48,
+	0x8f, 0xc5, 0xcb, 0x90, 0xec, 0x40, 0xf3, 0x22, 0x4c, 0x52, 0xc1, 0x26, 0x5c, 0x0a, 0x6b, 0xd1,
+	0x7c, 0x4e, 0x36, 0xa0, 0xe6, 0x47, 0x5a, 0x50, 0xcd, 0x8f, 0xc8, 0x36, 0x34, 0xa2, 0x30, 0x4e,
+	0x13, 0xbb, 0x2e, 0x21, 0x35, 0x21, 0x5d, 0x68, 0x27, 0x3c, 0x9e, 0xf9, 0x2e, 0x7f, 0x7a, 0x19,
+	0x71, 0x7b, 0xb9, 0x6b, 0xed, 0x36, 0xa8, 0x09, 0x91, 0x0e, 0xd4, 0x23, 0xdf, 0xb3, 0x1b, 0x92,
+	0x82, 0x43, 0xe2, 0xc0, 0x1a, 0x43, 0x15, 0x9e, 0xf3, 0x38, 0xf1, 0x43, 0x61, 0xaf, 0x48, 0x81,
+	0x25, 0x8c, 0xdc, 0x84, 0xd6, 0x6c, 0x92, 0x31, 0xac, 0x4a, 0x86, 0x02, 0x40, 0x09, 0x5c, 0x78,
+	0x4f, 0xfd, 0x09, 0x4f, 0x52, 0x36, 0x89, 0xec, 0x66, 0xd7, 0xda, 0xad, 0xd3, 0x12, 0x86, 0x12,
+	0xb8, 0xf0, 0x46, 0x29, 0x4b, 0xa7, 0x89, 0xdd, 0x92, 0xbb, 0x17, 0x00, 0xf9, 0x0c, 0x36, 0x50,
+	0x49, 0x1e, 0x9f, 0xf2, 0x94, 0x3d, 0x62, 0x29, 0xb3, 0xa1, 0x6b, 0xed, 0xb6, 0x7b, 0x5b, 0x7b,
+	0xb3, 0x83, 0xbd, 0xe1, 0xa8, 0x44, 0xa2, 0x15, 0x56, 0x14, 0xed, 0x86, 0x22, 0x65, 0xbe, 0xe0,
+	0xb1, 0xbd, 0x26, 0x9d, 0x50, 0x00, 0xce, 0xaf, 0x60, 0xb3, 0x22, 0x80, 0xdc, 0x02, 0x50, 0x22,
+	0xd0, 0xea, 0xda, 0xd2, 0x06, 0x82, 0xb6, 0x9d, 0x4d, 0xfa, 0xf1, 0xd8, 0xae, 0x75, 0xeb, 0x68,
+	0x5b, 0x39, 0x21, 0xbd, 0xdc, 0xb6, 0x72, 0x59, 0xbd, 0x5b, 0xdf, 0x6d, 0xf7, 0x3a, 0xb9, 0x82,
+	0x1a, 0xa7, 0x26, 0x93, 0x33, 0x84, 0x35, 0x93, 0x68, 0xf8, 0xe7, 0xac, 0x70, 0xb2, 0x09, 0x65,
+	0xba, 0xf9, 0x2e, 0x3f, 0xf1, 0xcf, 0xb5, 0x02, 0x06, 0xe2, 0xbc, 0x84, 0xb5, 0x61, 0x3f, 0xf2,
+	0xf3, 0xb3, 0x6c, 0x43, 0x83, 0x45, 0xfe, 0xb1, 0x27, 0x65, 0x35, 0xa8, 0x9a, 0x60, 0xec, 0xe1,
+	0x00, 0xf5, 0xd4, 0xb1, 0xa7, 0xa7, 0x84, 0xc0, 0x72, 0xe0, 0x0b, 0x2e, 0xc3, 0xa6, 0x41, 0xe5,
+	0x18, 0xb1, 0xb4, 0x08, 0x17, 0x39, 0x76, 0xee, 0xa3, 0xe6, 0x6f, 0x02, 0x73, 0x9f, 0xe4, 0x4d,
+	0x50, 0xec, 0x23, 0x27, 0x18, 0x4d, 0xc9, 0x9b, 0x40, 0xef, 0x81, 0x43, 0xe7, 0x09, 0x9a, 0x3b,
+	0x8d, 0x7d, 0x31, 0xce, 0x97, 0xee, 0x40, 0x33, 0x91, 0x48, 0xbe, 0x3a, 0x9f, 0x4b, 0x83, 0xc8,
+	0xf1, 0x73, 0x16, 0x4c, 0xb3, 0x44, 0x31, 0x21, 0x47, 0xc0, 0xc6, 0xf0, 0x69, 0xcc, 0x44, 0xc2,
+	0xdc, 0xd4, 0x0f, 0x85, 0x3e, 0x9c, 0xcc, 0x19, 0x4f, 0x1b, 0x30, 0x9b, 0x92, 0x8f, 0x60, 0x43,
+	0x0e, 0x47, 0x29, 0x8b, 0x53, 0x8c, 0x3d, 0x29, 0xb0, 0x4e, 0x2b, 0xa8, 0xd4, 0x88, 0xbf, 0x99,
+	0x72, 0xe1, 0x2a, 0x43, 0xd4, 0x69, 0x3e, 0x77, 0x2e, 0xf0, 0xe0, 0x11, 0x13, 0xa7, 0x2a, 0x59,
+	0xc9, 0x77, 0x61, 0x39, 0x89, 0x98, 0x90, 0x5b, 0xb5, 0x7b, 0x2d, 0xe5, 0xef, 0x88, 0x89, 0xc1,
+	0x12, 0x95, 0x04, 0xb2, 0x07, 0x2d, 0xfc, 0x3d, 0xbc, 0x98, 0x8a, 0xd7, 0x72, 0xbf, 0x76, 0x6f,
+	0x23, 0xe7, 0x92, 0xe8, 0x60, 0x89, 0x16, 0x2c, 0x0f, 0x57, 0xa1, 0xf1, 0xd2, 0xe7, 0x81, 0xe7,
+	0xfc, 0x73, 0x19, 0x1a, 0x92, 0x09, 0x4f, 0x34, 0xd3, 0xc9, 0xa5, 0x0c, 0x94, 0x4d, 0xc9, 0x8f,
+	0x60, 0x3d, 0x35, 0x0f, 0xaf, 0x37, 0x20, 0x72, 0x83, 0x92, 0x59, 0x68, 0x99, 0x91, 0xdc, 0x80,
+	0x15, 0xdc, 0xf3, 0xd8, 0x93, 0x27, 0xec, 0x50, 0x3d, 0xc3, 0x64, 0x8d, 0x58, 0x8c, 0xe6, 0x50,
+	0xd4, 0x65, 0x49, 0x2d, 0x61, 0x98, 0x51, 0x49, 0x6e, 0xc2, 0x86, 0x34, 0x50, 0x01, 0xa0, 0xb6,
+	0x3c, 0x60, 0x51, 0xc2, 0x3d, 0x59, 0x2b, 0x1a, 0x34, 0x9b, 0x16, 0xc1, 0xb8, 0x6a, 0x06, 0x63,
+	0xa5, 0x28, 0x35, 0xe7, 0x8b, 0x52, 0x0f, 0xda, 0xcc, 0x75, 0x79, 0x94, 0x1e, 0xcd, 0xb8, 0x48,
+	0x65, 0x79, 0xc8, 0x52, 0xab, 0x5f, 0xe0, 0xd4, 0x64, 0x22, 0xfb, 0x00, 0x4c, 0x88, 0x30, 0x65,
+	0x78, 0x5e, 0x1b, 0x64, 0x36, 0x6e, 0xaa, 0x25, 0x39, 0x4c, 0x0d, 0x16, 0x8c, 0xf2, 0x97, 0x01,
+	0x1b, 0xdb, 0x6d, 0x15, 0xe5, 0x38, 0xc6, 0xf8, 0xe5, 0xb1, 0x2a, 0x1a, 0xd7, 0x28, 0x0e, 0xc9,
+	0x1d, 0xe5, 0x4d, 0xa5, 0xc8, 0x7a, 0xb7, 0x5e, 0xf2, 0xa6, 0x52, 0xa3, 0x60, 0x20, 0x9f, 0xc2,
+	0x3a, 0x7f, 0x87, 0x3a, 0xa1, 0xcd, 0x31, 0xdb, 0x36, 0x8c, 0xb2, 0x75, 0x8c, 0x21, 0x97, 0x07,
+	0x32, 0x2d, 0x73, 0x92, 0xfb, 0x70, 0x83, 0x45, 0x51, 0xe0, 0xbb, 0x52, 0xbb, 0x91, 0x61, 0xa0,
+	0x4d, 0xa9, 0xe0, 0x15, 0x54, 0x5c, 0x17, 0x84, 0xe3, 0xb1, 0x2f, 0xc6, 0xa6, 0xfb, 0x71, 0xef,
+	0x8e, 0x5a, 0xb7, 0x98, 0xea, 0xfc, 0xce, 0xc2, 0xca, 0x61, 0x18, 0xb0, 0x03, 0xf5, 0x38, 0x72,
+	0x75, 0x0a, 0xe1, 0x10, 0xd3, 0x82, 0x0b, 0x6f, 0x88, 0x97, 0xa6, 0xce, 0xc4, 0x7c, 0x8e, 0x75,
+	0x29, 0xe6, 0x93, 0x30, 0xe5, 0x7d, 0xcf, 0x8b, 0xf5, 0xa5, 0x63, 0x20, 0xe8, 0x0e, 0x15, 0x42,
+	0x52, 0x95, 0xe5, 0xae, 0x95, 0xbb, 0x63, 0x98, 0xc3, 0xd4, 0x60, 0x71, 0xfe, 0x64, 0x41, 0xdb,
+	0xa0, 0x91, 0x1f, 0xc0, 0x75, 0x45, 0xed, 0x17, 0xe7, 0x36, 0x8a, 0xe4, 0x62, 0xe2, 0xc2, 0x55,
+	0xd2, 0x88, 0x35, 0x69, 0x8c, 0xc5, 0x44, 0x79, 0xe5, 0x49, 0x4b, 0x84, 0xf1, 0x20, 0x4c, 0x52,
+	0x7d, 0x9c, 0x12, 0xe6, 0xfc, 0x15, 0xef, 0xe6, 0x3c, 0x85, 0xbf, 0xd1, 0x14, 0x35, 0xfd, 0xb0,
+	0x5c, 0xf1, 0x43, 0x29, 0x3e, 0x1b, 0x5f, 0x17, 0x9f, 0x57, 0x07, 0xd9, 0xca, 0x7b, 0x83, 0xcc,
+	0x86, 0xd5, 0xd7, 0xfc, 0x52, 0xa6, 0xff, 0xaa, 0x4c, 0xff, 0x6c, 0x4a, 0x7e, 0x08, 0x6b, 0x41,
+	0xe8, 0xb2, 0xa0, 0x9f, 0x5c, 0x0a, 0xf7, 0xd8, 0x93, 0xd9, 0xdc, 0xee, 0x5d, 0x93, 0x2a, 0x9c,
+	0x18, 0x04, 0x5a, 0x62, 0x73, 0x8e, 0x60, 0xbd, 0x44, 0xc6, 0x1d, 0x98, 0x16, 0xa1, 0xed, 0xa9,
+	0xa7, 0xa5, 0xe2, 0x5c, 0xd3, 0xd7, 0x45, 0x56, 0x9c, 0xff, 0x9d, 0x39, 0x45, 0x1d, 0xcf, 0x64,
+	0xb5, 0xca, 0xac, 0x58, 0x8b, 0x3c, 0x1e, 0xa5, 0x17, 0x5a, 0x86, 0x9a, 0xa0, 0xe7, 0x65, 0x21,
+	0x3b, 0xd2, 0x05, 0x4c, 0x5d, 0x83, 0x25, 0x0c, 0x43, 0x9d, 0x0b, 0x2f, 0xe3, 0x50, 0x97, 0xa2,
+	0x81, 0x54, 0xeb, 0x59, 0x63, 0xbe, 0x9e, 0x95, 0x6b, 0xd3, 0xca, 0xd7, 0xd7, 0xa6, 0xbc, 0x70,
+	0x82, 0x59, 0x38, 0xe7, 0xaa, 0x4b, 0xfb, 0x83, 0xab, 0xcb, 0x1d, 0x68, 0x09, 0xfe, 0x4e, 0xd7,
+	0xd3, 0x35, 0xe3, 0x52, 0x3a, 0xcb, 0x50, 0x5a, 0x30, 0xe0, 0x89, 0xa5, 0xf5, 0xb3, 0xaa, 0x27,
+	0x4f, 0x5c, 0x20, 0xce, 0x19, 0x40, 0xb1, 0x90, 0x7c, 0x02, 0x6b, 0xba, 0x93, 0x55, 0xfc, 0x96,
+	0x11, 0x02, 0xa7, 0x06, 0x61, 0xb0, 0x44, 0x4b, 0x8c, 0xc5, 0xcd, 0xf7, 0x06, 0xd6, 0x4b, 0x9c,
+	0xa8, 0x00, 0x6a, 0xa3, 0xaf, 0x24, 0x4b, 0x66, 0x83, 0x81, 0xbc, 0xb7, 0x32, 0x7d, 0x0f, 0xd6,
+	0x3d, 0x9e, 0xa4, 0xbe, 0x60, 0x3a, 0xff, 0x54, 0x36, 0x97, 0x41, 0xe7, 0x2b, 0x68, 0x1b, 0xc6,
+	0xc7, 0xe2, 0xf7, 0x9a, 0x5f, 0xea, 0xa0, 0xc1, 0x21, 0xb9, 0x0d, 0x8d, 0x59, 0xde, 0x83, 0xb4,
+	0x7b, 0xdb, 0x15, 0x77, 0x29, 0x2b, 0x2b, 0x16, 0xe7, 0x3f, 0x75, 0xe8, 0x54, 0x69, 0xc4, 0x29,
+	0xb7, 0x32, 0xb2, 0x6c, 0x0d, 0x96, 0x4a, 0xcd, 0x0c, 0xb9, 0x05, 0xad, 0xf3, 0x30, 0x0c, 0x8a,
+	0x66, 0xa7, 0x89, 0xbd, 0x41, 0x0e, 0x91, 0x9b, 0xd0, 0xf4, 0x45, 0xaa, 0xc8, 0x32, 0x34, 0x07,
+	0x4b, 0x34, 0x47, 0x70, 0x75, 0x10, 0x66, 0xf2, 0x31, 0x2e, 0xeb, 0xb8, 0x3a, 0x87, 0x48, 0x17,
+	0x20, 0xb9, 0x08, 0x63, 0xbd, 0x1e, 0xe3, 0xf2, 0xda, 0x60, 0x89, 0x1a, 0x18, 0xea, 0xe8, 0x85,
+	0xd3, 0xf3, 0x80, 0x2b, 0x16, 0x2c, 0x02, 0x16, 0xea, 0x68, 0x80, 0xc8, 0x73, 0xee, 0x0b, 0x16,
+	0x5f, 0x2a, 0x1e, 0xcc, 0xff, 0x35, 0xe4, 0x31, 0x40, 0x79, 0x8e, 0xcb, 0x54, 0x4b, 0x69, 0xea,
+	0x8d, 0x0a, 0x88, 0x7c, 0x0e, 0x1b, 0x7e, 0x29, 0x3e, 0xed, 0xd6, 0x95, 0xa1, 0x3b, 0x58, 0xa2,
+	0x15, 0x66, 0xf2, 0x53, 0xb8, 0xa6, 0xac, 0x66, 0x4a, 0x50, 0x2f, 0x82, 0x1b, 0xaa, 0xd8, 0x55,
+	0xa9, 0x83, 0x25, 0x3a, 0xbf, 0x84, 0x3c, 0x81, 0xed, 0x5c, 0xb2, 0x29, 0x4a, 0xe5, 0xd1, 0xb7,
+	0xcb, 0xca, 0x94, 0xa5, 0x2d, 0x5c, 0x58, 0x44, 0xf0, 0x04, 0x36, 0x2b, 0xc7, 0xc0, 0x18, 0xcd,
+	0x7d, 0xa7, 0x8b, 0x51, 0xee, 0xb9, 0x07, 0xf3, 0x6d, 0x6e, 0xbb, 0x77, 0x73, 0x4f, 0x3d, 0x33,
+	0xf7, 0xb2, 0x67, 0xe6, 0x9e, 0x99, 0xd0, 0xa5, 0x26, 0xf8, 0x8f, 0x16, 0x90, 0xf9, 0x43, 0x93,
+	0x2f, 0xb0, 0x9a, 0xe5, 0xd3, 0x03, 0xdb, 0xfa, 0x00, 0xb9, 0xa5, 0x15, 0x15, 0x09, 0xbd, 0x0f,
+	0xd2, 0xac, 0xb4, 0xc2, 0xf9, 0x8b, 0x05, 0xd7, 0x17, 0x1a, 0xf1, 0xbd, 0x06, 0xa9, 0x6a, 0x5e,
+	0xfb, 0xbf, 0x35, 0xaf, 0xff, 0xcf, 0x9a, 0xff, 0x06, 0x3b, 0x22, 0x7c, 0x80, 0x66, 0xad, 0xfe,
+	0x1e, 0xb4, 0xb2, 0x87, 0x42, 0x56, 0xd5, 0x54, 0xd1, 0xec, 0x67, 0x28, 0x46, 0x79, 0xce, 0x82,
+	0x51, 0x9e, 0x4f, 0x1e, 0xb2, 0xd4, 0xbd, 0xb0, 0x6b, 0x46, 0x94, 0xf7, 0x4b, 0x24, 0x8c, 0xf2,
+	0x32, 0x73, 0x11, 0x4c, 0x7f, 0xa8, 0x67, 0x9f, 0x01, 0xa4, 0xd8, 0x9b, 0xd0, 0x4a, 0xf3, 0xb7,
+	0xb4, 0xa5, 0xba, 0xef, 0x1c, 0x20, 0xbb, 0xb0, 0xe9, 0x86, 0x41, 0xc0, 0xdd, 0xf4, 0x58, 0xa4,
+	0x3c, 0x9e, 0xb1, 0x40, 0x3f, 0x72, 0xaa, 0x30, 0xd9, 0x81, 0xda, 0xd8, 0xd5, 0x76, 0x01, 0xa9,
+	0xd2, 0x97, 0xb3, 0xc9, 0x63, 0x97, 0xd6, 0xc6, 0x2e, 0xf9, 0x08, 0x56, 0xdd, 0x68, 0x7a, 0x12,
+	0x32, 0x4f, 0xf7, 0x6a, 0x6b, 0x92, 0xe1, 0x50, 0x61, 0x34, 0x23, 0x62, 0x67, 0x6e, 0xf4, 0x2c,
+	0x76, 0xc3, 0xe8, 0xcc, 0x8d, 0xd6, 0x86, 0x9a, 0x4c, 0xaa, 0x9b, 0x4f, 0xfd, 0x19, 0x7f, 0x1a,
+	0x33, 0x57, 0x15, 0x99, 0xa2, 0x9b, 0xcf, 0x71, 0x6a, 0x32, 0xe1, 0x07, 0x00, 0x8f, 0xa5, 0x6c,
+	0x14, 0x4e, 0x63, 0x7c, 0xe7, 0x26, 0xa9, 0xbd, 0x6a, 0x98, 0xf2, 0x51, 0x89, 0x44, 0x2b, 0xac,
+	0xd8, 0x93, 0xc4, 0x3c, 0x89, 0x42, 0x91, 0x70, 0xd9, 0xb2, 0x98, 0x3d, 0x09, 0x35, 0x08, 0xb4,
+	0xc4, 0x86, 0xf1, 0x39, 0xe1, 0x29, 0x43, 0x61, 0xf2, 0x8a, 0x6c, 0xd1, 0x7c, 0xee, 0xfc, 0x04,
+	0x36, 0x2b, 0x0e, 0xc4, 0x2b, 0xd5, 0x8c, 0x8e, 0xfa, 0x7c, 0x74, 0x18, 0xb1, 0xe1, 0xfc, 0xab,
+	0x06, 0x2b, 0xca, 0xde, 0xc4, 0xd1, 0xcf, 0x6b, 0x74, 0xe5, 0x86, 0x5e, 0x23, 0x29, 0xd8, 0x2b,
+	0xa8, 0xe7, 0x36, 0xb9, 0x03, 0xd7, 0x5e, 0xcd, 0x26, 0xa7, 0x7c, 0x12, 0xc6, 0x97, 0x03, 0xce,
+	0xa2, 0x67, 0xd8, 0x7a, 0x28, 0xbf, 0xce, 0x13, 0xc8, 0x6d, 0xe8, 0x94, 0xc0, 0x53, 0xf6, 0x4e,
+	0xbf, 0x63, 0xe7, 0x70, 0xd2, 0x83, 0xed, 0x1c, 0x3b, 0x0b, 0x45, 0x2e, 0x5c, 0xde, 0x1f, 0x74,
+	0x21, 0x8d, 0xdc, 0x85, 0xad, 0x2a, 0x8e, 0x5b, 0xa8, 0x97, 0xe0, 0x22, 0x12, 0x5e, 0xc2, 0xaf,
+	0xf0, 0x48, 0x4f, 0x02, 0xef, 0x30, 0x9c, 0x8a, 0x54, 0x7a, 0xbd, 0x4e, 0xcb, 0x20, 0x76, 0x5f,
+	0x19, 0x60, 0xf4, 0x96, 0x25, 0x0c, 0xdb, 0x69, 0x39, 0x7f, 0xc4, 0x53, 0xe6, 0x07, 0x3c, 0xeb,
+	0x30, 0x49, 0x61, 0xb6, 0x8c, 0x42, 0xcb, 0x8c, 0xce, 0x6f, 0xeb, 0xb0, 0x51, 0xe6, 0xc8, 0xd5,
+	0x3a, 0xe3, 0x6f, 0x95, 0x5a, 0x96, 0xa1, 0x56, 0x06, 0xe6, 0x6a, 0x9d, 0xf1, 0xb7, 0xc6, 0x47,
+	0x83, 0x12, 0xa6, 0xcd, 0x38, 0x0c, 0xc3, 0xe0, 0x30, 0xf4, 0xf8, 0x21, 0x73, 0x2f, 0xf8, 0xb3,
+	0xac, 0x81, 0xb4, 0xe8, 0x42, 0x9a, 0x76, 0x2a, 0xe2, 0x67, 0xfc, 0xed, 0x63, 0x2e, 0x72, 0xbb,
+	0x5b, 0x74, 0x9e, 0x60, 0x70, 0x3f, 0x09, 0xbc, 0x8c, 0xbb, 0x51, 0xe2, 0x2e, 0x08, 0xe4, 0xc7,
+	0x60, 0x6b, 0x70, 0x34, 0x8d, 0x67, 0xfe, 0x2c, 0x8c, 0x47, 0x11, 0x73, 0x95, 0x4e, 0xf2, 0x5a,
+	0xa7, 0x57, 0xd2, 0xc9, 0x1e, 0x10, 0x4d, 0x1b, 0xf2, 0x78, 0x92, 0x6d, 0xb5, 0x2a, 0x57, 0x2d,
+	0xa0, 0x18, 0x67, 0xc7, 0x6f, 0x3a, 0x49, 0xbe, 0x4f, 0xb3, 0x74, 0xf6, 0x12, 0xcd, 0x19, 0x42,
+	0x33, 0xab, 0x26, 0xd8, 0xdd, 0xbd, 0x9a, 0x4d, 0xf4, 0x4c, 0xba, 0xc0, 0xa2, 0x06, 0x82, 0x5e,
+	0x4a, 0x2e, 0x93, 0x94, 0xe7, 0x2c, 0x35, 0xc9, 0x52, 0x06, 0x9d, 0x7f, 0x60, 0xb9, 0x36, 0x8a,
+	0x0e, 0x56, 0xc2, 0x84, 0x4d, 0xa2, 0x80, 0x7b, 0xb9, 0x7b, 0x75, 0x25, 0xac, 0xc0, 0x68, 0x2c,
+	0x0d, 0x1d, 0x86, 0x22, 0xf5, 0xc5, 0x54, 0xb6, 0x6d, 0x6a, 0x89, 0xca, 0x9b, 0x2b, 0xe9, 0xe8,
+	0x96, 0xa9, 0xa8, 0xee, 0xa3, 0x92, 0x67, 0x9e, 0x40, 0x1e, 0xc0, 0xce, 0x54, 0x5c, 0x25, 0x4b,
+	0x27, 0xd0, 0x7b, 0x38, 0x9c, 0xdf, 0xe3, 0x6d, 0x6a, 0x54, 0xc9, 0x81, 0x9f, 0xa4, 0xe1, 0x38,
+	0x66, 0x93, 0xf7, 0x3c, 0x40, 0xef, 0xc2, 0xd6, 0x45, 0xc6, 0x36, 0x72, 0x2f, 0xf8, 0x84, 0x19,
+	0x2f, 0xe0, 0x45, 0x24, 0xac, 0x1f, 0x46, 0xf1, 0xcd, 0xec, 0x50, 0xdf, 0x6d, 0xd0, 0x39, 0xdc,
+	0x79, 0x2c, 0x3f, 0x1b, 0xe4, 0x20, 0xf9, 0x04, 0x5a, 0xb9, 0x48, 0xdb, 0x32, 0x1a, 0xa9, 0x45,
+	0x5a, 0xd3, 0x82, 0xd7, 0xf9, 0x1b, 0x3e, 0xf8, 0x8b, 0x4a, 0x2e, 0xbf, 0x68, 0x67, 0x8f, 0xbf,
+	0x9a, 0xef, 0x49, 0x77, 0x16, 0x6f, 0x28, 0xcc, 0x24, 0x7d, 0x84, 0x2a, 0x8c, 0xf9, 0x8a, 0x45,
+	0xfa, 0x9c, 0x25, 0xea, 0x33, 0xaa, 0x7e, 0xbe, 0x9b, 0x18, 0x36, 0xf8, 0xd3, 0x38, 0xd0, 0xcf,
+	0x67, 0x1c, 0x62, 0x14, 0xab, 0xc3, 0x1d, 0x86, 0x42, 0x70, 0x19, 0x42, 0x23, 0xff, 0x97, 0xd9,
+	0xfb, 0x6d, 0x21, 0x0d, 0x9d, 0x3f, 0x61, 0xef, 0x2a, 0x0b, 0xd4, 0xd3, 0x79, 0x9e, 0xe0, 0x3c,
+	0x84, 0xcd, 0xca, 0x55, 0x85, 0x2f, 0xc1, 0xe2, 0xb2, 0xb2, 0x2d, 0xe3, 0x25, 0x58, 0x30, 0x52,
+	0x83, 0xc5, 0xb9, 0x07, 0xeb, 0xa5, 0x3b, 0x0b, 0x0f, 0xc2, 0x66, 0x63, 0x5d, 0xb8, 0x70, 0x88,
+	0xc8, 0x84, 0xbd, 0xd3, 0xb1, 0x8e, 0xc3, 0xdb, 0xbf, 0xb6, 0x00, 0x8a, 0x2b, 0x85, 0x7c, 0x0b,
+	0xb6, 0xbe, 0x7c, 0x7e, 0xfa, 0xe2, 0xf1, 0xe1, 0x8b, 0xa7, 0x3f, 0x1f, 0x1e, 0xbd, 0x78, 0x76,
+	0xf6, 0xd5, 0xd9, 0x93, 0x9f, 0x9d, 0x75, 0x96, 0xc8, 0x0d, 0x20, 0x26, 0x61, 0x74, 0x44, 0x8f,
+	0xfb, 0x27, 0x1d, 0x8b, 0xd8, 0xb0, 0x6d, 0xe2, 0xc3, 0x3e, 0xed, 0x9f, 0x9c, 0x1c, 0x9d, 0x74,
+	0x6a, 0x64, 0x0b, 0x36, 0x4d, 0xca, 0xe1, 0xe9, 0xa8, 0x53, 0x27, 0x04, 0x36, 0x4c, 0xf0, 0xf1,
+	0x41, 0x67, 0xb9, 0xc7, 0xa0, 0x21, 0xef, 0x41, 0xb2, 0x0f, 0x1d, 0x8a, 0x6f, 0xf0, 0x24, 0x2d,
+	0xfe, 0xd8, 0x30, 0xee, 0x49, 0x9c, 0xef, 0xb4, 0xb3, 0xab, 0x19, 0xff, 0x41, 0xf9, 0x3e, 0xb4,
+	0xf1, 0xcf, 0x90, 0x11, 0x4f, 0x64, 0x34, 0xab, 0x2f, 0xac, 0x88, 0xec, 0x14, 0xc3, 0x5d, 0xeb,
+	0xae, 0xd5, 0xfb, 0xb3, 0x05, 0xcd, 0x53, 0x7d, 0x41, 0x93, 0x7b, 0x40, 0xf4, 0x36, 0xe6, 0x57,
+	0x6a, 0xfd, 0x39, 0xbe, 0x40, 0xca, 0x5b, 0x15, 0x8b, 0xcc, 0x4f, 0xe8, 0xba, 0x35, 0x89, 0xfc,
+	0xc5, 0x8b, 0x3e, 0x85, 0xeb, 0xd9, 0x4e, 0xe5, 0xef, 0xda, 0x5b, 0xc6, 0x53, 0x64, 0xe1, 0xd2,
+	0xde, 0x03, 0x58, 0x96, 0xdf, 0x77, 0xef, 0x43, 0x73, 0x84, 0x7f, 0x76, 0xe0, 0xb8, 0x93, 0x7f,
+	0xad, 0xd1, 0x6d, 0xe7, 0xce, 0x8d, 0xb9, 0x9e, 0xf5, 0x08, 0xff, 0x8b, 0xda, 0xb5, 0x7a, 0x87,
+	0xb0, 0x2c, 0x3b, 0xc2, 0xcf, 0x60, 0x1d, 0xd7, 0x17, 0x2d, 0xa2, 0x16, 0x52, 0xf4, 0xae, 0x57,
+	0x0b, 0x79, 0xf8, 0xf0, 0x17, 0x5f, 0x8c, 0xfd, 0xf4, 0x62, 0x7a, 0xbe, 0xe7, 0x86, 0x93, 0x7d,
+	0xcf, 0x17, 0xe3, 0x4b, 0x16, 0xf8, 0x22, 0xff, 0x7f, 0xec, 0xe3, 0x71, 0xf8, 0xb1, 0xec, 0x5b,
+	0xf6, 0xaf, 0xfc, 0xeb, 0xec, 0x7c, 0x45, 0x4a, 0xbd, 0xf7, 0xdf, 0x01, 0x00, 0xe1, 0x64, 0x3d,
+	0x19, 0x5e, 0x1b, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// AgentClient is the client API for Agent service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AgentClient interface {
+	RequestAgentInfo(ctx context.Context, in *PAgentInfo, opts ...grpc.CallOption) (*PResult, error)
+	PingSession(ctx context.Context, opts ...grpc.CallOption) (Agent_PingSessionClient, error)
+}
+
+type agentClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAgentClient(cc grpc.ClientConnInterface) AgentClient {
+	return &agentClient{cc}
+}
+
+func (c *agentClient) RequestAgentInfo(ctx context.Context, in *PAgentInfo, opts ...grpc.CallOption) (*PResult, error) {
+	out := new(PResult)
+	err := c.cc.Invoke(ctx, "/v1.Agent/RequestAgentInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) PingSession(ctx context.Context, opts ...grpc.CallOption) (Agent_PingSessionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Agent_serviceDesc.Streams[0], "/v1.Agent/PingSession", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &agentPingSessionClient{stream}
+	return x, nil
+}
+
+type Agent_PingSessionClient interface {
+	Send(*PPing) error
+	Recv() (*PPing, error)
+	grpc.ClientStream
+}
+
+type agentPingSessionClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentPingSessionClient) Send(m *PPing) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *agentPingSessionClient) Recv() (*PPing, error) {
+	m := new(PPing)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AgentServer is the server API for Agent service.
+type AgentServer interface {
+	RequestAgentInfo(context.Context, *PAgentInfo) (*PResult, error)
+	PingSession(Agent_PingSessionServer) error
+}
+
+// UnimplementedAgentServer can be embedded to have forward compatible implementations.
+type UnimplementedAgentServer struct {
+}
+
+func (*UnimplementedAgentServer) RequestAgentInfo(ctx context.Context, req *PAgentInfo) (*PResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestAgentInfo not implemented")
+}
+func (*UnimplementedAgentServer) PingSession(srv Agent_PingSessionServer) error {
+	return status.Errorf(codes.Unimplemented, "method PingSession not implemented")
+}
+
+func RegisterAgentServer(s *grpc.Server, srv AgentServer) {
+	s.RegisterService(&_Agent_serviceDesc, srv)
+}
+
+func _Agent_RequestAgentInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PAgentInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).RequestAgentInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Agent/RequestAgentInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).RequestAgentInfo(ctx, req.(*PAgentInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_PingSession_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServer).PingSession(&agentPingSessionServer{stream})
+}
+
+type Agent_PingSessionServer interface {
+	Send(*PPing) error
+	Recv() (*PPing, error)
+	grpc.ServerStream
+}
+
+type agentPingSessionServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentPingSessionServer) Send(m *PPing) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *agentPingSessionServer) Recv() (*PPing, error) {
+	m := new(PPing)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Agent_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.Agent",
+	HandlerType: (*AgentServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RequestAgentInfo",
+			Handler:    _Agent_RequestAgentInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PingSession",
+			Handler:       _Agent_PingSession_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "internal/pinpoint_grpc_v1/v1.proto",
+}
+
+// MetadataClient is the client API for Metadata service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type MetadataClient interface {
+	RequestSqlMetaData(ctx context.Context, in *PSqlMetaData, opts ...grpc.CallOption) (*PResult, error)
+	RequestApiMetaData(ctx context.Context, in *PApiMetaData, opts ...grpc.CallOption) (*PResult, error)
+	RequestStringMetaData(ctx context.Context, in *PStringMetaData, opts ...grpc.CallOption) (*PResult, error)
+}
+
+type metadataClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMetadataClient(cc grpc.ClientConnInterface) MetadataClient {
+	return &metadataClient{cc}
+}
+
+func (c *metadataClient) RequestSqlMetaData(ctx context.Context, in *PSqlMetaData, opts ...grpc.CallOption) (*PResult, error) {
+	out := new(PResult)
+	err := c.cc.Invoke(ctx, "/v1.Metadata/RequestSqlMetaData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataClient) RequestApiMetaData(ctx context.Context, in *PApiMetaData, opts ...grpc.CallOption) (*PResult, error) {
+	out := new(PResult)
+	err := c.cc.Invoke(ctx, "/v1.Metadata/RequestApiMetaData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataClient) RequestStringMetaData(ctx context.Context, in *PStringMetaData, opts ...grpc.CallOption) (*PResult, error) {
+	out := new(PResult)
+	err := c.cc.Invoke(ctx, "/v1.Metadata/RequestStringMetaData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MetadataServer is the server API for Metadata service.
+type MetadataServer interface {
+	RequestSqlMetaData(context.Context, *PSqlMetaData) (*PResult, error)
+	RequestApiMetaData(context.Context, *PApiMetaData) (*PResult, error)
+	RequestStringMetaData(context.Context, *PStringMetaData) (*PResult, error)
+}
+
+// UnimplementedMetadataServer can be embedded to have forward compatible implementations.
+type UnimplementedMetadataServer struct {
+}
+
+func (*UnimplementedMetadataServer) RequestSqlMetaData(ctx context.Context, req *PSqlMetaData) (*PResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestSqlMetaData not implemented")
+}
+func (*UnimplementedMetadataServer) RequestApiMetaData(ctx context.Context, req *PApiMetaData) (*PResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestApiMetaData not implemented")
+}
+func (*UnimplementedMetadataServer) RequestStringMetaData(ctx context.Context, req *PStringMetaData) (*PResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestStringMetaData not implemented")
+}
+
+func RegisterMetadataServer(s *grpc.Server, srv MetadataServer) {
+	s.RegisterService(&_Metadata_serviceDesc, srv)
+}
+
+func _Metadata_RequestSqlMetaData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PSqlMetaData)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServer).RequestSqlMetaData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Metadata/RequestSqlMetaData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServer).RequestSqlMetaData(ctx, req.(*PSqlMetaData))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Metadata_RequestApiMetaData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PApiMetaData)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServer).RequestApiMetaData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Metadata/RequestApiMetaData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServer).RequestApiMetaData(ctx, req.(*PApiMetaData))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Metadata_RequestStringMetaData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PStringMetaData)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServer).RequestStringMetaData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Metadata/RequestStringMetaData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServer).RequestStringMetaData(ctx, req.(*PStringMetaData))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Metadata_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.Metadata",
+	HandlerType: (*MetadataServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RequestSqlMetaData",
+			Handler:    _Metadata_RequestSqlMetaData_Handler,
+		},
+		{
+			MethodName: "RequestApiMetaData",
+			Handler:    _Metadata_RequestApiMetaData_Handler,
+		},
+		{
+			MethodName: "RequestStringMetaData",
+			Handler:    _Metadata_RequestStringMetaData_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/pinpoint_grpc_v1/v1.proto",
+}
+
+// SpanClient is the client API for Span service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SpanClient interface {
+	SendSpan(ctx context.Context, opts ...grpc.CallOption) (Span_SendSpanClient, error)
+}
+
+type spanClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSpanClient(cc grpc.ClientConnInterface) SpanClient {
+	return &spanClient{cc}
+}
+
+func (c *spanClient) SendSpan(ctx context.Context, opts ...grpc.CallOption) (Span_SendSpanClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Span_serviceDesc.Streams[0], "/v1.Span/SendSpan", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &spanSendSpanClient{stream}
+	return x, nil
+}
+
+type Span_SendSpanClient interface {
+	Send(*PSpanMessage) error
+	CloseAndRecv() (*empty.Empty, error)
+	grpc.ClientStream
+}
+
+type spanSendSpanClient struct {
+	grpc.ClientStream
+}
+
+func (x *spanSendSpanClient) Send(m *PSpanMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *spanSendSpanClient) CloseAndRecv() (*empty.Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(empty.Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SpanServer is the server API for Span service.
+type SpanServer interface {
+	SendSpan(Span_SendSpanServer) error
+}
+
+// UnimplementedSpanServer can be embedded to have forward compatible implementations.
+type UnimplementedSpanServer struct {
+}
+
+func (*UnimplementedSpanServer) SendSpan(srv Span_SendSpanServer) error {
+	return status.Errorf(codes.Unimplemented, "method SendSpan not implemented")
+}
+
+func RegisterSpanServer(s *grpc.Server, srv SpanServer) {
+	s.RegisterService(&_Span_serviceDesc, srv)
+}
+
+func _Span_SendSpan_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SpanServer).SendSpan(&spanSendSpanServer{stream})
+}
+
+type Span_SendSpanServer interface {
+	SendAndClose(*empty.Empty) error
+	Recv() (*PSpanMessage, error)
+	grpc.ServerStream
+}
+
+type spanSendSpanServer struct {
+	grpc.ServerStream
+}
+
+func (x *spanSendSpanServer) SendAndClose(m *empty.Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *spanSendSpanServer) Recv() (*PSpanMessage, error) {
+	m := new(PSpanMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Span_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.Span",
+	HandlerType: (*SpanServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SendSpan",
+			Handler:       _Span_SendSpan_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "internal/pinpoint_grpc_v1/v1.proto",
+}
+
+// StatClient is the client API for Stat service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type StatClient interface {
+	SendAgentStat(ctx context.Context, opts ...grpc.CallOption) (Stat_SendAgentStatClient, error)
+}
+
+type statClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStatClient(cc grpc.ClientConnInterface) StatClient {
+	return &statClient{cc}
+}
+
+func (c *statClient) SendAgentStat(ctx context.Context, opts ...grpc.CallOption) (Stat_SendAgentStatClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Stat_serviceDesc.Streams[0], "/v1.Stat/SendAgentStat", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &statSendAgentStatClient{stream}
+	return x, nil
+}
+
+type Stat_SendAgentStatClient interface {
+	Send(*PStatMessage) error
+	CloseAndRecv() (*empty.Empty, error)
+	grpc.ClientStream
+}
+
+type statSendAgentStatClient struct {
+	grpc.ClientStream
+}
+
+func (x *statSendAgentStatClient) Send(m *PStatMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *statSendAgentStatClient) CloseAndRecv() (*empty.Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(empty.Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// StatServer is the server API for Stat service.
+type StatServer interface {
+	SendAgentStat(Stat_SendAgentStatServer) error
+}
+
+// UnimplementedStatServer can be embedded to have forward compatible implementations.
+type UnimplementedStatServer struct {
+}
+
+func (*UnimplementedStatServer) SendAgentStat(srv Stat_SendAgentStatServer) error {
+	return status.Errorf(codes.Unimplemented, "method SendAgentStat not implemented")
+}
+
+func RegisterStatServer(s *grpc.Server, srv StatServer) {
+	s.RegisterService(&_Stat_serviceDesc, srv)
+}
+
+func _Stat_SendAgentStat_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(StatServer).SendAgentStat(&statSendAgentStatServer{stream})
+}
+
+type Stat_SendAgentStatServer interface {
+	SendAndClose(*empty.Empty) error
+	Recv() (*PStatMessage, error)
+	grpc.ServerStream
+}
+
+type statSendAgentStatServer struct {
+	grpc.ServerStream
+}
+
+func (x *statSendAgentStatServer) SendAndClose(m *empty.Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *statSendAgentStatServer) Recv() (*PStatMessage, error) {
+	m := new(PStatMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Stat_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.Stat",
+	HandlerType: (*StatServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SendAgentStat",
+			Handler:       _Stat_SendAgentStat_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "internal/pinpoint_grpc_v1/v1.proto",
+}
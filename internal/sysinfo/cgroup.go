@@ -0,0 +1,233 @@
+package sysinfo
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrCgroupNotFound is returned if /proc/self/cgroup lists neither the
+	// cgroup v2 hierarchy nor the cpu and memory controllers of cgroup v1.
+	ErrCgroupNotFound = errors.New("cgroup not found")
+
+	errCgroupNoLimit = errors.New("cgroup has no limit")
+)
+
+// cgroupV1UnlimitedMemory is the lowest memory limit cgroup v1 reports when
+// no limit is set, the maximum int64 rounded down to the page size.
+const cgroupV1UnlimitedMemory = 1 << 62
+
+// Cgroup reads the CPU and memory controllers of the control group of the
+// process, for processes running in containers.
+type Cgroup struct {
+	// Version is 1 or 2.
+	Version   int
+	cpuDir    string
+	cpuAcct   string
+	memoryDir string
+}
+
+// NewCgroup detects the cgroup of the process.
+func NewCgroup() (*Cgroup, error) {
+	if "linux" != runtime.GOOS {
+		return nil, ErrFeatureUnsupported
+	}
+	return NewCgroupFrom("/")
+}
+
+// NewCgroupFrom detects the cgroup of the process from the proc and cgroup
+// filesystems mounted under root.
+func NewCgroupFrom(root string) (*Cgroup, error) {
+	f, err := os.Open(filepath.Join(root, "proc/self/cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mount := filepath.Join(root, "sys/fs/cgroup")
+	c := &Cgroup{}
+	var unified string
+	hasUnified := false
+
+	// Each line consists of the hierarchy ID, the comma separated
+	// controllers and the path of the cgroup, for example
+	//   4:cpu,cpuacct:/docker/67f98c9e6188
+	//   0::/kubepods/pod1/67f98c9e6188
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		cols := strings.SplitN(scanner.Text(), ":", 3)
+		if len(cols) < 3 {
+			continue
+		}
+		if "0" == cols[0] && "" == cols[1] {
+			unified, hasUnified = cols[2], true
+			continue
+		}
+		for _, controller := range strings.Split(cols[1], ",") {
+			switch controller {
+			case "cpu":
+				c.cpuDir = cgroupDir(mount, cols[1], controller, cols[2], "cpu.cfs_quota_us")
+			case "cpuacct":
+				c.cpuAcct = cgroupDir(mount, cols[1], controller, cols[2], "cpuacct.usage")
+			case "memory":
+				c.memoryDir = cgroupDir(mount, cols[1], controller, cols[2], "memory.limit_in_bytes")
+			}
+		}
+	}
+
+	switch {
+	case "" != c.cpuDir || "" != c.memoryDir:
+		c.Version = 1
+	case hasUnified:
+		dir := cgroupDir(mount, "", "", unified, "cgroup.controllers")
+		if "" == dir {
+			return nil, ErrCgroupNotFound
+		}
+		c.Version = 2
+		c.cpuDir, c.cpuAcct, c.memoryDir = dir, dir, dir
+	default:
+		return nil, ErrCgroupNotFound
+	}
+	return c, nil
+}
+
+// cgroupDir returns the directory of the cgroup at path holding file.  The
+// cgroup is looked up at its path below the mount of the controllers, then
+// at the root of the mount, which is where it is found in containers with
+// their own cgroup namespace.  It returns "" if file is in neither.
+func cgroupDir(mount string, controllers string, controller string, path string, file string) string {
+	var mounts []string
+	if "" == controllers {
+		mounts = []string{mount}
+	} else {
+		mounts = []string{filepath.Join(mount, controllers), filepath.Join(mount, controller)}
+	}
+	for _, m := range mounts {
+		for _, dir := range []string{filepath.Join(m, path), m} {
+			if _, err := os.Stat(filepath.Join(dir, file)); nil == err {
+				return dir
+			}
+		}
+	}
+	return ""
+}
+
+// CPUQuota returns the number of CPUs the cgroup may use, or an error if it
+// has no quota.
+func (c *Cgroup) CPUQuota() (float64, error) {
+	if "" == c.cpuDir {
+		return 0, errCgroupNoLimit
+	}
+	var quota, period int64
+	if 2 == c.Version {
+		// cpu.max is "$MAX $PERIOD", with "max" meaning no quota.
+		fields, err := readCgroupFields(c.cpuDir, "cpu.max")
+		if err != nil {
+			return 0, err
+		}
+		if len(fields) != 2 || "max" == fields[0] {
+			return 0, errCgroupNoLimit
+		}
+		if quota, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+			return 0, err
+		}
+		if period, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+			return 0, err
+		}
+	} else {
+		var err error
+		if quota, err = readCgroupInt(c.cpuDir, "cpu.cfs_quota_us"); err != nil {
+			return 0, err
+		}
+		if period, err = readCgroupInt(c.cpuDir, "cpu.cfs_period_us"); err != nil {
+			return 0, err
+		}
+	}
+	if quota <= 0 || period <= 0 {
+		return 0, errCgroupNoLimit
+	}
+	return float64(quota) / float64(period), nil
+}
+
+// CPUUsage returns the total CPU time used by the processes of the cgroup.
+func (c *Cgroup) CPUUsage() (time.Duration, error) {
+	if "" == c.cpuAcct {
+		return 0, ErrCgroupNotFound
+	}
+	if 1 == c.Version {
+		ns, err := readCgroupInt(c.cpuAcct, "cpuacct.usage")
+		return time.Duration(ns), err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(c.cpuAcct, "cpu.stat"))
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && "usage_usec" == fields[0] {
+			usec, err := strconv.ParseInt(fields[1], 10, 64)
+			return time.Duration(usec) * time.Microsecond, err
+		}
+	}
+	return 0, errors.New("usage_usec not found in cpu.stat")
+}
+
+// MemoryLimit returns the memory limit of the cgroup in bytes, or an error
+// if it has none.
+func (c *Cgroup) MemoryLimit() (int64, error) {
+	if "" == c.memoryDir {
+		return 0, errCgroupNoLimit
+	}
+	file := "memory.limit_in_bytes"
+	if 2 == c.Version {
+		file = "memory.max"
+		if fields, err := readCgroupFields(c.memoryDir, file); nil == err && len(fields) == 1 && "max" == fields[0] {
+			return 0, errCgroupNoLimit
+		}
+	}
+	limit, err := readCgroupInt(c.memoryDir, file)
+	if err != nil {
+		return 0, err
+	}
+	if limit <= 0 || limit >= cgroupV1UnlimitedMemory {
+		return 0, errCgroupNoLimit
+	}
+	return limit, nil
+}
+
+// MemoryUsage returns the memory used by the processes of the cgroup in
+// bytes, page cache included.
+func (c *Cgroup) MemoryUsage() (int64, error) {
+	if "" == c.memoryDir {
+		return 0, ErrCgroupNotFound
+	}
+	if 2 == c.Version {
+		return readCgroupInt(c.memoryDir, "memory.current")
+	}
+	return readCgroupInt(c.memoryDir, "memory.usage_in_bytes")
+}
+
+func readCgroupFields(dir string, file string) ([]string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
+
+func readCgroupInt(dir string, file string) (int64, error) {
+	fields, err := readCgroupFields(dir, file)
+	if err != nil {
+		return 0, err
+	}
+	if len(fields) != 1 {
+		return 0, errors.New("unexpected content of " + file)
+	}
+	return strconv.ParseInt(fields[0], 10, 64)
+}
@@ -0,0 +1,55 @@
+package sysinfo
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCgroupLimits(t *testing.T) {
+	testCases := []struct {
+		root        string
+		version     int
+		cpus        float64
+		usage       time.Duration
+		memoryLimit int64
+		memoryUsage int64
+	}{
+		{root: "cgroup_v1", version: 1, cpus: 1.5, usage: 123456789 * time.Microsecond, memoryLimit: 512 << 20, memoryUsage: 128 << 20},
+		{root: "cgroup_v1_unlimited", version: 1, usage: 5000, memoryUsage: 4096},
+		{root: "cgroup_v2", version: 2, cpus: 2, usage: 2500 * time.Millisecond, memoryLimit: 1 << 30, memoryUsage: 256 << 20},
+		{root: "cgroup_v2_unlimited", version: 2, usage: time.Millisecond, memoryUsage: 8192},
+	}
+
+	for _, tc := range testCases {
+		c, err := NewCgroupFrom(filepath.Join("testdata", tc.root))
+		if err != nil {
+			t.Errorf("%s: %v", tc.root, err)
+			continue
+		}
+		if c.Version != tc.version {
+			t.Errorf("%s: version %d", tc.root, c.Version)
+		}
+		if cpus, err := c.CPUQuota(); cpus != tc.cpus || (nil == err) != (tc.cpus > 0) {
+			t.Errorf("%s: cpu quota %v %v", tc.root, cpus, err)
+		}
+		if usage, err := c.CPUUsage(); usage != tc.usage || nil != err {
+			t.Errorf("%s: cpu usage %v %v", tc.root, usage, err)
+		}
+		if limit, err := c.MemoryLimit(); limit != tc.memoryLimit || (nil == err) != (tc.memoryLimit > 0) {
+			t.Errorf("%s: memory limit %v %v", tc.root, limit, err)
+		}
+		if usage, err := c.MemoryUsage(); usage != tc.memoryUsage || nil != err {
+			t.Errorf("%s: memory usage %v %v", tc.root, usage, err)
+		}
+	}
+}
+
+func TestCgroupNotFound(t *testing.T) {
+	if _, err := NewCgroupFrom(filepath.Join("testdata", "no_cgroup")); err != ErrCgroupNotFound {
+		t.Error(err)
+	}
+	if _, err := NewCgroupFrom(filepath.Join("testdata", "missing")); nil == err {
+		t.Error("cgroup found without /proc/self/cgroup")
+	}
+}
@@ -0,0 +1,170 @@
+package pinpoint
+
+import (
+	"bytes"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// activeTransactions tracks the transactions which have started and not
+// ended yet, and the goroutines they were started on.  The zero value is
+// ready to use.
+type activeTransactions struct {
+	sync.Mutex
+	txns map[*txn]activeTxn
+	// trackGoroutines records the goroutine of every transaction.  Reading
+	// it walks the stack, so it is only done when the collector may ask
+	// for thread dumps.  It is set before any transaction starts.
+	trackGoroutines bool
+}
+
+// activeTxn is a transaction in flight.  Its goroutine is 0 unless the
+// goroutines are tracked.
+type activeTxn struct {
+	txn       *txn
+	start     time.Time
+	goroutine int64
+}
+
+// add records t as in flight since its start, owned by the calling
+// goroutine.
+func (a *activeTransactions) add(t *txn) {
+	var goroutine int64
+	if a.trackGoroutines {
+		goroutine = currentGoroutineID()
+	}
+
+	a.Lock()
+	defer a.Unlock()
+	if nil == a.txns {
+		a.txns = make(map[*txn]activeTxn)
+	}
+	a.txns[t] = activeTxn{txn: t, start: t.Start, goroutine: goroutine}
+}
+
+// remove forgets t once it has ended.
+func (a *activeTransactions) remove(t *txn) {
+	a.Lock()
+	defer a.Unlock()
+	delete(a.txns, t)
+}
+
+// histogram counts the active transactions at now in the fast, normal, slow
+// and very slow buckets of activeThreadCountSlots by how long they have been
+// running.
+func (a *activeTransactions) histogram(now time.Time) []int32 {
+	a.Lock()
+	defer a.Unlock()
+	counts := make([]int32, len(activeThreadCountSlots)+1)
+	for _, active := range a.txns {
+		elapsed := now.Sub(active.start)
+		slot := len(activeThreadCountSlots)
+		for i, limit := range activeThreadCountSlots {
+			if elapsed <= limit {
+				slot = i
+				break
+			}
+		}
+		counts[slot]++
+	}
+	return counts
+}
+
+// snapshot returns the active transactions, the longest running first.
+func (a *activeTransactions) snapshot() []activeTxn {
+	a.Lock()
+	snapshot := make([]activeTxn, 0, len(a.txns))
+	for _, active := range a.txns {
+		snapshot = append(snapshot, active)
+	}
+	a.Unlock()
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].start.Before(snapshot[j].start)
+	})
+	return snapshot
+}
+
+var goroutinePrefix = []byte("goroutine ")
+
+// currentGoroutineID returns the id of the calling goroutine, read from the
+// header of its stack trace, or 0 if it cannot be parsed.
+func currentGoroutineID() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	id, _ := parseGoroutineHeader(buf[:n])
+	return id
+}
+
+// parseGoroutineHeader parses the id and the wait reason from the first line
+// of a goroutine stack trace such as "goroutine 7 [chan receive]:".
+func parseGoroutineHeader(stack []byte) (int64, string) {
+	if !bytes.HasPrefix(stack, goroutinePrefix) {
+		return 0, ""
+	}
+	stack = stack[len(goroutinePrefix):]
+	end := bytes.IndexByte(stack, ' ')
+	if end < 0 {
+		return 0, ""
+	}
+	id, err := strconv.ParseInt(string(stack[:end]), 10, 64)
+	if nil != err {
+		return 0, ""
+	}
+	var state string
+	if from := bytes.IndexByte(stack, '['); from >= 0 {
+		if to := bytes.IndexByte(stack[from:], ']'); to >= 0 {
+			state = string(stack[from+1 : from+to])
+		}
+	}
+	return id, state
+}
+
+// goroutineStack is the state and the stack frames of a goroutine.
+type goroutineStack struct {
+	state  string
+	frames []string
+}
+
+// goroutineStacks returns the stacks of all goroutines by id, keeping at
+// most maxFrames frames of each.  Goroutines past maxGoroutineDumpSize bytes
+// of stack traces are left out.
+func goroutineStacks(maxFrames int) map[int64]goroutineStack {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxGoroutineDumpSize {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	return parseGoroutineStacks(buf, maxFrames)
+}
+
+// parseGoroutineStacks parses the output of runtime.Stack for all
+// goroutines.  Each frame is the function line joined with its location.
+func parseGoroutineStacks(dump []byte, maxFrames int) map[int64]goroutineStack {
+	stacks := make(map[int64]goroutineStack)
+	for _, block := range bytes.Split(dump, []byte("\n\n")) {
+		lines := bytes.Split(bytes.TrimSpace(block), []byte("\n"))
+		id, state := parseGoroutineHeader(lines[0])
+		if 0 == id {
+			continue
+		}
+		var frames []string
+		for i := 1; i < len(lines) && len(frames) < maxFrames; i++ {
+			frame := string(lines[i])
+			if i+1 < len(lines) && bytes.HasPrefix(lines[i+1], []byte("\t")) {
+				i++
+				frame += " " + string(bytes.TrimSpace(lines[i]))
+			}
+			frames = append(frames, frame)
+		}
+		stacks[id] = goroutineStack{state: state, frames: frames}
+	}
+	return stacks
+}
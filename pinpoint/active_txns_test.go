@@ -0,0 +1,57 @@
+package pinpoint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoroutineHeader(t *testing.T) {
+	testcases := []struct {
+		header string
+		id     int64
+		state  string
+	}{
+		{header: "goroutine 7 [running]:", id: 7, state: "running"},
+		{header: "goroutine 123 [chan receive, 2 minutes]:", id: 123, state: "chan receive, 2 minutes"},
+		{header: "goroutine x [running]:", id: 0, state: ""},
+		{header: "created by main.main", id: 0, state: ""},
+	}
+	for _, tc := range testcases {
+		if id, state := parseGoroutineHeader([]byte(tc.header)); id != tc.id || state != tc.state {
+			t.Error(tc.header, id, state)
+		}
+	}
+	if id := currentGoroutineID(); id <= 0 {
+		t.Error(id)
+	}
+}
+
+func TestParseGoroutineStacks(t *testing.T) {
+	dump := strings.Join([]string{
+		"goroutine 1 [running]:",
+		"main.handler(0x1)",
+		"\t/app/main.go:12 +0x1d",
+		"main.main()",
+		"\t/app/main.go:5 +0x3",
+		"",
+		"goroutine 9 [select]:",
+		"net/http.(*conn).serve(0xc000)",
+		"\t/go/src/net/http/server.go:1900 +0x2f",
+		"created by net/http.(*Server).Serve",
+		"\t/go/src/net/http/server.go:2900 +0x35",
+		"",
+	}, "\n")
+	stacks := parseGoroutineStacks([]byte(dump), 1)
+	want := map[int64]goroutineStack{
+		1: {state: "running", frames: []string{"main.handler(0x1) /app/main.go:12 +0x1d"}},
+		9: {state: "select", frames: []string{"net/http.(*conn).serve(0xc000) /go/src/net/http/server.go:1900 +0x2f"}},
+	}
+	if !reflect.DeepEqual(stacks, want) {
+		t.Error(stacks)
+	}
+	if stacks := parseGoroutineStacks([]byte(dump), 10); len(stacks[9].frames) != 2 ||
+		stacks[9].frames[1] != "created by net/http.(*Server).Serve /go/src/net/http/server.go:2900 +0x35" {
+		t.Error(stacks[9].frames)
+	}
+}
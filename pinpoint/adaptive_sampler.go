@@ -23,6 +23,11 @@ type adaptiveSampler struct {
 		numSeen    uint64
 		end        time.Time
 	}
+
+	// onPeriod, when set, is called with the end and the counts of the
+	// period just ended and the sample ratio of the period starting.  It is
+	// called without the lock held.
+	onPeriod func(end time.Time, numSeen, numSampled uint64, sampleRatio float32)
 }
 
 // newAdaptiveSampler creates an adaptiveSampler.
@@ -40,13 +45,34 @@ func newAdaptiveSampler(period time.Duration, target uint64, now time.Time) *ada
 // computeSampled calculates if the transaction should be sampled.
 func (as *adaptiveSampler) computeSampled(priority float32, now time.Time) bool {
 	as.Lock()
-	defer as.Unlock()
+	sampled, ended := as.computeSampledLocked(priority, now)
+	onPeriod := as.onPeriod
+	as.Unlock()
+
+	if nil != ended && nil != onPeriod {
+		onPeriod(ended.end, ended.numSeen, ended.numSampled, ended.sampleRatio)
+	}
+	return sampled
+}
+
+// endedPeriod is the last period rolled over by computeSampledLocked.
+type endedPeriod struct {
+	end         time.Time
+	numSeen     uint64
+	numSampled  uint64
+	sampleRatio float32
+}
 
+// computeSampledLocked calculates if the transaction should be sampled, and
+// returns the period which ended before now, if any.  The periods roll over
+// on the first transaction after their end, so the end reported is the one
+// the period was due to, not now.
+func (as *adaptiveSampler) computeSampledLocked(priority float32, now time.Time) (bool, *endedPeriod) {
 	// Never sample anything if the target is zero.  This is not an expected
 	// connect reply response, but it is used for the placeholder run (app
 	// not connected yet), and is used for testing.
 	if 0 == as.target {
-		return false
+		return false, nil
 	}
 
 	// If the current time is after the end of the "currentPeriod".  This is in
@@ -54,7 +80,13 @@ func (as *adaptiveSampler) computeSampled(priority float32, now time.Time) bool
 	// i.e. for situations where a single call to
 	//    as.currentPeriod.end = as.currentPeriod.end.Add(as.period)
 	// might not catch us up to the current period
+	var ended *endedPeriod
 	for now.After(as.currentPeriod.end) {
+		ended = &endedPeriod{
+			end:        as.currentPeriod.end,
+			numSeen:    as.currentPeriod.numSeen,
+			numSampled: as.currentPeriod.numSampled,
+		}
 		as.priorityMin = 0.0
 		if as.currentPeriod.numSeen > 0 {
 			sampledRatio := float32(as.target) / float32(as.currentPeriod.numSeen)
@@ -64,6 +96,9 @@ func (as *adaptiveSampler) computeSampled(priority float32, now time.Time) bool
 		as.currentPeriod.numSeen = 0
 		as.currentPeriod.end = as.currentPeriod.end.Add(as.period)
 	}
+	if nil != ended {
+		ended.sampleRatio = as.sampleRatio()
+	}
 
 	as.currentPeriod.numSeen++
 
@@ -72,20 +107,69 @@ func (as *adaptiveSampler) computeSampled(priority float32, now time.Time) bool
 	if as.currentPeriod.numSampled > as.target {
 		if as.computeSampledBackoff(as.target, as.currentPeriod.numSeen, as.currentPeriod.numSampled) {
 			as.currentPeriod.numSampled++
-			return true
+			return true, ended
 		}
-		return false
+		return false, ended
 	}
 
 	if priority >= as.priorityMin {
 		as.currentPeriod.numSampled++
-		return true
+		return true, ended
 	}
 
-	return false
+	return false, ended
+}
+
+// sampleRatio returns the share of the transactions sampled in the current
+// period, before the backoff applied once the target is exceeded.
+func (as *adaptiveSampler) sampleRatio() float32 {
+	if as.priorityMin <= 0.0 {
+		return 1.0
+	}
+	return 1.0 - as.priorityMin
 }
 
 func (as *adaptiveSampler) computeSampledBackoff(target uint64, decidedCount uint64, sampledTrueCount uint64) bool {
 	return float64(randUint64N(decidedCount)) <
 		math.Pow(float64(target), (float64(target)/float64(sampledTrueCount)))-math.Pow(float64(target), 0.5)
 }
+
+// targetSampler is the Sampler of SamplingTypeAdaptive.  It feeds the
+// adaptiveSampler with random priorities, so that about perMinute new traces
+// are sampled every minute whatever the traffic.
+type targetSampler struct {
+	*adaptiveSampler
+	now func() time.Time
+}
+
+// NewAdaptiveSampler returns a Sampler aiming for perMinute new traces sampled
+// per minute.  The share of the traces sampled is recomputed every minute
+// from the traffic of the previous one, and sampling backs off exponentially
+// once the target is exceeded within a minute.
+func NewAdaptiveSampler(perMinute int) Sampler {
+	return newTargetSampler(perMinute, nil)
+}
+
+// newTargetSampler creates a targetSampler logging the effective sampling
+// rate to lg every minute, if lg is not nil.
+func newTargetSampler(perMinute int, lg Logger) *targetSampler {
+	s := &targetSampler{now: time.Now}
+	s.adaptiveSampler = newAdaptiveSampler(time.Minute, uint64(perMinute), s.now())
+	if nil != lg {
+		target := perMinute
+		s.onPeriod = func(end time.Time, numSeen, numSampled uint64, sampleRatio float32) {
+			lg.Info("adaptive sampling rate", map[string]interface{}{
+				"target":     target,
+				"period_end": end.Format(time.RFC3339),
+				"seen":       numSeen,
+				"sampled":    numSampled,
+				"rate":       sampleRatio,
+			})
+		}
+	}
+	return s
+}
+
+func (s *targetSampler) IsSampled(req SamplingRequest) bool {
+	return s.computeSampled(newPriority().Float32(), s.now())
+}
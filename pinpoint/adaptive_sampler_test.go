@@ -4,6 +4,8 @@
 package pinpoint
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 	"time"
 )
@@ -73,6 +75,31 @@ func TestAdaptiveSamplerSkipPeriod(t *testing.T) {
 	assert(t, sampler.computeSampled(0.0, now))
 }
 
+func TestAdaptiveSamplerOnPeriod(t *testing.T) {
+	start := time.Now()
+	sampler := newAdaptiveSampler(60*time.Second, 2, start)
+	var ends []time.Time
+	sampler.onPeriod = func(end time.Time, numSeen, numSampled uint64, sampleRatio float32) {
+		// the lock is not held
+		sampler.Lock()
+		sampler.Unlock()
+		if numSeen != 4 || numSampled < 3 || sampleRatio != 0.5 {
+			t.Error(numSeen, numSampled, sampleRatio)
+		}
+		ends = append(ends, end)
+	}
+	for i := 0; i < 4; i++ {
+		sampler.computeSampled(0.0, start)
+	}
+
+	// the period is reported with its end, not when it rolled over
+	sampler.computeSampled(0.0, start.Add(90*time.Second))
+	sampler.computeSampled(0.0, start.Add(90*time.Second))
+	if len(ends) != 1 || !ends[0].Equal(start.Add(60*time.Second)) {
+		t.Error(ends)
+	}
+}
+
 func TestAdaptiveSamplerTarget(t *testing.T) {
 	var target uint64
 	target = 20
@@ -100,3 +127,57 @@ func TestAdaptiveSamplerTargetZero(t *testing.T) {
 		assert(t, !sampler.computeSampled(0.0, start))
 	}
 }
+
+func TestTargetSampler(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Now()
+	now := start
+	s := newTargetSampler(10, NewLogger(&buf))
+	s.now = func() time.Time { return now }
+
+	sampled := func(n int) (count int) {
+		for i := 0; i < n; i++ {
+			if s.IsSampled(SamplingRequest{}) {
+				count++
+			}
+		}
+		return
+	}
+	// everything is sampled up to the target in the first minute
+	if n := sampled(10); n != 10 {
+		t.Error(n)
+	}
+	if s.sampleRatio() != 1.0 {
+		t.Error(s.sampleRatio())
+	}
+
+	// 100 transactions in the first minute sample one in ten in the next
+	sampled(90)
+	now = now.Add(61 * time.Second)
+	sampled(1)
+	if r := s.sampleRatio(); r < 0.099 || r > 0.101 {
+		t.Error(r)
+	}
+	periodEnd := start.Add(time.Minute).Format(time.RFC3339)
+	if log := buf.String(); !strings.Contains(log, "adaptive sampling rate") ||
+		!strings.Contains(log, `"seen":100`) || !strings.Contains(log, `"period_end":"`+periodEnd+`"`) {
+		t.Error(log)
+	}
+
+	// low traffic samples everything again
+	now = now.Add(60 * time.Second)
+	sampled(1)
+	if s.sampleRatio() != 1.0 {
+		t.Error(s.sampleRatio())
+	}
+}
+
+func TestTargetSamplerFromConfig(t *testing.T) {
+	c := defaultConfig()
+	c.Sampling.Type = SamplingTypeAdaptive
+	c.Sampling.PerMinute = 30
+	s, ok := newSampler(c).(*targetSampler)
+	if !ok || s.target != 30 || s.period != time.Minute {
+		t.Error(s)
+	}
+}
@@ -2,38 +2,60 @@ package pinpoint
 
 import (
 	"os"
+	"runtime"
+	"sync"
 	"time"
 
 	"github.com/shirou/gopsutil/cpu"
-	"github.com/shirou/gopsutil/mem"
 	"github.com/shirou/gopsutil/process"
 
+	"github.com/dingyalin/pinpoint-go-agent/internal/sysinfo"
 	pinpoint "github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
 )
 
-func getTAgentStat(agentID string, startTime int64, collectInterval int64) *pinpoint.TAgentStat {
-	timestamp := time.Now().UnixNano() / 1e6
+// agentStatSources are the states sampled into the agent stats.
+type agentStatSources struct {
+	gc            *gcStats
+	container     *containerStats
+	txnCounts     *transactionCounts
+	active        *activeTransactions
+	responseTimes *responseTimes
+	dataSources   *dataSourceRegistry
+}
+
+func getTAgentStat(agentID string, startTime int64, collectInterval int64, sources agentStatSources) *pinpoint.TAgentStat {
+	now := time.Now()
+	timestamp := now.UnixNano() / 1e6
 
 	// process
-	appCPULoad, appMEMUsage := getProcessStat()
+	appCPULoad := getProcessCPULoad()
 
 	// cpu
-	sysCPULoad := getSysCPULoad()
+	sysCPULoad, ok := sources.container.cpuLoad(now)
+	if ok {
+		appCPULoad /= sources.container.cpus
+	} else {
+		sysCPULoad = getSysCPULoad()
+	}
 	cpuLoad := &pinpoint.TCpuLoad{
 		JvmCpuLoad:    &appCPULoad,
 		SystemCpuLoad: &sysCPULoad,
 	}
 
 	// mem
-	memUsed, memTotal := getMemStat()
-	gc := &pinpoint.TJvmGc{
-		Type:                 0,
-		JvmMemoryHeapUsed:    appMEMUsage,
-		JvmMemoryHeapMax:     memTotal,
-		JvmMemoryNonHeapUsed: memUsed,
-		JvmMemoryNonHeapMax:  memTotal,
-		JvmGcOldCount:        -1,
-		JvmGcOldTime:         -1,
+	gc := sources.gc.sample()
+	if used, limit, ok := sources.container.memory(); ok {
+		gc.JvmMemoryNonHeapUsed = used
+		gc.JvmMemoryNonHeapMax = limit
+	}
+
+	// active trace
+	schema := int32(activeThreadCountSchema)
+	activeTrace := &pinpoint.TActiveTrace{
+		Histogram: &pinpoint.TActiveTraceHistogram{
+			HistogramSchemaType: &schema,
+			ActiveTraceCount:    sources.active.histogram(now),
+		},
 	}
 
 	tagentStat := &pinpoint.TAgentStat{
@@ -43,8 +65,10 @@ func getTAgentStat(agentID string, startTime int64, collectInterval int64) *pinp
 		CollectInterval: &collectInterval,
 		Gc:              gc,
 		CpuLoad:         cpuLoad,
-		Transaction:     nil, // nil
-		ActiveTrace:     nil, // nil
+		Transaction:     sources.txnCounts.sample(),
+		ActiveTrace:     activeTrace,
+		DataSourceList:  sources.dataSources.sample(),
+		ResponseTime:    sources.responseTimes.sample(),
 		Metadata:        nil, // nil
 	}
 
@@ -62,33 +86,227 @@ func getSysCPULoad() (sysCPULoad float64) {
 	return
 }
 
-func getMemStat() (memUsed, memTotal int64) {
-	mem, err := mem.VirtualMemory()
+// containerStats samples the CPU and memory of the container the process
+// runs in.  The CPU loads are reported against the CPU quota of the
+// container, and in place of the non-heap memory its memory usage against
+// its limit, page cache included.  Outside containers, or in containers
+// without a quota or a limit, the host CPU load and the non-heap memory are
+// reported instead.  A nil containerStats reports the host metrics.
+type containerStats struct {
+	cgroup *sysinfo.Cgroup
+	// cpus is the CPU quota in CPUs, 0 without quota.
+	cpus        float64
+	memoryLimit int64
+	lastUsage   time.Duration
+	lastTime    time.Time
+}
+
+// newContainerStats detects the cgroup of the process.  It returns nil if
+// the process has none with a CPU quota or a memory limit.
+func newContainerStats() *containerStats {
+	cgroup, err := sysinfo.NewCgroup()
 	if err != nil {
-		return
+		return nil
 	}
+	return newCgroupStats(cgroup)
+}
 
-	memUsed = int64(mem.Used)
-	memTotal = int64(mem.Total)
-	return
+func newCgroupStats(cgroup *sysinfo.Cgroup) *containerStats {
+	s := &containerStats{cgroup: cgroup}
+	if cpus, err := cgroup.CPUQuota(); nil == err {
+		s.cpus = cpus
+	}
+	if limit, err := cgroup.MemoryLimit(); nil == err {
+		s.memoryLimit = limit
+	}
+	if 0 == s.cpus && 0 == s.memoryLimit {
+		return nil
+	}
+	return s
 }
 
-func getProcessStat() (appCPULoad float64, appMEMUsage int64) {
+// cpuLoad returns the CPU time used by the container since the previous
+// sample as a fraction of its quota, 0 for the first sample.  It returns
+// false if the container has no quota.
+func (s *containerStats) cpuLoad(now time.Time) (float64, bool) {
+	if nil == s || 0 == s.cpus {
+		return 0, false
+	}
+	usage, err := s.cgroup.CPUUsage()
+	if err != nil {
+		return 0, false
+	}
+	var load float64
+	if elapsed := now.Sub(s.lastTime); !s.lastTime.IsZero() && elapsed > 0 {
+		load = float64(usage-s.lastUsage) / (float64(elapsed) * s.cpus)
+	}
+	s.lastUsage = usage
+	s.lastTime = now
+	return load, true
+}
+
+// memory returns the memory usage of the container and its limit.  It
+// returns false if the container has no limit.
+func (s *containerStats) memory() (int64, int64, bool) {
+	if nil == s || 0 == s.memoryLimit {
+		return 0, 0, false
+	}
+	used, err := s.cgroup.MemoryUsage()
+	if err != nil {
+		return 0, 0, false
+	}
+	return used, s.memoryLimit, true
+}
+
+func getProcessCPULoad() (appCPULoad float64) {
 	proc, err := process.NewProcess(int32(os.Getpid()))
 	if err != nil {
 		return
 	}
 
-	// cpu
 	cpuPercent, err := proc.CPUPercent()
 	if err != nil {
 		return
 	}
 	appCPULoad = cpuPercent / float64(100)
+	return
+}
 
-	// mem
-	mem, err := proc.MemoryInfo()
-	appMEMUsage = int64(mem.RSS)
+// gcStats samples the memory statistics of the Go runtime into the JVM
+// memory and GC fields of the agent stats, which drive the heap and GC
+// charts of the inspector:
+//
+//	JvmMemoryHeapUsed     MemStats.HeapInuse, bytes in in-use heap spans
+//	JvmMemoryHeapMax      MemStats.HeapSys, heap bytes obtained from the OS
+//	JvmMemoryNonHeapUsed  stack, span, mcache, profiling bucket, GC metadata
+//	                      and other runtime bytes in use
+//	JvmMemoryNonHeapMax   MemStats.Sys minus MemStats.HeapSys
+//	JvmGcOldCount         MemStats.NumGC, GC cycles since the start; the
+//	                      web charts the difference between samples
+//	JvmGcOldTime          MemStats.PauseTotalNs in ms, likewise cumulative
+//
+// and into the detailed GC fields:
+//
+//	JvmGcNewCount      GC cycles since the previous sample
+//	JvmGcNewTime       GC stop-the-world pause in ms since the previous
+//	                   sample
+//	JvmPoolOldGenUsed  HeapInuse / HeapSys, the usage of the heap
+//
+// The collector charts the other memory pool fields as the usage of JVM
+// pools the Go runtime does not have, so they are left unset.  In a
+// container with a memory limit the non-heap fields are replaced, see
+// containerStats.  gcStats is not safe for concurrent use.
+type gcStats struct {
+	last runtime.MemStats
+}
 
-	return
+// sample reads the memory statistics and returns them with the GCs since the
+// previous sample, or since the start for the first one.
+func (s *gcStats) sample() *pinpoint.TJvmGc {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	gcCount := int64(m.NumGC - s.last.NumGC)
+	pauseTime := int64(m.PauseTotalNs-s.last.PauseTotalNs) / int64(time.Millisecond)
+	var heapUsage float64
+	if m.HeapSys > 0 {
+		heapUsage = float64(m.HeapInuse) / float64(m.HeapSys)
+	}
+
+	s.last = m
+
+	nonHeapUsed := m.StackInuse + m.MSpanInuse + m.MCacheInuse + m.BuckHashSys + m.GCSys + m.OtherSys
+	return &pinpoint.TJvmGc{
+		Type:                 pinpoint.TJvmGcType_UNKNOWN,
+		JvmMemoryHeapUsed:    int64(m.HeapInuse),
+		JvmMemoryHeapMax:     int64(m.HeapSys),
+		JvmMemoryNonHeapUsed: int64(nonHeapUsed),
+		JvmMemoryNonHeapMax:  int64(m.Sys - m.HeapSys),
+		JvmGcOldCount:        int64(m.NumGC),
+		JvmGcOldTime:         int64(m.PauseTotalNs) / int64(time.Millisecond),
+		JvmGcDetailed: &pinpoint.TJvmGcDetailed{
+			JvmGcNewCount:     &gcCount,
+			JvmGcNewTime:      &pauseTime,
+			JvmPoolOldGenUsed: &heapUsage,
+		},
+	}
+}
+
+// transactionCounts counts the transactions since the previous agent stat
+// sample for the TPS chart of the inspector.  A transaction is a
+// continuation when it was called with a Pinpoint-Traceid header.  Sampled
+// and unsampled transactions are both counted when they end, once the
+// inbound headers and the sampling decision are known.  The zero value is
+// ready to use.
+type transactionCounts struct {
+	sync.Mutex
+	sampledNew            int64
+	sampledContinuation   int64
+	unsampledNew          int64
+	unsampledContinuation int64
+}
+
+// count adds one transaction.
+func (c *transactionCounts) count(sampled bool, continuation bool) {
+	c.Lock()
+	defer c.Unlock()
+	switch {
+	case sampled && continuation:
+		c.sampledContinuation++
+	case sampled:
+		c.sampledNew++
+	case continuation:
+		c.unsampledContinuation++
+	default:
+		c.unsampledNew++
+	}
+}
+
+// sample returns the counts since the previous sample and resets them.
+func (c *transactionCounts) sample() *pinpoint.TTransaction {
+	c.Lock()
+	defer c.Unlock()
+	sampledNew, sampledContinuation := c.sampledNew, c.sampledContinuation
+	unsampledNew, unsampledContinuation := c.unsampledNew, c.unsampledContinuation
+	c.sampledNew, c.sampledContinuation, c.unsampledNew, c.unsampledContinuation = 0, 0, 0, 0
+	return &pinpoint.TTransaction{
+		SampledNewCount:            &sampledNew,
+		SampledContinuationCount:   &sampledContinuation,
+		UnsampledNewCount:          &unsampledNew,
+		UnsampledContinuationCount: &unsampledContinuation,
+	}
+}
+
+// responseTimes sums the response times of the transactions ended since the
+// previous agent stat sample.  The zero value is ready to use.
+type responseTimes struct {
+	sync.Mutex
+	count int64
+	total time.Duration
+	max   time.Duration
+}
+
+// add records the duration of an ended transaction.
+func (r *responseTimes) add(duration time.Duration) {
+	r.Lock()
+	defer r.Unlock()
+	r.count++
+	r.total += duration
+	if duration > r.max {
+		r.max = duration
+	}
+}
+
+// sample returns the average and maximum response times in ms since the
+// previous sample and resets them.  Both are 0 if no transaction ended.
+func (r *responseTimes) sample() *pinpoint.TResponseTime {
+	r.Lock()
+	defer r.Unlock()
+	res := &pinpoint.TResponseTime{}
+	if r.count > 0 {
+		res.Avg = int64(r.total/time.Duration(r.count)) / int64(time.Millisecond)
+		res.Max = int64(r.max / time.Millisecond)
+	}
+	r.count, r.total, r.max = 0, 0, 0
+	return res
 }
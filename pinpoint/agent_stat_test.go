@@ -1,9 +1,13 @@
 package pinpoint
 
 import (
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"testing"
+	"time"
 
+	"github.com/dingyalin/pinpoint-go-agent/internal/sysinfo"
 	pinpoint "github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
 )
 
@@ -22,9 +26,160 @@ func Test_getTAgentStat(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := getTAgentStat(tt.args.agentID, tt.args.startTime, tt.args.collectInterval); !reflect.DeepEqual(got, tt.want) {
+			if got := getTAgentStat(tt.args.agentID, tt.args.startTime, tt.args.collectInterval, agentStatSources{
+				gc:            &gcStats{},
+				txnCounts:     &transactionCounts{},
+				active:        &activeTransactions{},
+				responseTimes: &responseTimes{},
+				dataSources:   &dataSourceRegistry{},
+			}); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("getAgentStat() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestGCStatsSample(t *testing.T) {
+	var s gcStats
+	first := s.sample()
+	if first.JvmMemoryHeapUsed <= 0 || first.JvmMemoryHeapUsed > first.JvmMemoryHeapMax ||
+		first.JvmMemoryNonHeapUsed <= 0 || first.JvmMemoryNonHeapUsed > first.JvmMemoryNonHeapMax {
+		t.Error(first)
+	}
+
+	garbage := make([][]byte, 0, 100)
+	for i := 0; i < 100; i++ {
+		garbage = append(garbage, make([]byte, 1024))
+	}
+	runtime.GC()
+	runtime.GC()
+	second := s.sample()
+	detailed := second.JvmGcDetailed
+	if detailed.GetJvmGcNewCount() < 2 || second.JvmGcOldCount != first.JvmGcOldCount+detailed.GetJvmGcNewCount() {
+		t.Error(first.JvmGcOldCount, second.JvmGcOldCount, detailed.GetJvmGcNewCount())
+	}
+	if second.JvmGcOldTime < first.JvmGcOldTime {
+		t.Error(first.JvmGcOldTime, second.JvmGcOldTime)
+	}
+	if usage := detailed.GetJvmPoolOldGenUsed(); usage <= 0 || usage > 1 {
+		t.Error(usage)
+	}
+	// the pools the Go runtime does not have are left unset
+	if detailed.IsSetJvmPoolNewGenUsed() || detailed.IsSetJvmPoolCodeCacheUsed() || detailed.IsSetJvmPoolSurvivorSpaceUsed() ||
+		detailed.IsSetJvmPoolPermGenUsed() || detailed.IsSetJvmPoolMetaspaceUsed() {
+		t.Error(detailed)
+	}
+	runtime.KeepAlive(garbage)
+}
+
+func TestTransactionCounts(t *testing.T) {
+	var c transactionCounts
+	c.count(true, false)
+	c.count(true, false)
+	c.count(true, true)
+	c.count(false, false)
+	c.count(false, true)
+	c.count(false, true)
+	counts := c.sample()
+	if counts.GetSampledNewCount() != 2 || counts.GetSampledContinuationCount() != 1 ||
+		counts.GetUnsampledNewCount() != 1 || counts.GetUnsampledContinuationCount() != 2 {
+		t.Error(counts)
+	}
+	if counts := c.sample(); counts.GetSampledNewCount() != 0 || counts.GetUnsampledContinuationCount() != 0 {
+		t.Error(counts)
+	}
+}
+
+func TestResponseTimes(t *testing.T) {
+	var r responseTimes
+	if res := r.sample(); res.Avg != 0 || res.Max != 0 {
+		t.Error(res)
+	}
+	r.add(10 * time.Millisecond)
+	r.add(20 * time.Millisecond)
+	r.add(90 * time.Millisecond)
+	if res := r.sample(); res.Avg != 40 || res.Max != 90 {
+		t.Error(res)
+	}
+	if res := r.sample(); res.Avg != 0 || res.Max != 0 {
+		t.Error(res)
+	}
+}
+
+func TestAgentStatActiveTrace(t *testing.T) {
+	active := &activeTransactions{}
+	for _, elapsed := range []time.Duration{0, 2 * time.Second, time.Minute} {
+		txn := &txn{}
+		txn.Start = time.Now().Add(-elapsed)
+		active.add(txn)
+	}
+	var r responseTimes
+	r.add(5 * time.Millisecond)
+
+	stat := getTAgentStat("agent", 1, 5000, agentStatSources{
+		gc:            &gcStats{},
+		txnCounts:     &transactionCounts{},
+		active:        active,
+		responseTimes: &r,
+		dataSources:   &dataSourceRegistry{},
+	})
+	histogram := stat.GetActiveTrace().GetHistogram()
+	if histogram.GetHistogramSchemaType() != activeThreadCountSchema ||
+		!reflect.DeepEqual(histogram.ActiveTraceCount, []int32{1, 1, 0, 1}) {
+		t.Error(histogram)
+	}
+	if stat.GetResponseTime().Avg != 5 || stat.GetResponseTime().Max != 5 {
+		t.Error(stat.GetResponseTime())
+	}
+}
+
+func TestContainerStats(t *testing.T) {
+	cgroup, err := sysinfo.NewCgroupFrom(filepath.Join("..", "internal", "sysinfo", "testdata", "cgroup_v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newCgroupStats(cgroup)
+	if nil == s || s.cpus != 2 || s.memoryLimit != 1<<30 {
+		t.Fatal(s)
+	}
+	now := time.Now()
+	if load, ok := s.cpuLoad(now); !ok || load != 0 {
+		t.Error(load, ok)
+	}
+	// The fixture usage does not change, so pretend 1s of CPU time was
+	// used in the last second, half of the quota.
+	s.lastUsage -= time.Second
+	if load, ok := s.cpuLoad(now.Add(time.Second)); !ok || load != 0.5 {
+		t.Error(load, ok)
+	}
+	if used, limit, ok := s.memory(); !ok || used != 256<<20 || limit != 1<<30 {
+		t.Error(used, limit, ok)
+	}
+
+	stat := getTAgentStat("agent", 1, 5000, agentStatSources{
+		gc:            &gcStats{},
+		container:     s,
+		txnCounts:     &transactionCounts{},
+		active:        &activeTransactions{},
+		responseTimes: &responseTimes{},
+		dataSources:   &dataSourceRegistry{},
+	})
+	if gc := stat.GetGc(); gc.JvmMemoryNonHeapUsed != 256<<20 || gc.JvmMemoryNonHeapMax != 1<<30 {
+		t.Error(gc)
+	}
+
+	unlimited, err := sysinfo.NewCgroupFrom(filepath.Join("..", "internal", "sysinfo", "testdata", "cgroup_v2_unlimited"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newCgroupStats(unlimited); nil != s {
+		t.Error(s)
+	}
+	var host *containerStats
+	if _, ok := host.cpuLoad(now); ok {
+		t.Error("cpu load without container")
+	}
+	if _, _, ok := host.memory(); ok {
+		t.Error("memory without container")
+	}
+}
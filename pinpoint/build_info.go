@@ -0,0 +1,31 @@
+// +build go1.12
+
+package pinpoint
+
+import "runtime/debug"
+
+// readBuildInfo returns the main module of the running binary and the
+// modules it depends on.  ok is false if the binary was not built with
+// module support.
+func readBuildInfo() (main string, deps []string, ok bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", nil, false
+	}
+	deps = make([]string, 0, len(info.Deps))
+	for _, dep := range info.Deps {
+		deps = append(deps, moduleString(dep))
+	}
+	return moduleString(&info.Main), deps, true
+}
+
+func moduleString(m *debug.Module) string {
+	s := m.Path
+	if "" != m.Version {
+		s += "@" + m.Version
+	}
+	if nil != m.Replace {
+		s += " => " + moduleString(m.Replace)
+	}
+	return s
+}
@@ -0,0 +1,9 @@
+// +build !go1.12
+
+package pinpoint
+
+// readBuildInfo reports no build information since runtime/debug.ReadBuildInfo
+// requires Go 1.12.
+func readBuildInfo() (main string, deps []string, ok bool) {
+	return "", nil, false
+}
@@ -6,6 +6,7 @@ package pinpoint
 import (
 	"bytes"
 	"compress/gzip"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,11 +15,13 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	"git.apache.org/thrift.git/lib/go/thrift"
 	"github.com/dingyalin/pinpoint-go-agent/internal"
 	"github.com/dingyalin/pinpoint-go-agent/internal/logger"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
 	"github.com/dingyalin/pinpoint-go-agent/thrift/io"
 )
 
@@ -311,32 +314,723 @@ func connectAttempt(config config, cs rpmControls) (*internal.ConnectReply, rpmR
 	return reply, resp
 }
 
+// handshake property keys understood by the collector.
+const (
+	handshakeSocketID           = "socketId"
+	handshakeHostName           = "hostName"
+	handshakeIP                 = "ip"
+	handshakeAgentID            = "agentId"
+	handshakeApplicationName    = "applicationName"
+	handshakeServiceType        = "serviceType"
+	handshakePid                = "pid"
+	handshakeVersion            = "version"
+	handshakeStartTimestamp     = "startTimestamp"
+	handshakeSupportServer      = "supportServer"
+	handshakeSupportCommandList = "supportCommandList"
+)
+
+// handshake response codes sent by the collector.
+const (
+	handshakeCodeSuccess      = 0
+	handshakeCodeAlreadyKnown = 1
+)
+
+// socket state codes reported in ping packets.
+const (
+	socketStateVersion    uint8 = 0
+	socketStateRunSimplex uint8 = 11
+)
+
+const (
+	// tcpPingTimeoutFactor is the number of ping intervals without any
+	// packet from the collector after which the tcp connection is
+	// considered dead.
+	tcpPingTimeoutFactor = 3
+	// defaultTCPReconnectInterval is how often a lost tcp connection is
+	// re-established when the pings are disabled.
+	defaultTCPReconnectInterval = time.Minute
+)
+
+var (
+	errTCPReconnectBackoff = errors.New("tcp reconnect backoff")
+	errTCPHandshakeTimeout = errors.New("tcp handshake response not received")
+	errTCPRequestTimeout   = errors.New("tcp request timed out")
+	errTCPConnClosed       = errors.New("tcp connection closed")
+)
+
+// tcpRejectedError is returned for a request the collector answered with an
+// unsuccessful TResult.
+type tcpRejectedError struct {
+	message string
+}
+
+func (e tcpRejectedError) Error() string {
+	return "tcp request rejected: " + e.message
+}
+
+// tcpReconnectBackoff returns the time to wait before the next tcp connect
+// after attempt consecutive failures.
+func tcpReconnectBackoff(attempt int) time.Duration {
+	backoffTimes := [...]time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		16 * time.Second,
+		30 * time.Second,
+		60 * time.Second,
+	}
+	l := len(backoffTimes)
+	if (attempt < 0) || (attempt >= l) {
+		return backoffTimes[l-1]
+	}
+	return backoffTimes[attempt]
+}
+
+// collectorClient is the transport between the agent and the pinpoint
+// collector.  PinpointClient speaks the thrift protocol and grpcClient the
+// gRPC protocol of Pinpoint 2.x collectors.
+type collectorClient interface {
+	// RequestTStruct sends tstruct and waits until the collector has
+	// acknowledged it.
+	RequestTStruct(ttype uint16, tstruct thrift.TStruct) error
+	SendSpan(tstruct thrift.TStruct) error
+	SendSpanChunk(tstruct thrift.TStruct) error
+	SendAgentStat(tstruct thrift.TStruct) error
+	SendAgentStatBatch(tstruct thrift.TStruct) error
+	// keepAlive keeps the connection to the collector alive until done is
+	// closed.
+	keepAlive(done <-chan struct{})
+}
+
 // PinpointClient ...
 type PinpointClient struct {
-	tcpAddress     string
-	statAddress    string
-	spanAddress    string
-	uploaded       bool
-	tcpConnTimeout time.Duration
-	tcpConn        net.Conn
-	statConn       net.Conn
-	spanConn       net.Conn
-	messageID      uint32
-	logger         Logger
-}
-
-// ConnTCP tcp conn
+	tcpAddress      string
+	statAddress     string
+	spanAddress     string
+	uploaded        bool
+	tcpConnTimeout  time.Duration
+	tcpPingInterval time.Duration
+	// tcpReconnectInterval replaces tcpPingInterval when the pings are
+	// disabled, defaultTCPReconnectInterval if zero.
+	tcpReconnectInterval time.Duration
+	// tcpRequestTimeout bounds the wait for the response of a request and
+	// tcpRequestRetries is the number of times a failed request is sent
+	// again.
+	tcpRequestTimeout time.Duration
+	tcpRequestRetries int
+	// handshakeProperties is sent in the control handshake of every new tcp
+	// connection.
+	handshakeProperties map[string]interface{}
+	// reconnectHandler is called after a lost tcp connection has been
+	// established again.
+	reconnectHandler func()
+
+	// endpoints, if set, are the collector addresses the tcp connection
+	// fails over between.  The tcp, stat and span addresses then follow
+	// the one in use, on tcpPort, statPort and spanPort.  With
+	// stickyPrimary the primary address is used again as soon as it
+	// accepts connections.
+	endpoints     *collectorEndpoints
+	stickyPrimary bool
+	tcpPort       int
+	statPort      int
+	spanPort      int
+	// tlsConfig, if set, secures the tcp connection.
+	tlsConfig *tls.Config
+	// spool, if set, keeps the metadata requests and spans which cannot
+	// be sent until the collector is reachable again.
+	spool *diskSpool
+	// commands, if set, answers the commands the collector sends.
+	commands *commandDispatcher
+
+	// dialMu serializes the attempts to connect the tcp connection.  The
+	// collector hosts are resolved and dialed under dialMu only, so that a
+	// slow resolver or collector does not hold up the current connection.
+	dialMu sync.Mutex
+	// tcpMu protects the tcp connection and its reconnect state.
+	tcpMu        sync.Mutex
+	tcpConn      net.Conn
+	tcpConnected bool
+	tcpAttempts  int
+	tcpRetryAt   time.Time
+	tcpLastRead  time.Time
+	// tcpClosed is set once the agent has closed the connection for good.
+	tcpClosed bool
+	socketID  int32
+	pingID    uint32
+	messageID uint32
+	// pending maps the message ids of requests waiting for a response to
+	// the channels their responses are delivered on.
+	pending map[uint32]chan *io.Packet
+	// streams maps the ids of the open command streams to the channels
+	// closed to end them.
+	streams map[uint32]chan struct{}
+
+	// udpMu protects the stat and span connections.
+	udpMu    sync.Mutex
+	statConn net.Conn
+	spanConn net.Conn
+	logger   Logger
+}
+
+// ConnTCP connects the tcp connection unless it is connected.
 func (pinpointClient *PinpointClient) ConnTCP() error {
-	conn, err := net.DialTimeout("tcp", pinpointClient.tcpAddress, pinpointClient.tcpConnTimeout)
+	// The current connection is used without waiting for dialMu, which
+	// checkEndpoint holds while it resolves the collector hosts.
+	if pinpointClient.isTCPConnected() {
+		return nil
+	}
+
+	pinpointClient.dialMu.Lock()
+	defer pinpointClient.dialMu.Unlock()
+
+	pinpointClient.tcpMu.Lock()
+	connected, closed := pinpointClient.tcpConn != nil, pinpointClient.tcpClosed
+	retryAt := pinpointClient.tcpRetryAt
+	pinpointClient.tcpMu.Unlock()
+
+	if closed {
+		return errTCPConnClosed
+	}
+	if connected {
+		return nil
+	}
+	now := time.Now()
+	if now.Before(retryAt) {
+		return errTCPReconnectBackoff
+	}
+
+	conn, host, err := pinpointClient.dialCollector()
+
+	pinpointClient.tcpMu.Lock()
+	defer pinpointClient.tcpMu.Unlock()
 	if err != nil {
-		pinpointClient.tcpConn = nil
+		pinpointClient.connTCPFailedLocked(now)
 		return err
 	}
+	if pinpointClient.tcpClosed {
+		conn.Close()
+		return errTCPConnClosed
+	}
+	if "" != host {
+		pinpointClient.useHostLocked(host)
+	}
+	pinpointClient.useTCPConnLocked(conn)
+	return nil
+}
+
+// dialCollector dials the collector and runs the control handshake, failing
+// over to the next collector address when either fails.  It returns the
+// address connected to when the client has endpoints.
+func (pinpointClient *PinpointClient) dialCollector() (net.Conn, string, error) {
+	if nil == pinpointClient.endpoints {
+		conn, err := pinpointClient.dialTCP(pinpointClient.tcpAddress)
+		return conn, "", err
+	}
+
+	var err error
+	for _, host := range pinpointClient.endpoints.candidates(pinpointClient.stickyPrimary) {
+		var conn net.Conn
+		conn, err = pinpointClient.dialTCP(net.JoinHostPort(host, strconv.Itoa(pinpointClient.tcpPort)))
+		if err != nil {
+			pinpointClient.logger.Warn("collector unavailable", map[string]interface{}{
+				"host": host,
+				"err":  err.Error(),
+			})
+			continue
+		}
+		return conn, host, nil
+	}
+	if nil == err {
+		err = errors.New("no collector address")
+	}
+	return nil, "", err
+}
+
+// dialTCP connects to address, over TLS if the client has a tlsConfig, and
+// runs the control handshake.  It must not be called with tcpMu held.
+func (pinpointClient *PinpointClient) dialTCP(address string) (net.Conn, error) {
+	var conn net.Conn
+	var err error
+	if nil != pinpointClient.tlsConfig {
+		host, _, _ := net.SplitHostPort(address)
+		tlsConfig := pinpointClient.tlsConfig.Clone()
+		tlsConfig.ServerName = collectorServerName(pinpointClient.tlsConfig, pinpointClient.endpoints, host)
+		dialer := &net.Dialer{Timeout: pinpointClient.tcpConnTimeout}
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", address, pinpointClient.tcpConnTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = pinpointClient.handshake(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (pinpointClient *PinpointClient) connTCPFailedLocked(now time.Time) {
+	pinpointClient.tcpConn = nil
+	pinpointClient.tcpRetryAt = now.Add(tcpReconnectBackoff(pinpointClient.tcpAttempts))
+	pinpointClient.tcpAttempts++
+}
 
+// useHostLocked points the tcp, stat and span addresses at host.
+func (pinpointClient *PinpointClient) useHostLocked(host string) {
+	pinpointClient.endpoints.setCurrent(host)
+	pinpointClient.tcpAddress = net.JoinHostPort(host, strconv.Itoa(pinpointClient.tcpPort))
+
+	statAddress := net.JoinHostPort(host, strconv.Itoa(pinpointClient.statPort))
+	spanAddress := net.JoinHostPort(host, strconv.Itoa(pinpointClient.spanPort))
+
+	pinpointClient.udpMu.Lock()
+	defer pinpointClient.udpMu.Unlock()
+	if pinpointClient.statAddress != statAddress {
+		pinpointClient.statAddress = statAddress
+		if nil != pinpointClient.statConn {
+			pinpointClient.statConn.Close()
+			pinpointClient.statConn = nil
+		}
+	}
+	if pinpointClient.spanAddress != spanAddress {
+		pinpointClient.spanAddress = spanAddress
+		if nil != pinpointClient.spanConn {
+			pinpointClient.spanConn.Close()
+			pinpointClient.spanConn = nil
+		}
+	}
+}
+
+// useTCPConnLocked makes conn, which has completed the handshake, the
+// current connection.
+func (pinpointClient *PinpointClient) useTCPConnLocked(conn net.Conn) {
+	reconnected := pinpointClient.tcpConnected
 	pinpointClient.tcpConn = conn
+	pinpointClient.tcpConnected = true
+	pinpointClient.tcpAttempts = 0
+	pinpointClient.tcpRetryAt = time.Time{}
+	pinpointClient.tcpLastRead = time.Now()
+	go pinpointClient.readTCP(conn)
+
+	pinpointClient.logger.Info("tcp connected", map[string]interface{}{
+		"address":  pinpointClient.tcpAddress,
+		"socketId": pinpointClient.socketID,
+	})
+	if reconnected && nil != pinpointClient.reconnectHandler {
+		go pinpointClient.reconnectHandler()
+	}
+}
+
+// handshake sends the control handshake and waits for its response.
+func (pinpointClient *PinpointClient) handshake(conn net.Conn) error {
+	properties := make(map[string]interface{}, len(pinpointClient.handshakeProperties)+1)
+	for key, val := range pinpointClient.handshakeProperties {
+		properties[key] = val
+	}
+	pinpointClient.tcpMu.Lock()
+	pinpointClient.socketID++
+	properties[handshakeSocketID] = pinpointClient.socketID
+	pinpointClient.messageID++
+	messageID := pinpointClient.messageID
+	pinpointClient.tcpMu.Unlock()
+
+	data, err := io.EncodeControlHandshake(messageID, properties)
+	if err != nil {
+		return err
+	}
+
+	conn.SetDeadline(time.Now().Add(pinpointClient.tcpConnTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	_, err = conn.Write(data)
+	if err != nil {
+		return err
+	}
+
+	for {
+		packet, err := io.ReadPacket(conn)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				return errTCPHandshakeTimeout
+			}
+			return err
+		}
+
+		switch packet.Type {
+		case io.RequestTypeControlHandshakeResponse:
+			return parseHandshakeResponse(packet.Payload)
+		case io.RequestTypeControlPing, io.RequestTypeControlPingSimple, io.RequestTypeControlPingPayload:
+			_, err = conn.Write(io.EncodePong())
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func parseHandshakeResponse(payload []byte) error {
+	message, err := io.DecodeControlMessage(payload)
+	if err != nil {
+		return err
+	}
+	response, ok := message.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid handshake response: %v", message)
+	}
+	code, _ := response["code"].(int32)
+	subCode, _ := response["subCode"].(int32)
+	if code != handshakeCodeSuccess && code != handshakeCodeAlreadyKnown {
+		return fmt.Errorf("handshake rejected: code=%d subCode=%d", code, subCode)
+	}
 	return nil
 }
 
+// readTCP reads packets from conn until it fails or is closed.
+func (pinpointClient *PinpointClient) readTCP(conn net.Conn) {
+	for {
+		packet, err := io.ReadPacket(conn)
+		if err != nil {
+			pinpointClient.logger.Debug("tcp read failed", map[string]interface{}{
+				"err": err.Error(),
+			})
+			pinpointClient.closeTCPConn(conn)
+			return
+		}
+
+		pinpointClient.tcpMu.Lock()
+		if pinpointClient.tcpConn == conn {
+			pinpointClient.tcpLastRead = time.Now()
+		}
+		pinpointClient.tcpMu.Unlock()
+
+		pinpointClient.handlePacket(conn, packet)
+	}
+}
+
+func (pinpointClient *PinpointClient) handlePacket(conn net.Conn, packet *io.Packet) {
+	switch packet.Type {
+	case io.RequestTypeControlPing, io.RequestTypeControlPingSimple, io.RequestTypeControlPingPayload:
+		pinpointClient.writeTCPConn(conn, io.EncodePong())
+	case io.RequestTypeControlPong:
+	case io.RequestTypeAppResponse:
+		pinpointClient.tcpMu.Lock()
+		responseChan := pinpointClient.pending[packet.RequestID]
+		delete(pinpointClient.pending, packet.RequestID)
+		pinpointClient.tcpMu.Unlock()
+
+		if nil != responseChan {
+			responseChan <- packet
+		} else {
+			pinpointClient.logger.Debug("unexpected tcp response", map[string]interface{}{
+				"messageId": packet.RequestID,
+			})
+		}
+	case io.RequestTypeAppRequest:
+		go pinpointClient.handleCommand(conn, packet)
+	case io.RequestTypeAppStreamCreate:
+		pinpointClient.openStream(conn, packet)
+	case io.RequestTypeAppStreamClose:
+		pinpointClient.tcpMu.Lock()
+		pinpointClient.closeStreamLocked(packet.StreamID)
+		pinpointClient.tcpMu.Unlock()
+	case io.RequestTypeAppStreamPing:
+		pinpointClient.writeTCPConn(conn, io.EncodeStreamPong(packet.StreamID, packet.RequestID))
+	case io.RequestTypeControlServerClose:
+		pinpointClient.logger.Info("tcp closed by collector", map[string]interface{}{
+			"address": conn.RemoteAddr().String(),
+		})
+		pinpointClient.closeTCPConn(conn)
+	default:
+		pinpointClient.logger.Debug("unhandled tcp packet", map[string]interface{}{
+			"type": packet.Type,
+		})
+	}
+}
+
+// handleCommand answers the command request in packet.
+func (pinpointClient *PinpointClient) handleCommand(conn net.Conn, packet *io.Packet) {
+	if nil == pinpointClient.commands {
+		return
+	}
+	ttype, response := pinpointClient.commands.handleRequest(packet.Payload)
+	data, err := io.EncodeTCPTStructResponse(packet.RequestID, ttype, response)
+	if err == nil {
+		err = pinpointClient.writeTCPConn(conn, data)
+	}
+	if err != nil {
+		pinpointClient.logger.Debug("command response failed", map[string]interface{}{
+			"messageId": packet.RequestID,
+			"err":       err.Error(),
+		})
+	}
+}
+
+// openStream accepts a command stream if the command in packet can be
+// streamed, and sends its responses until the stream or the connection is
+// closed.
+func (pinpointClient *PinpointClient) openStream(conn net.Conn, packet *io.Packet) {
+	streamID := packet.StreamID
+	if nil == pinpointClient.commands {
+		pinpointClient.writeTCPConn(conn, io.EncodeStreamCreateFail(streamID, io.StreamCodeTypeUnsupport))
+		return
+	}
+	next, interval := pinpointClient.commands.handleStream(packet.Payload)
+	if nil == next {
+		pinpointClient.writeTCPConn(conn, io.EncodeStreamCreateFail(streamID, io.StreamCodeTypeUnsupport))
+		return
+	}
+
+	pinpointClient.tcpMu.Lock()
+	if pinpointClient.tcpConn != conn {
+		pinpointClient.tcpMu.Unlock()
+		return
+	}
+	pinpointClient.closeStreamLocked(streamID)
+	if nil == pinpointClient.streams {
+		pinpointClient.streams = make(map[uint32]chan struct{})
+	}
+	done := make(chan struct{})
+	pinpointClient.streams[streamID] = done
+	err := pinpointClient.writeTCPLocked(io.EncodeStreamCreateSuccess(streamID))
+	pinpointClient.tcpMu.Unlock()
+	if err != nil {
+		return
+	}
+
+	go pinpointClient.stream(conn, streamID, next, interval, done)
+}
+
+func (pinpointClient *PinpointClient) stream(conn net.Conn, streamID uint32,
+	next func() (uint16, thrift.TStruct), interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ttype, response := next()
+		payload, err := io.EncodeTstruct(ttype, response)
+		if err == nil {
+			var data []byte
+			if data, err = io.EncodeStreamResponse(streamID, payload); err == nil {
+				err = pinpointClient.writeTCPConn(conn, data)
+			}
+		}
+		if err != nil {
+			pinpointClient.logger.Debug("command stream failed", map[string]interface{}{
+				"streamId": streamID,
+				"err":      err.Error(),
+			})
+			pinpointClient.tcpMu.Lock()
+			pinpointClient.closeStreamLocked(streamID)
+			pinpointClient.tcpMu.Unlock()
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
+
+// closeStreamLocked ends the stream streamID if it is open.
+func (pinpointClient *PinpointClient) closeStreamLocked(streamID uint32) {
+	if done, ok := pinpointClient.streams[streamID]; ok {
+		close(done)
+		delete(pinpointClient.streams, streamID)
+	}
+}
+
+// writeTCPConn writes data if conn is still the current connection.
+func (pinpointClient *PinpointClient) writeTCPConn(conn net.Conn, data []byte) error {
+	pinpointClient.tcpMu.Lock()
+	defer pinpointClient.tcpMu.Unlock()
+
+	if pinpointClient.tcpConn != conn {
+		return errTCPConnClosed
+	}
+	return pinpointClient.writeTCPLocked(data)
+}
+
+func (pinpointClient *PinpointClient) writeTCPLocked(data []byte) error {
+	conn := pinpointClient.tcpConn
+	conn.SetWriteDeadline(time.Now().Add(pinpointClient.tcpConnTimeout))
+	_, err := conn.Write(data)
+	if err != nil {
+		pinpointClient.dropTCPConnLocked()
+		return err
+	}
+	return nil
+}
+
+// dropTCPConnLocked closes the current connection, fails every request
+// waiting for a response on it and ends its command streams.
+func (pinpointClient *PinpointClient) dropTCPConnLocked() {
+	if pinpointClient.tcpConn != nil {
+		pinpointClient.tcpConn.Close()
+		pinpointClient.tcpConn = nil
+	}
+	for messageID, responseChan := range pinpointClient.pending {
+		close(responseChan)
+		delete(pinpointClient.pending, messageID)
+	}
+	for streamID := range pinpointClient.streams {
+		pinpointClient.closeStreamLocked(streamID)
+	}
+}
+
+// closeTCPConn closes conn and forgets it if it is the current connection.
+func (pinpointClient *PinpointClient) closeTCPConn(conn net.Conn) {
+	pinpointClient.tcpMu.Lock()
+	if pinpointClient.tcpConn == conn {
+		pinpointClient.dropTCPConnLocked()
+	}
+	pinpointClient.tcpMu.Unlock()
+	conn.Close()
+}
+
+// keepAlive pings the collector every tcpPingInterval and reconnects the tcp
+// connection when it has been lost, replaying the spool once connected.  When
+// the pings are disabled the connection is only re-established, every
+// tcpReconnectInterval.  It closes the connections when done is closed.
+func (pinpointClient *PinpointClient) keepAlive(done <-chan struct{}) {
+	defer pinpointClient.close()
+
+	if !pinpointClient.uploaded {
+		<-done
+		return
+	}
+
+	check := pinpointClient.ping
+	interval := pinpointClient.tcpPingInterval
+	if interval <= 0 {
+		check = pinpointClient.ConnTCP
+		interval = pinpointClient.tcpReconnectInterval
+		if interval <= 0 {
+			interval = defaultTCPReconnectInterval
+		}
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := check()
+			if err != nil && err != errTCPReconnectBackoff {
+				pinpointClient.logger.Debug("tcp keepalive failed", map[string]interface{}{
+					"err": err.Error(),
+				})
+			}
+			if err == nil {
+				pinpointClient.replaySpool()
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// close tells the collector the agent is shutting down, closes the tcp
+// connection for good and closes the stat and span connections.
+func (pinpointClient *PinpointClient) close() {
+	pinpointClient.tcpMu.Lock()
+	if pinpointClient.tcpConn != nil {
+		if err := pinpointClient.writeTCPLocked(io.EncodeControlClientClose()); err != nil {
+			pinpointClient.logger.Debug("tcp close failed", map[string]interface{}{
+				"err": err.Error(),
+			})
+		}
+		pinpointClient.dropTCPConnLocked()
+	}
+	pinpointClient.tcpClosed = true
+	pinpointClient.tcpMu.Unlock()
+
+	pinpointClient.udpMu.Lock()
+	if pinpointClient.statConn != nil {
+		pinpointClient.statConn.Close()
+		pinpointClient.statConn = nil
+	}
+	if pinpointClient.spanConn != nil {
+		pinpointClient.spanConn.Close()
+		pinpointClient.spanConn = nil
+	}
+	pinpointClient.udpMu.Unlock()
+}
+
+func (pinpointClient *PinpointClient) ping() error {
+	if !pinpointClient.isTCPConnected() {
+		return pinpointClient.ConnTCP()
+	}
+	if nil != pinpointClient.endpoints {
+		pinpointClient.checkEndpoint()
+		if !pinpointClient.isTCPConnected() {
+			return pinpointClient.ConnTCP()
+		}
+	}
+
+	pinpointClient.tcpMu.Lock()
+	defer pinpointClient.tcpMu.Unlock()
+	if pinpointClient.tcpConn == nil {
+		return errTCPConnClosed
+	}
+	if time.Since(pinpointClient.tcpLastRead) > tcpPingTimeoutFactor*pinpointClient.tcpPingInterval {
+		pinpointClient.dropTCPConnLocked()
+		return errors.New("tcp ping timeout")
+	}
+
+	pinpointClient.pingID++
+	return pinpointClient.writeTCPLocked(io.EncodePingPayload(pinpointClient.pingID, socketStateVersion, socketStateRunSimplex))
+}
+
+// checkEndpoint drops the connection if its address is no longer resolved
+// from the collector hosts, and moves it back to the primary address if
+// stickyPrimary is set and the primary accepts connections.  The hosts are
+// resolved and the primary dialed without holding tcpMu.
+func (pinpointClient *PinpointClient) checkEndpoint() {
+	pinpointClient.dialMu.Lock()
+	defer pinpointClient.dialMu.Unlock()
+
+	addrs := pinpointClient.endpoints.addresses()
+	current := pinpointClient.endpoints.getCurrent()
+	if !containsString(addrs, current) {
+		pinpointClient.logger.Info("collector address no longer resolved", map[string]interface{}{
+			"host": current,
+		})
+		pinpointClient.tcpMu.Lock()
+		pinpointClient.dropTCPConnLocked()
+		pinpointClient.tcpMu.Unlock()
+		return
+	}
+	if !pinpointClient.stickyPrimary || len(addrs) == 0 || addrs[0] == current {
+		return
+	}
+
+	primary := addrs[0]
+	conn, err := pinpointClient.dialTCP(net.JoinHostPort(primary, strconv.Itoa(pinpointClient.tcpPort)))
+	if err != nil {
+		pinpointClient.logger.Debug("primary collector unavailable", map[string]interface{}{
+			"host": primary,
+			"err":  err.Error(),
+		})
+		return
+	}
+
+	pinpointClient.tcpMu.Lock()
+	defer pinpointClient.tcpMu.Unlock()
+	if pinpointClient.tcpClosed {
+		conn.Close()
+		return
+	}
+	pinpointClient.dropTCPConnLocked()
+	pinpointClient.useHostLocked(primary)
+	pinpointClient.useTCPConnLocked(conn)
+}
+
 // ConnStat udp stat conn
 func (pinpointClient *PinpointClient) ConnStat() error {
 	conn, err := net.Dial("udp", pinpointClient.statAddress)
@@ -367,20 +1061,16 @@ func (pinpointClient *PinpointClient) WriteTCPData(data []byte) error {
 		return nil
 	}
 
-	if pinpointClient.tcpConn == nil {
-		err := pinpointClient.ConnTCP()
-		if err != nil {
-			return err
-		}
-	}
-
-	_, err := pinpointClient.tcpConn.Write(data)
-	if err != nil {
-		pinpointClient.tcpConn = nil
+	if err := pinpointClient.ConnTCP(); err != nil {
 		return err
 	}
 
-	return nil
+	pinpointClient.tcpMu.Lock()
+	defer pinpointClient.tcpMu.Unlock()
+	if pinpointClient.tcpConn == nil {
+		return errTCPConnClosed
+	}
+	return pinpointClient.writeTCPLocked(data)
 }
 
 // WriteStatData ...
@@ -389,6 +1079,9 @@ func (pinpointClient *PinpointClient) WriteStatData(data []byte) error {
 		return nil
 	}
 
+	pinpointClient.udpMu.Lock()
+	defer pinpointClient.udpMu.Unlock()
+
 	if pinpointClient.statConn == nil {
 		err := pinpointClient.ConnStat()
 		if err != nil {
@@ -411,6 +1104,9 @@ func (pinpointClient *PinpointClient) WriteSpanData(data []byte) error {
 		return nil
 	}
 
+	pinpointClient.udpMu.Lock()
+	defer pinpointClient.udpMu.Unlock()
+
 	if pinpointClient.spanConn == nil {
 		err := pinpointClient.ConnSpan()
 		if err != nil {
@@ -427,22 +1123,180 @@ func (pinpointClient *PinpointClient) WriteSpanData(data []byte) error {
 	return nil
 }
 
-// SendTCPTStruct ...
-func (pinpointClient *PinpointClient) SendTCPTStruct(ttype uint16, tstruct thrift.TStruct) error {
+// writeSpanPacket sends a span or span chunk packet.  With a spool, the
+// packet is spooled instead if it cannot be sent, if the tcp connection to
+// the collector is down, or if spooled metadata it may refer to has not been
+// replayed yet.
+func (pinpointClient *PinpointClient) writeSpanPacket(data []byte) error {
+	if !pinpointClient.uploaded || nil == pinpointClient.spool {
+		return pinpointClient.WriteSpanData(data)
+	}
+	if pinpointClient.spool.hasMeta() || !pinpointClient.isTCPConnected() {
+		return pinpointClient.spoolPacket(spoolSpan, data)
+	}
+	if err := pinpointClient.WriteSpanData(data); err != nil {
+		return pinpointClient.spoolPacket(spoolSpan, data)
+	}
+	return nil
+}
+
+func (pinpointClient *PinpointClient) isTCPConnected() bool {
+	pinpointClient.tcpMu.Lock()
+	defer pinpointClient.tcpMu.Unlock()
+	return pinpointClient.tcpConn != nil
+}
+
+func (pinpointClient *PinpointClient) spoolPacket(kind spoolKind, data []byte) error {
+	if err := pinpointClient.spool.put(kind, data); err != nil {
+		return err
+	}
+	pinpointClient.logger.Debug("packet spooled", map[string]interface{}{
+		"kind": kind,
+		"size": len(data),
+	})
+	return nil
+}
+
+// replaySpool sends the spooled packets, metadata first.  It stops at the
+// first packet which cannot be sent, to be continued on the next call.
+func (pinpointClient *PinpointClient) replaySpool() {
+	if nil == pinpointClient.spool || pinpointClient.spool.len() == 0 {
+		return
+	}
+
+	sent, err := pinpointClient.spool.replay(pinpointClient.sendSpooled)
+	fields := map[string]interface{}{
+		"sent":    sent,
+		"pending": pinpointClient.spool.len(),
+	}
+	if err != nil {
+		fields["err"] = err.Error()
+		pinpointClient.logger.Warn("spool replay interrupted", fields)
+		return
+	}
+	pinpointClient.logger.Info("spool replayed", fields)
+}
+
+func (pinpointClient *PinpointClient) sendSpooled(kind spoolKind, data []byte) error {
+	if kind == spoolSpan {
+		return pinpointClient.WriteSpanData(data)
+	}
+	err := pinpointClient.requestTCPData(data)
+	if _, rejected := err.(tcpRejectedError); rejected {
+		// Sending it again would be rejected as well.
+		pinpointClient.logger.Warn("spooled metadata rejected", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return nil
+	}
+	return err
+}
+
+// RequestTCPTStruct sends tstruct as a request and waits for the collector
+// to acknowledge it with a successful TResult.  Requests which time out, fail
+// or are rejected are sent again up to tcpRequestRetries times.  With a
+// spool, metadata requests which cannot reach the collector are spooled.
+func (pinpointClient *PinpointClient) RequestTCPTStruct(ttype uint16, tstruct thrift.TStruct) error {
+	if !pinpointClient.uploaded {
+		return nil
+	}
+
+	tstructData, err := io.EncodeTstruct(ttype, tstruct)
+	if err != nil {
+		return err
+	}
+	err = pinpointClient.requestTCPData(tstructData)
+	if err == nil || nil == pinpointClient.spool || ttype == io.TTypeAgentInfo {
+		// The agent info is sent again on every reconnect.
+		return err
+	}
+	if _, rejected := err.(tcpRejectedError); rejected {
+		return err
+	}
+	return pinpointClient.spoolPacket(spoolMeta, tstructData)
+}
+
+// RequestTStruct implements collectorClient.
+func (pinpointClient *PinpointClient) RequestTStruct(ttype uint16, tstruct thrift.TStruct) error {
+	return pinpointClient.RequestTCPTStruct(ttype, tstruct)
+}
+
+// requestTCPData sends tstructData, the output of io.EncodeTstruct, as a
+// request up to tcpRequestRetries+1 times until it is acknowledged.
+func (pinpointClient *PinpointClient) requestTCPData(tstructData []byte) error {
+	var err error
+	for attempt := 0; attempt <= pinpointClient.tcpRequestRetries; attempt++ {
+		err = pinpointClient.requestTCPDataOnce(tstructData, pinpointClient.tcpRequestTimeout)
+		if err == nil {
+			return nil
+		}
+		pinpointClient.logger.Debug("tcp request failed", map[string]interface{}{
+			"attempt": attempt,
+			"err":     err.Error(),
+		})
+	}
+	return err
+}
+
+func (pinpointClient *PinpointClient) requestTCPDataOnce(tstructData []byte, timeout time.Duration) error {
+	if err := pinpointClient.ConnTCP(); err != nil {
+		return err
+	}
+
+	pinpointClient.tcpMu.Lock()
+	if pinpointClient.tcpConn == nil {
+		pinpointClient.tcpMu.Unlock()
+		return errTCPConnClosed
+	}
+
 	pinpointClient.messageID++
 	messageID := pinpointClient.messageID
-	data, err := io.EncodeTCPTTstuctRequest(messageID, ttype, tstruct)
+	data, err := io.EncodeTCPRequest(messageID, tstructData)
 	if err != nil {
+		pinpointClient.tcpMu.Unlock()
 		return err
 	}
-	err = pinpointClient.WriteTCPData(data)
+
+	responseChan := make(chan *io.Packet, 1)
+	if pinpointClient.pending == nil {
+		pinpointClient.pending = make(map[uint32]chan *io.Packet)
+	}
+	pinpointClient.pending[messageID] = responseChan
+	err = pinpointClient.writeTCPLocked(data)
+	pinpointClient.tcpMu.Unlock()
 	if err != nil {
 		return err
 	}
 
-	pinpointClient.logger.Debug("tcp tstruct data", map[string]interface{}{
-		"tstruct": fmt.Sprintf("% x", data),
-	})
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case packet, ok := <-responseChan:
+		if !ok {
+			return errTCPConnClosed
+		}
+		return parseTResult(packet.Payload)
+	case <-timer.C:
+		pinpointClient.tcpMu.Lock()
+		delete(pinpointClient.pending, messageID)
+		pinpointClient.tcpMu.Unlock()
+		return errTCPRequestTimeout
+	}
+}
+
+func parseTResult(payload []byte) error {
+	tstruct, err := io.DecodeTstruct(payload)
+	if err != nil {
+		return err
+	}
+	result, ok := tstruct.(*trace.TResult_)
+	if !ok {
+		return fmt.Errorf("unexpected tcp response %T", tstruct)
+	}
+	if !result.Success {
+		return tcpRejectedError{message: result.GetMessage()}
+	}
 	return nil
 }
 
@@ -453,7 +1307,7 @@ func (pinpointClient *PinpointClient) SendSpan(tstruct thrift.TStruct) error {
 		return err
 	}
 
-	err = pinpointClient.WriteSpanData(data)
+	err = pinpointClient.writeSpanPacket(data)
 	if err != nil {
 		return err
 	}
@@ -471,7 +1325,7 @@ func (pinpointClient *PinpointClient) SendSpanChunk(tstruct thrift.TStruct) erro
 		return err
 	}
 
-	err = pinpointClient.WriteSpanData(data)
+	err = pinpointClient.writeSpanPacket(data)
 	if err != nil {
 		return err
 	}
@@ -501,3 +1355,22 @@ func (pinpointClient *PinpointClient) SendAgentStat(tstruct thrift.TStruct) erro
 
 	return nil
 }
+
+// SendAgentStatBatch ...
+func (pinpointClient *PinpointClient) SendAgentStatBatch(tstruct thrift.TStruct) error {
+	data, err := io.EncodeTstruct(io.TTypeAgentStatBatch, tstruct)
+	if err != nil {
+		return err
+	}
+
+	err = pinpointClient.WriteStatData(data)
+	if err != nil {
+		return err
+	}
+
+	pinpointClient.logger.Debug("agent stat batch data", map[string]interface{}{
+		"tstruct": fmt.Sprintf("% x", data),
+	})
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package pinpoint
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// collectorEndpoints resolves the collector hosts to the addresses the agent
+// fails over between and remembers the address in use.  Host names are
+// resolved again once refreshInterval has passed, so that collectors moved
+// to other addresses are followed.
+type collectorEndpoints struct {
+	hosts           []string
+	refreshInterval time.Duration
+	lookupHost      func(host string) ([]string, error)
+
+	sync.Mutex
+	// resolved holds the addresses of each host from the last lookup.
+	resolved   map[string][]string
+	resolvedAt time.Time
+	current    string
+}
+
+func newCollectorEndpoints(hosts []string, refreshInterval time.Duration) *collectorEndpoints {
+	return &collectorEndpoints{
+		hosts:           hosts,
+		refreshInterval: refreshInterval,
+		lookupHost:      net.LookupHost,
+		resolved:        make(map[string][]string),
+	}
+}
+
+// addresses returns the addresses of all hosts in the configured order.  A
+// host which cannot be resolved keeps the addresses of the last successful
+// lookup, or is returned as is if there was none.
+func (e *collectorEndpoints) addresses() []string {
+	e.Lock()
+	defer e.Unlock()
+
+	now := time.Now()
+	stale := e.resolvedAt.IsZero() ||
+		(e.refreshInterval > 0 && now.Sub(e.resolvedAt) >= e.refreshInterval)
+	if stale {
+		for _, host := range e.hosts {
+			if addrs, err := e.lookupHost(host); nil == err && len(addrs) > 0 {
+				e.resolved[host] = addrs
+			}
+		}
+		e.resolvedAt = now
+	}
+
+	var addrs []string
+	seen := make(map[string]bool)
+	for _, host := range e.hosts {
+		hostAddrs, ok := e.resolved[host]
+		if !ok {
+			hostAddrs = []string{host}
+		}
+		for _, addr := range hostAddrs {
+			if !seen[addr] {
+				seen[addr] = true
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	return addrs
+}
+
+// candidates returns the addresses in the order they should be tried: from
+// the primary address if sticky is set, and otherwise from the address in
+// use, wrapping around.
+func (e *collectorEndpoints) candidates(sticky bool) []string {
+	addrs := e.addresses()
+	if sticky {
+		return addrs
+	}
+	current := e.getCurrent()
+	for i, addr := range addrs {
+		if addr == current {
+			return append(append([]string{}, addrs[i:]...), addrs[:i]...)
+		}
+	}
+	return addrs
+}
+
+func (e *collectorEndpoints) getCurrent() string {
+	e.Lock()
+	defer e.Unlock()
+	return e.current
+}
+
+func (e *collectorEndpoints) setCurrent(addr string) {
+	e.Lock()
+	defer e.Unlock()
+	e.current = addr
+}
+
+// hostOf returns the configured host addr was resolved from, or addr itself
+// if it was not resolved from any.
+func (e *collectorEndpoints) hostOf(addr string) string {
+	e.Lock()
+	defer e.Unlock()
+	for _, host := range e.hosts {
+		if containsString(e.resolved[host], addr) {
+			return host
+		}
+	}
+	return addr
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package pinpoint
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCollectorEndpointsAddresses(t *testing.T) {
+	lookups := 0
+	fail := false
+	e := newCollectorEndpoints([]string{"collector.local", "10.0.0.9", "unknown.local"}, time.Hour)
+	e.lookupHost = func(host string) ([]string, error) {
+		lookups++
+		switch {
+		case fail, host == "unknown.local":
+			return nil, errors.New("no such host")
+		case host == "collector.local":
+			return []string{"10.0.0.1", "10.0.0.2"}, nil
+		}
+		return []string{host}, nil
+	}
+
+	want := []string{"10.0.0.1", "10.0.0.2", "10.0.0.9", "unknown.local"}
+	if addrs := e.addresses(); !reflect.DeepEqual(addrs, want) {
+		t.Error(addrs)
+	}
+	// Lookups are cached until the refresh interval has passed.
+	e.addresses()
+	if lookups != 3 {
+		t.Error(lookups)
+	}
+
+	// Failed lookups keep the previous addresses.
+	fail = true
+	e.resolvedAt = time.Now().Add(-time.Hour)
+	if addrs := e.addresses(); !reflect.DeepEqual(addrs, want) {
+		t.Error(addrs)
+	}
+	if lookups != 6 {
+		t.Error(lookups)
+	}
+}
+
+func TestCollectorEndpointsCandidates(t *testing.T) {
+	e := newCollectorEndpoints([]string{"a", "b", "c"}, 0)
+	e.lookupHost = func(host string) ([]string, error) { return []string{host}, nil }
+
+	if c := e.candidates(false); !reflect.DeepEqual(c, []string{"a", "b", "c"}) {
+		t.Error(c)
+	}
+	e.setCurrent("b")
+	if c := e.candidates(false); !reflect.DeepEqual(c, []string{"b", "c", "a"}) {
+		t.Error(c)
+	}
+	if c := e.candidates(true); !reflect.DeepEqual(c, []string{"a", "b", "c"}) {
+		t.Error(c)
+	}
+	e.setCurrent("gone")
+	if c := e.candidates(false); !reflect.DeepEqual(c, []string{"a", "b", "c"}) {
+		t.Error(c)
+	}
+}
@@ -8,14 +8,23 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/dingyalin/pinpoint-go-agent/internal"
 	"github.com/dingyalin/pinpoint-go-agent/internal/logger"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/command"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/io"
 )
 
 func TestCollectorResponseCodeError(t *testing.T) {
@@ -484,3 +493,860 @@ func TestPreconnectRequestMarshall(t *testing.T) {
 		}
 	}
 }
+
+// fakeCollector is a local collector tcp endpoint which answers handshakes
+// and pings and records every packet it receives.
+type fakeCollector struct {
+	listener      net.Listener
+	packets       chan *io.Packet
+	handshakeCode int32
+	// result returns the response to a request.  No response is sent
+	// when it returns nil.  Every request succeeds when result is nil.
+	result func(*io.Packet) *trace.TResult_
+
+	sync.Mutex
+	conns []net.Conn
+}
+
+func newFakeCollector(t *testing.T) *fakeCollector {
+	return newFakeCollectorAt(t, "127.0.0.1:0")
+}
+
+func newFakeCollectorAt(t *testing.T, address string) *fakeCollector {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newFakeCollectorOn(listener)
+}
+
+func newFakeCollectorOn(listener net.Listener) *fakeCollector {
+	fc := &fakeCollector{
+		listener: listener,
+		packets:  make(chan *io.Packet, 100),
+	}
+	go fc.serve()
+	return fc
+}
+
+func (fc *fakeCollector) serve() {
+	for {
+		conn, err := fc.listener.Accept()
+		if err != nil {
+			return
+		}
+		fc.Lock()
+		fc.conns = append(fc.conns, conn)
+		fc.Unlock()
+		go fc.handle(conn)
+	}
+}
+
+func (fc *fakeCollector) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := io.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		switch packet.Type {
+		case io.RequestTypeControlHandshake:
+			data, _ := io.EncodeControlHandshakeResponse(packet.RequestID, fc.handshakeCode, 0)
+			conn.Write(data)
+		case io.RequestTypeControlPingPayload:
+			conn.Write(io.EncodePong())
+		case io.RequestTypeAppRequest:
+			result := &trace.TResult_{Success: true}
+			if nil != fc.result {
+				result = fc.result(packet)
+			}
+			if nil != result {
+				data, _ := io.EncodeTCPTStructResponse(packet.RequestID, io.TTypeResult, result)
+				conn.Write(data)
+			}
+		}
+		fc.packets <- packet
+	}
+}
+
+// expectPacket returns the next received packet of type typ, skipping
+// packets of other types.
+func (fc *fakeCollector) expectPacket(t *testing.T, typ uint16) *io.Packet {
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case packet := <-fc.packets:
+			if packet.Type == typ {
+				return packet
+			}
+		case <-timeout:
+			t.Fatalf("packet type %d not received", typ)
+			return nil
+		}
+	}
+}
+
+// dropConnections closes every accepted connection.
+func (fc *fakeCollector) dropConnections() {
+	fc.Lock()
+	defer fc.Unlock()
+	for _, conn := range fc.conns {
+		conn.Close()
+	}
+	fc.conns = nil
+}
+
+func (fc *fakeCollector) Close() {
+	fc.listener.Close()
+	fc.dropConnections()
+}
+
+func newTestPinpointClient(address string) *PinpointClient {
+	return &PinpointClient{
+		uploaded:        true,
+		tcpAddress:      address,
+		tcpConnTimeout:  time.Second,
+		tcpPingInterval: time.Hour,
+		handshakeProperties: map[string]interface{}{
+			handshakeAgentID:         "agent",
+			handshakeApplicationName: "app",
+			handshakeServiceType:     int32(io.ServiceTypeGo),
+			handshakeStartTimestamp:  int64(1234),
+			handshakeSupportServer:   false,
+		},
+		tcpRequestTimeout: time.Second,
+		tcpRequestRetries: 2,
+		logger:            logger.ShimLogger{},
+	}
+}
+
+func TestPinpointClientHandshake(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	err := client.RequestTCPTStruct(io.TTypeAgentInfo, &pinpoint.TAgentInfo{AgentId: "agent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handshake := fc.expectPacket(t, io.RequestTypeControlHandshake)
+	message, err := io.DecodeControlMessage(handshake.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	properties := message.(map[string]interface{})
+	if properties[handshakeAgentID] != "agent" ||
+		properties[handshakeApplicationName] != "app" ||
+		properties[handshakeServiceType] != int32(io.ServiceTypeGo) ||
+		properties[handshakeStartTimestamp] != int64(1234) ||
+		properties[handshakeSupportServer] != false ||
+		properties[handshakeSocketID] != int32(1) {
+		t.Error(properties)
+	}
+
+	request := fc.expectPacket(t, io.RequestTypeAppRequest)
+	if request.RequestID <= handshake.RequestID {
+		t.Error(request.RequestID, handshake.RequestID)
+	}
+	if len(request.Payload) < 4 || request.Payload[3] != io.TTypeAgentInfo {
+		t.Errorf("% x", request.Payload)
+	}
+}
+
+func TestPinpointClientHandshakeRejected(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+	fc.handshakeCode = 2 // PROPERTY_ERROR
+
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	err := client.ConnTCP()
+	if err == nil || !strings.Contains(err.Error(), "handshake rejected") {
+		t.Fatal(err)
+	}
+	err = client.ConnTCP()
+	if err != errTCPReconnectBackoff {
+		t.Error(err)
+	}
+}
+
+func TestPinpointClientHandshakeTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		// Accept the connection but never answer the handshake.
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			ioutil.ReadAll(conn)
+		}
+	}()
+
+	client := newTestPinpointClient(listener.Addr().String())
+	client.tcpConnTimeout = 50 * time.Millisecond
+	if err := client.ConnTCP(); err != errTCPHandshakeTimeout {
+		t.Error(err)
+	}
+}
+
+func TestPinpointClientKeepAlive(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+
+	reconnected := make(chan struct{}, 1)
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	client.tcpPingInterval = 10 * time.Millisecond
+	client.reconnectHandler = func() { reconnected <- struct{}{} }
+
+	done := make(chan struct{})
+	defer close(done)
+	go client.keepAlive(done)
+
+	fc.expectPacket(t, io.RequestTypeControlHandshake)
+	ping := fc.expectPacket(t, io.RequestTypeControlPingPayload)
+	if ping.StateCode != socketStateRunSimplex {
+		t.Error(ping.StateCode)
+	}
+
+	// The connection is re-established after the collector drops it.
+	fc.dropConnections()
+	handshake := fc.expectPacket(t, io.RequestTypeControlHandshake)
+	message, _ := io.DecodeControlMessage(handshake.Payload)
+	if id := message.(map[string]interface{})[handshakeSocketID]; id != int32(2) {
+		t.Error(id)
+	}
+	select {
+	case <-reconnected:
+	case <-time.After(2 * time.Second):
+		t.Error("reconnect handler not called")
+	}
+}
+
+func TestPinpointClientKeepAliveWithoutPings(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+
+	reconnected := make(chan struct{}, 1)
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	client.tcpPingInterval = 0
+	client.tcpReconnectInterval = 10 * time.Millisecond
+	client.reconnectHandler = func() { reconnected <- struct{}{} }
+
+	done := make(chan struct{})
+	defer close(done)
+	go client.keepAlive(done)
+
+	// The connection is still re-established after the collector drops it.
+	fc.expectPacket(t, io.RequestTypeControlHandshake)
+	fc.dropConnections()
+	fc.expectPacket(t, io.RequestTypeControlHandshake)
+	select {
+	case <-reconnected:
+	case <-time.After(2 * time.Second):
+		t.Error("reconnect handler not called")
+	}
+}
+
+func TestPinpointClientRequest(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	err := client.RequestTCPTStruct(io.TTypeAPIMetadata, &trace.TApiMetaData{ApiId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.pending) != 0 {
+		t.Error(client.pending)
+	}
+}
+
+func TestPinpointClientRequestRetry(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+
+	var requests int32
+	fc.result = func(packet *io.Packet) *trace.TResult_ {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			message := "busy"
+			return &trace.TResult_{Success: false, Message: &message}
+		}
+		return &trace.TResult_{Success: true}
+	}
+
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	err := client.RequestTCPTStruct(io.TTypeAPIMetadata, &trace.TApiMetaData{ApiId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := fc.expectPacket(t, io.RequestTypeAppRequest)
+	second := fc.expectPacket(t, io.RequestTypeAppRequest)
+	if first.RequestID == second.RequestID {
+		t.Error(first.RequestID, second.RequestID)
+	}
+}
+
+func TestPinpointClientRequestRejected(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+
+	message := "invalid"
+	fc.result = func(packet *io.Packet) *trace.TResult_ {
+		return &trace.TResult_{Success: false, Message: &message}
+	}
+
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	client.tcpRequestRetries = 0
+	err := client.RequestTCPTStruct(io.TTypeAPIMetadata, &trace.TApiMetaData{ApiId: 1})
+	if err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Error(err)
+	}
+}
+
+func TestPinpointClientRequestTimeout(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+	fc.result = func(packet *io.Packet) *trace.TResult_ { return nil }
+
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	client.tcpRequestTimeout = 20 * time.Millisecond
+	err := client.RequestTCPTStruct(io.TTypeAPIMetadata, &trace.TApiMetaData{ApiId: 1})
+	if err != errTCPRequestTimeout {
+		t.Error(err)
+	}
+	for i := 0; i < 3; i++ {
+		fc.expectPacket(t, io.RequestTypeAppRequest)
+	}
+	if len(client.pending) != 0 {
+		t.Error(client.pending)
+	}
+}
+
+func TestPinpointClientRequestConnClosed(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+	fc.result = func(packet *io.Packet) *trace.TResult_ {
+		fc.dropConnections()
+		return nil
+	}
+
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	client.tcpRequestRetries = 0
+	err := client.RequestTCPTStruct(io.TTypeAPIMetadata, &trace.TApiMetaData{ApiId: 1})
+	if err != errTCPConnClosed {
+		t.Error(err)
+	}
+}
+
+func TestGetAPIIDUnacknowledged(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+
+	var acknowledge int32
+	fc.result = func(packet *io.Packet) *trace.TResult_ {
+		if atomic.LoadInt32(&acknowledge) == 0 {
+			return &trace.TResult_{Success: false}
+		}
+		return &trace.TResult_{Success: true}
+	}
+
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	client.tcpRequestRetries = 0
+	cfg := defaultConfig()
+	cfg.Logger = logger.ShimLogger{}
+	app := &app{
+		config:         config{Config: cfg},
+		pinpointClient: client,
+		Logger:         cfg.Logger,
+	}
+	txn := &txn{app: app, appRun: &appRun{Config: app.config}}
+
+	if id := txn.getAPIID("main.handler"); id != nil {
+		t.Fatal(*id)
+	}
+	if _, ok := app.apiMetaData.ids["main.handler"]; ok {
+		t.Error("unacknowledged api id registered")
+	}
+
+	atomic.StoreInt32(&acknowledge, 1)
+	id := txn.getAPIID("main.handler")
+	if id == nil || *id != 1 || app.apiMetaData.ids["main.handler"] != 1 {
+		t.Error(id, app.apiMetaData.ids)
+	}
+}
+
+func TestGetSQLID(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+
+	var acknowledge int32
+	fc.result = func(packet *io.Packet) *trace.TResult_ {
+		if atomic.LoadInt32(&acknowledge) == 0 {
+			return &trace.TResult_{Success: false}
+		}
+		return &trace.TResult_{Success: true}
+	}
+
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	client.tcpRequestRetries = 0
+	cfg := defaultConfig()
+	cfg.Logger = logger.ShimLogger{}
+	app := &app{
+		config:         config{Config: cfg},
+		pinpointClient: client,
+		Logger:         cfg.Logger,
+	}
+	txn := &txn{app: app, appRun: &appRun{Config: app.config}}
+
+	evt := &spanEvent{}
+	evt.AgentAttributes.addString(SpanAttributeDBStatement, "SELECT * FROM t WHERE id = 7")
+	tSpanEvent := &trace.TSpanEvent{}
+	txn.handeDatastoreSpanEvent(evt, tSpanEvent)
+	if len(tSpanEvent.Annotations) != 1 || tSpanEvent.Annotations[0].Key != io.TAnnotationSQL ||
+		tSpanEvent.Annotations[0].Value.GetStringValue() != "SELECT * FROM t WHERE id = 7" {
+		t.Error(tSpanEvent.Annotations)
+	}
+	if _, ok := app.sqlMetaData.ids["SELECT * FROM t WHERE id = 0#"]; ok {
+		t.Error("unacknowledged sql id registered")
+	}
+
+	atomic.StoreInt32(&acknowledge, 1)
+	for _, id := range []int32{1, 1} {
+		tSpanEvent := &trace.TSpanEvent{}
+		txn.handeDatastoreSpanEvent(evt, tSpanEvent)
+		if len(tSpanEvent.Annotations) != 1 || tSpanEvent.Annotations[0].Key != io.TAnnotationSQLID {
+			t.Fatal(tSpanEvent.Annotations)
+		}
+		value := tSpanEvent.Annotations[0].Value.GetIntStringStringValue()
+		if value.IntValue != id || value.GetStringValue1() != "7" || nil != value.StringValue2 {
+			t.Error(value.IntValue, value.GetStringValue1(), value.StringValue2)
+		}
+	}
+	if id := txn.getSQLID("SELECT 1"); id == nil || *id != 2 || len(app.sqlMetaData.ids) != 2 {
+		t.Error(id, app.sqlMetaData.ids)
+	}
+}
+
+func TestGetExceptionInfo(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+
+	var acknowledge int32
+	fc.result = func(packet *io.Packet) *trace.TResult_ {
+		if atomic.LoadInt32(&acknowledge) == 0 {
+			return &trace.TResult_{Success: false}
+		}
+		return &trace.TResult_{Success: true}
+	}
+
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	client.tcpRequestRetries = 0
+	cfg := defaultConfig()
+	cfg.Logger = logger.ShimLogger{}
+	app := &app{
+		config:         config{Config: cfg},
+		pinpointClient: client,
+		Logger:         cfg.Logger,
+	}
+	txn := &txn{app: app, appRun: &appRun{Config: app.config}}
+	txn.Attrs = newAttributes(createAttributeConfig(app.config, true))
+	txn.Errors = newTxnErrors(maxTxnErrors)
+	txn.Errors.Add(errorData{Klass: "*errors.errorString", Msg: "first"})
+	txn.Errors.Add(errorData{Klass: "*errors.errorString", Msg: "second"})
+
+	annotations, errFlag, exceptionInfo := txn.getAnnotationsErr()
+	if errFlag != 1 || nil != exceptionInfo || len(annotations) != 2 {
+		t.Error(errFlag, exceptionInfo, annotations)
+	}
+	if _, ok := app.stringMetaData.ids["*errors.errorString"]; ok {
+		t.Error("unacknowledged string id registered")
+	}
+
+	atomic.StoreInt32(&acknowledge, 1)
+	annotations, errFlag, exceptionInfo = txn.getAnnotationsErr()
+	if errFlag != 1 || nil == exceptionInfo || exceptionInfo.IntValue != 1 || exceptionInfo.GetStringValue() != "first" {
+		t.Fatal(errFlag, exceptionInfo)
+	}
+	if len(annotations) != 1 || annotations[0].Key != io.TAnnotationException || annotations[0].Value.GetStringValue() != "second" {
+		t.Error(annotations)
+	}
+
+	if info := txn.getExceptionInfo("*errors.errorString", "third"); nil == info || info.IntValue != 1 || info.GetStringValue() != "third" {
+		t.Error(info)
+	}
+	if info := txn.getExceptionInfo("*url.Error", ""); nil == info || info.IntValue != 2 {
+		t.Error(info)
+	}
+	if info := txn.getExceptionInfo("", "no class"); nil != info {
+		t.Error(info)
+	}
+}
+
+func TestPinpointClientSendAgentStatBatch(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := newTestPinpointClient("")
+	client.statAddress = conn.LocalAddr().String()
+	first, second := int64(1000), int64(2000)
+	err = client.SendAgentStatBatch(&pinpoint.TAgentStatBatch{
+		AgentId:        "agent",
+		StartTimestamp: 1234,
+		AgentStats:     []*pinpoint.TAgentStat{{Timestamp: &first}, {Timestamp: &second}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tstruct, err := io.DecodeTstruct(buf[:n])
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch, ok := tstruct.(*pinpoint.TAgentStatBatch)
+	if !ok || batch.AgentId != "agent" || len(batch.AgentStats) != 2 ||
+		batch.AgentStats[1].GetTimestamp() != 2000 {
+		t.Errorf("%#v", tstruct)
+	}
+}
+
+func newTestEndpointsClient(hosts []string, port string) *PinpointClient {
+	client := newTestPinpointClient("")
+	client.endpoints = newCollectorEndpoints(hosts, 0)
+	client.tcpPort, _ = strconv.Atoi(port)
+	client.statPort = 9995
+	client.spanPort = 9996
+	return client
+}
+
+func TestPinpointClientFailover(t *testing.T) {
+	// The first collector rejects the handshake, nothing listens on the
+	// second host.
+	rejecting := newFakeCollectorAt(t, "127.0.0.1:0")
+	defer rejecting.Close()
+	rejecting.handshakeCode = 2
+	_, port, _ := net.SplitHostPort(rejecting.listener.Addr().String())
+	fc := newFakeCollectorAt(t, net.JoinHostPort("127.0.0.3", port))
+	defer fc.Close()
+
+	client := newTestEndpointsClient([]string{"127.0.0.1", "127.0.0.2", "127.0.0.3"}, port)
+	if err := client.ConnTCP(); err != nil {
+		t.Fatal(err)
+	}
+	if client.tcpAddress != net.JoinHostPort("127.0.0.3", port) ||
+		client.statAddress != "127.0.0.3:9995" || client.spanAddress != "127.0.0.3:9996" {
+		t.Error(client.tcpAddress, client.statAddress, client.spanAddress)
+	}
+	if current := client.endpoints.getCurrent(); current != "127.0.0.3" {
+		t.Error(current)
+	}
+
+	// All collectors failing backs off.
+	client.tcpMu.Lock()
+	conn := client.tcpConn
+	client.tcpMu.Unlock()
+	fc.Close()
+	client.closeTCPConn(conn)
+	if err := client.ConnTCP(); err == nil {
+		t.Error("connected without a collector")
+	}
+	if err := client.ConnTCP(); err != errTCPReconnectBackoff {
+		t.Error(err)
+	}
+}
+
+func TestPinpointClientStickyPrimary(t *testing.T) {
+	secondary := newFakeCollectorAt(t, "127.0.0.2:0")
+	defer secondary.Close()
+	_, port, _ := net.SplitHostPort(secondary.listener.Addr().String())
+
+	client := newTestEndpointsClient([]string{"127.0.0.1", "127.0.0.2"}, port)
+	client.stickyPrimary = true
+	reconnected := make(chan struct{}, 1)
+	client.reconnectHandler = func() { reconnected <- struct{}{} }
+	if err := client.ConnTCP(); err != nil {
+		t.Fatal(err)
+	}
+	if current := client.endpoints.getCurrent(); current != "127.0.0.2" {
+		t.Fatal(current)
+	}
+
+	primary := newFakeCollectorAt(t, net.JoinHostPort("127.0.0.1", port))
+	defer primary.Close()
+	if err := client.ping(); err != nil {
+		t.Fatal(err)
+	}
+	if current := client.endpoints.getCurrent(); current != "127.0.0.1" {
+		t.Error(current)
+	}
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Error("reconnect handler not called")
+	}
+}
+
+func TestPinpointClientEndpointMoved(t *testing.T) {
+	old := newFakeCollectorAt(t, "127.0.0.2:0")
+	defer old.Close()
+	_, port, _ := net.SplitHostPort(old.listener.Addr().String())
+	moved := newFakeCollectorAt(t, net.JoinHostPort("127.0.0.1", port))
+	defer moved.Close()
+
+	var mu sync.Mutex
+	address := "127.0.0.2"
+	client := newTestEndpointsClient([]string{"collector.local"}, port)
+	client.endpoints.refreshInterval = time.Nanosecond
+	client.endpoints.lookupHost = func(host string) ([]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return []string{address}, nil
+	}
+	if err := client.ConnTCP(); err != nil {
+		t.Fatal(err)
+	}
+	if client.tcpAddress != net.JoinHostPort("127.0.0.2", port) {
+		t.Fatal(client.tcpAddress)
+	}
+
+	mu.Lock()
+	address = "127.0.0.1"
+	mu.Unlock()
+	if err := client.ping(); err != nil {
+		t.Fatal(err)
+	}
+	if client.tcpAddress != net.JoinHostPort("127.0.0.1", port) {
+		t.Error(client.tcpAddress)
+	}
+}
+
+func TestPinpointClientSlowResolver(t *testing.T) {
+	fc := newFakeCollectorAt(t, "127.0.0.1:0")
+	defer fc.Close()
+	_, port, _ := net.SplitHostPort(fc.listener.Addr().String())
+
+	lookups := make(chan struct{})
+	client := newTestEndpointsClient([]string{"collector.local"}, port)
+	client.endpoints.refreshInterval = time.Nanosecond
+	client.endpoints.lookupHost = func(host string) ([]string, error) { return []string{"127.0.0.1"}, nil }
+	if err := client.ConnTCP(); err != nil {
+		t.Fatal(err)
+	}
+	fc.expectPacket(t, io.RequestTypeControlHandshake)
+
+	// The next lookup blocks until the request below has been answered.
+	client.endpoints.Lock()
+	client.endpoints.lookupHost = func(host string) ([]string, error) {
+		<-lookups
+		return []string{"127.0.0.1"}, nil
+	}
+	client.endpoints.Unlock()
+	pinged := make(chan error, 1)
+	go func() { pinged <- client.ping() }()
+
+	requested := make(chan error, 1)
+	go func() {
+		requested <- client.RequestTCPTStruct(io.TTypeAgentInfo, &pinpoint.TAgentInfo{AgentId: "agent"})
+	}()
+	select {
+	case err := <-requested:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("request blocked by the resolver")
+	}
+	close(lookups)
+	if err := <-pinged; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTCPReconnectBackoff(t *testing.T) {
+	if d := tcpReconnectBackoff(0); d != time.Second {
+		t.Error(d)
+	}
+	if d := tcpReconnectBackoff(3); d != 8*time.Second {
+		t.Error(d)
+	}
+	if d := tcpReconnectBackoff(100); d != 60*time.Second {
+		t.Error(d)
+	}
+	if d := tcpReconnectBackoff(-1); d != 60*time.Second {
+		t.Error(d)
+	}
+}
+
+func TestControlMessageRoundTrip(t *testing.T) {
+	in := map[string]interface{}{
+		"int":    int32(-5),
+		"long":   int64(1) << 40,
+		"double": 1.5,
+		"string": strings.Repeat("x", 200),
+		"true":   true,
+		"false":  false,
+		"null":   nil,
+		"list":   []interface{}{int32(1), "two"},
+		"map":    map[string]interface{}{"code": int32(0)},
+	}
+	data, err := io.EncodeControlMessage(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.DecodeControlMessage(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("%#v", out)
+	}
+	if _, err := io.DecodeControlMessage(data[:len(data)-3]); err == nil {
+		t.Error("truncated message decoded")
+	}
+}
+
+func TestPinpointClientSpool(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+	spans, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spans.Close()
+
+	spool, cleanup := newTestSpool(t, 1<<20, time.Hour)
+	defer cleanup()
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	client.spanAddress = spans.LocalAddr().String()
+	client.spool = spool
+
+	// The collector is unreachable until the reconnect backoff has passed.
+	client.tcpRetryAt = time.Now().Add(time.Hour)
+	if err := client.RequestTStruct(io.TTypeAPIMetadata, &trace.TApiMetaData{ApiId: 1, ApiInfo: "main.handler"}); err != nil {
+		t.Fatal(err)
+	}
+	apiID := int32(1)
+	if err := client.SendSpan(&trace.TSpan{AgentId: "agent", ApiId: &apiID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RequestTStruct(io.TTypeAgentInfo, &pinpoint.TAgentInfo{}); err != errTCPReconnectBackoff {
+		t.Error(err)
+	}
+	if spool.len() != 2 {
+		t.Fatal(spool.len())
+	}
+
+	client.tcpRetryAt = time.Time{}
+	if err := client.ping(); err != nil {
+		t.Fatal(err)
+	}
+	client.replaySpool()
+	if spool.len() != 0 {
+		t.Error(spool.len())
+	}
+
+	packet := fc.expectPacket(t, io.RequestTypeAppRequest)
+	tstruct, err := io.DecodeTstruct(packet.Payload)
+	if apiMetaData, ok := tstruct.(*trace.TApiMetaData); !ok || apiMetaData.ApiInfo != "main.handler" {
+		t.Error(tstruct, err)
+	}
+	buf := make([]byte, 65536)
+	spans.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := spans.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tstruct, err = io.DecodeTstruct(buf[:n])
+	if span, ok := tstruct.(*trace.TSpan); !ok || span.GetApiId() != 1 {
+		t.Error(tstruct, err)
+	}
+
+	// Rejected metadata is not spooled.
+	fc.result = func(*io.Packet) *trace.TResult_ { return &trace.TResult_{} }
+	client.tcpRequestRetries = 0
+	if _, ok := client.RequestTStruct(io.TTypeAPIMetadata, &trace.TApiMetaData{ApiId: 2}).(tcpRejectedError); !ok {
+		t.Error("metadata not rejected")
+	}
+	if spool.len() != 0 {
+		t.Error(spool.len())
+	}
+}
+
+// send writes data to the most recently accepted connection.
+func (fc *fakeCollector) send(t *testing.T, data []byte) {
+	fc.Lock()
+	defer fc.Unlock()
+	if len(fc.conns) == 0 {
+		t.Fatal("no connection")
+	}
+	if _, err := fc.conns[len(fc.conns)-1].Write(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPinpointClientCommands(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+
+	client := newTestPinpointClient(fc.listener.Addr().String())
+	client.commands = newCommandDispatcher(&activeTransactions{}, logger.ShimLogger{})
+	if err := client.ConnTCP(); err != nil {
+		t.Fatal(err)
+	}
+
+	echo, _ := io.EncodeTCPTTstuctRequest(100, io.TTypeCommandEcho, &command.TCommandEcho{Message: "hello"})
+	fc.send(t, echo)
+	response := fc.expectPacket(t, io.RequestTypeAppResponse)
+	tstruct, err := io.DecodeTstruct(response.Payload)
+	if response.RequestID != 100 || err != nil || tstruct.(*command.TCommandEcho).Message != "hello" {
+		t.Error(response.RequestID, err, tstruct)
+	}
+
+	payload, _ := io.EncodeTstruct(io.TTypeCmdActiveThreadCount, &command.TCmdActiveThreadCount{})
+	create, _ := io.EncodeStreamCreate(7, payload)
+	fc.send(t, create)
+	if success := fc.expectPacket(t, io.RequestTypeAppStreamCreateSuccess); success.StreamID != 7 {
+		t.Error(success.StreamID)
+	}
+	response = fc.expectPacket(t, io.RequestTypeAppStreamResponse)
+	tstruct, err = io.DecodeTstruct(response.Payload)
+	if response.StreamID != 7 || err != nil {
+		t.Fatal(response.StreamID, err)
+	}
+	if counts := tstruct.(*command.TCmdActiveThreadCountRes).ActiveThreadCount; !reflect.DeepEqual(counts, []int32{0, 0, 0, 0}) {
+		t.Error(counts)
+	}
+
+	fc.send(t, io.EncodeStreamClose(7, io.StreamCodeOK))
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		client.tcpMu.Lock()
+		open := len(client.streams)
+		client.tcpMu.Unlock()
+		if open == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stream not closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	echoStream, _ := io.EncodeTstruct(io.TTypeCommandEcho, &command.TCommandEcho{})
+	create, _ = io.EncodeStreamCreate(8, echoStream)
+	fc.send(t, create)
+	if fail := fc.expectPacket(t, io.RequestTypeAppStreamCreateFail); fail.StreamID != 8 || fail.StreamCode != io.StreamCodeTypeUnsupport {
+		t.Error(fail.StreamID, fail.StreamCode)
+	}
+}
@@ -0,0 +1,56 @@
+package pinpoint
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+var errCollectorTLSKeyPair = errors.New("Collector.TLS.CertFile and Collector.TLS.KeyFile must be set together")
+
+// collectorTLSConfig loads the certificates of Collector.TLS.  It returns
+// nil if TLS is not enabled.
+func (c Config) collectorTLSConfig() (*tls.Config, error) {
+	settings := c.Collector.TLS
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{ServerName: settings.ServerName}
+	if "" != settings.CAFile {
+		pem, err := ioutil.ReadFile(settings.CAFile)
+		if nil != err {
+			return nil, fmt.Errorf("unable to read collector CA file: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in collector CA file %s", settings.CAFile)
+		}
+	}
+	if ("" == settings.CertFile) != ("" == settings.KeyFile) {
+		return nil, errCollectorTLSKeyPair
+	}
+	if "" != settings.CertFile {
+		cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if nil != err {
+			return nil, fmt.Errorf("unable to load collector client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// collectorServerName returns the name the certificate of the collector at
+// host is verified against: the configured server name, or else the host
+// name host was resolved from.
+func collectorServerName(tlsConfig *tls.Config, endpoints *collectorEndpoints, host string) string {
+	if "" != tlsConfig.ServerName {
+		return tlsConfig.ServerName
+	}
+	if nil != endpoints {
+		return endpoints.hostOf(host)
+	}
+	return host
+}
@@ -0,0 +1,203 @@
+package pinpoint
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/io"
+)
+
+// testCertificates are a CA, a collector certificate for collector.local
+// and a client certificate, all written as PEM files to dir.
+type testCertificates struct {
+	dir      string
+	caFile   string
+	certFile string
+	keyFile  string
+	// server requires a client certificate signed by the CA.
+	server *tls.Config
+}
+
+func newTestCertificates(t *testing.T) *testCertificates {
+	dir, err := ioutil.TempDir("", "pinpoint-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := &testCertificates{
+		dir:      dir,
+		caFile:   filepath.Join(dir, "ca.pem"),
+		certFile: filepath.Join(dir, "agent.pem"),
+		keyFile:  filepath.Join(dir, "agent-key.pem"),
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	caKey, caDER := newTestCertificate(t, caTemplate, nil, nil)
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestPEM(t, tc.caFile, "CERTIFICATE", caDER)
+
+	serverKey, serverDER := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "collector.local"},
+		DNSNames:     []string{"collector.local"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+	clientKey, clientDER := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "agent"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+	writeTestPEM(t, tc.certFile, "CERTIFICATE", clientDER)
+	clientKeyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestPEM(t, tc.keyFile, "EC PRIVATE KEY", clientKeyDER)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+	tc.server = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	return tc
+}
+
+func (tc *testCertificates) Close() {
+	os.RemoveAll(tc.dir)
+}
+
+// config returns a Config enabling TLS with the test certificates.
+func (tc *testCertificates) config() Config {
+	cfg := defaultConfig()
+	cfg.Collector.TLS.Enabled = true
+	cfg.Collector.TLS.CAFile = tc.caFile
+	cfg.Collector.TLS.CertFile = tc.certFile
+	cfg.Collector.TLS.KeyFile = tc.keyFile
+	return cfg
+}
+
+func newTestCertificate(t *testing.T, template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nil == parent {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, der
+}
+
+func writeTestPEM(t *testing.T, path, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectorTLSConfig(t *testing.T) {
+	tc := newTestCertificates(t)
+	defer tc.Close()
+
+	if tlsConfig, err := defaultConfig().collectorTLSConfig(); tlsConfig != nil || err != nil {
+		t.Error(tlsConfig, err)
+	}
+
+	cfg := tc.config()
+	cfg.Collector.TLS.ServerName = "collector.local"
+	tlsConfig, err := cfg.collectorTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.RootCAs == nil || len(tlsConfig.Certificates) != 1 || tlsConfig.ServerName != "collector.local" {
+		t.Error(tlsConfig)
+	}
+
+	cfg = tc.config()
+	cfg.Collector.TLS.KeyFile = ""
+	if _, err := cfg.collectorTLSConfig(); err != errCollectorTLSKeyPair {
+		t.Error(err)
+	}
+
+	cfg = tc.config()
+	cfg.Collector.TLS.CAFile = tc.keyFile
+	if _, err := cfg.collectorTLSConfig(); err == nil {
+		t.Error("CA file without certificates accepted")
+	}
+
+	cfg = tc.config()
+	cfg.Collector.TLS.CAFile = filepath.Join(tc.dir, "missing.pem")
+	if _, err := cfg.collectorTLSConfig(); err == nil {
+		t.Error("missing CA file accepted")
+	}
+}
+
+func TestPinpointClientTLS(t *testing.T) {
+	tc := newTestCertificates(t)
+	defer tc.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tc.server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fc := newFakeCollectorOn(listener)
+	defer fc.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	tlsConfig, err := tc.config().collectorTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The certificate is verified against the host name, not the address
+	// it was resolved to.
+	client := newTestEndpointsClient([]string{"collector.local"}, port)
+	client.endpoints.lookupHost = func(host string) ([]string, error) { return []string{"127.0.0.1"}, nil }
+	client.tlsConfig = tlsConfig
+	if err := client.RequestTCPTStruct(io.TTypeAgentInfo, &pinpoint.TAgentInfo{AgentId: "agent"}); err != nil {
+		t.Fatal(err)
+	}
+	fc.expectPacket(t, io.RequestTypeControlHandshake)
+	fc.expectPacket(t, io.RequestTypeAppRequest)
+
+	// A client without the CA does not trust the collector.
+	untrusted := newTestPinpointClient(listener.Addr().String())
+	untrusted.tlsConfig = &tls.Config{ServerName: "collector.local"}
+	if err := untrusted.ConnTCP(); err == nil {
+		t.Error("connected to an untrusted collector")
+	}
+}
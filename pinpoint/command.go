@@ -0,0 +1,266 @@
+package pinpoint
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/command"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/io"
+)
+
+const (
+	// activeThreadCountSchema is the type of the histogram schema of the
+	// active thread counts: the normal schema with 1s, 3s and 5s slots.
+	activeThreadCountSchema = 2
+	// activeThreadCountInterval is the interval between the responses of
+	// an active thread count stream.
+	activeThreadCountInterval = time.Second
+)
+
+// activeThreadCountSlots are the upper bounds of the fast, normal and slow
+// active thread count buckets.  Longer requests are very slow.
+var activeThreadCountSlots = [...]time.Duration{
+	1 * time.Second,
+	3 * time.Second,
+	5 * time.Second,
+}
+
+// supportedCommands lists the command types the agent answers, announced to
+// the collector in the handshake.
+func supportedCommands() []interface{} {
+	return []interface{}{
+		int32(io.TTypeCommandEcho),
+		int32(io.TTypeCmdActiveThreadCount),
+		int32(io.TTypeCmdActiveThreadDump),
+		int32(io.TTypeCmdActiveThreadLightDump),
+	}
+}
+
+// commandDispatcher answers the commands the pinpoint web sends to the agent
+// through the collector tcp connection.
+type commandDispatcher struct {
+	active *activeTransactions
+	log    Logger
+}
+
+func newCommandDispatcher(active *activeTransactions, log Logger) *commandDispatcher {
+	return &commandDispatcher{
+		active: active,
+		log:    log,
+	}
+}
+
+// handleRequest answers the command in payload.  Commands which cannot be
+// decoded or are not supported are answered with an unsuccessful TResult.
+func (d *commandDispatcher) handleRequest(payload []byte) (uint16, thrift.TStruct) {
+	tstruct, err := io.DecodeTstruct(payload)
+	if err != nil {
+		return commandFailure(err.Error())
+	}
+	if d.log.DebugEnabled() {
+		d.log.Debug("command received", map[string]interface{}{
+			"command": fmt.Sprintf("%T", tstruct),
+		})
+	}
+
+	switch cmd := tstruct.(type) {
+	case *command.TCommandEcho:
+		return io.TTypeCommandEcho, &command.TCommandEcho{Message: cmd.Message}
+	case *command.TCmdActiveThreadCount:
+		return io.TTypeCmdActiveThreadCountRes, d.activeThreadCount(time.Now())
+	case *command.TCmdActiveThreadDump:
+		return io.TTypeCmdActiveThreadDumpRes, d.activeThreadDump(cmd)
+	case *command.TCmdActiveThreadLightDump:
+		return io.TTypeCmdActiveThreadLightDumpRes, d.activeThreadLightDump(cmd)
+	default:
+		return commandFailure(fmt.Sprintf("unsupported command %T", tstruct))
+	}
+}
+
+// handleStream returns the function producing the responses of the streamed
+// command in payload, and the interval between them.  It returns nil if the
+// command cannot be streamed.
+func (d *commandDispatcher) handleStream(payload []byte) (func() (uint16, thrift.TStruct), time.Duration) {
+	tstruct, err := io.DecodeTstruct(payload)
+	if err != nil {
+		return nil, 0
+	}
+
+	switch tstruct.(type) {
+	case *command.TCmdActiveThreadCount:
+		next := func() (uint16, thrift.TStruct) {
+			return io.TTypeCmdActiveThreadCountRes, d.activeThreadCount(time.Now())
+		}
+		return next, activeThreadCountInterval
+	default:
+		return nil, 0
+	}
+}
+
+// activeThreadCount counts the transactions in flight at now by how long
+// they have been running.
+func (d *commandDispatcher) activeThreadCount(now time.Time) *command.TCmdActiveThreadCountRes {
+	counts := d.active.histogram(now)
+	timestamp := now.UnixNano() / int64(time.Millisecond)
+	return &command.TCmdActiveThreadCountRes{
+		HistogramSchemaType: activeThreadCountSchema,
+		ActiveThreadCount:   counts,
+		TimeStamp:           &timestamp,
+	}
+}
+
+// activeTxnDump is the state of an active transaction and of the goroutine
+// which started it.
+type activeTxnDump struct {
+	activeTxn
+	transactionID string
+	localTraceID  int64
+	entryPoint    string
+	sampled       bool
+	stack         goroutineStack
+	alive         bool
+}
+
+// dumpActive returns the active transactions selected by names and
+// localTraceIDs, or all of them if both are empty, the longest running
+// first.  At most limit transactions are returned if limit is positive, and
+// never more than maxActiveThreadDumps.  They have no stack unless the
+// goroutines are tracked.
+func (d *commandDispatcher) dumpActive(limit int32, names []string, localTraceIDs []int64, maxFrames int) []activeTxnDump {
+	max := maxActiveThreadDumps
+	if limit > 0 && int(limit) < max {
+		max = int(limit)
+	}
+
+	active := d.active.snapshot()
+	var stacks map[int64]goroutineStack
+	if d.active.trackGoroutines {
+		stacks = goroutineStacks(maxFrames)
+	}
+	dumps := make([]activeTxnDump, 0, len(active))
+	for _, a := range active {
+		if len(dumps) >= max {
+			break
+		}
+		dump := activeTxnDump{activeTxn: a}
+		a.txn.Lock()
+		finished := a.txn.finished
+		dump.transactionID = a.txn.TraceID
+		dump.localTraceID = a.txn.SequenceID
+		dump.entryPoint = a.txn.Name
+		dump.sampled = !a.txn.ignore && !a.txn.unsampled
+		a.txn.Unlock()
+		if finished || !dumpSelected(dump, names, localTraceIDs) {
+			continue
+		}
+		dump.stack, dump.alive = stacks[a.goroutine]
+		dumps = append(dumps, dump)
+	}
+	return dumps
+}
+
+func dumpSelected(dump activeTxnDump, names []string, localTraceIDs []int64) bool {
+	if len(names) == 0 && len(localTraceIDs) == 0 {
+		return true
+	}
+	for _, name := range names {
+		if name == goroutineName(dump.goroutine) {
+			return true
+		}
+	}
+	for _, id := range localTraceIDs {
+		if id == dump.localTraceID {
+			return true
+		}
+	}
+	return false
+}
+
+func goroutineName(id int64) string {
+	return fmt.Sprintf("goroutine %d", id)
+}
+
+// goroutineState maps the wait reason of a goroutine to the closest thread
+// state.  A goroutine which has exited since its transaction started is in
+// an unknown state.
+func goroutineState(dump activeTxnDump) command.TThreadState {
+	if !dump.alive {
+		return command.TThreadState_UNKNOWN
+	}
+	reason := strings.SplitN(dump.stack.state, ",", 2)[0]
+	switch reason {
+	case "running", "runnable", "syscall", "IO wait":
+		return command.TThreadState_RUNNABLE
+	case "sleep":
+		return command.TThreadState_TIMED_WAITING
+	case "semacquire", "sync.Mutex.Lock", "sync.RWMutex.Lock", "sync.RWMutex.RLock":
+		return command.TThreadState_BLOCKED
+	default:
+		return command.TThreadState_WAITING
+	}
+}
+
+// activeThreadDump dumps the stacks of the goroutines running the active
+// transactions.  The web derives the elapsed time of each transaction from
+// its start time.
+func (d *commandDispatcher) activeThreadDump(cmd *command.TCmdActiveThreadDump) *command.TCmdActiveThreadDumpRes {
+	res := &command.TCmdActiveThreadDumpRes{ThreadDumps: []*command.TActiveThreadDump{}}
+	res.Type, res.SubType, res.Version = dumpRuntime()
+	for _, dump := range d.dumpActive(cmd.GetLimit(), cmd.ThreadNameList, cmd.LocalTraceIdList, maxActiveThreadDumpFrames) {
+		transactionID, entryPoint := dump.transactionID, dump.entryPoint
+		res.ThreadDumps = append(res.ThreadDumps, &command.TActiveThreadDump{
+			StartTime:    dump.start.UnixNano() / int64(time.Millisecond),
+			LocalTraceId: dump.localTraceID,
+			ThreadDump: &command.TThreadDump{
+				ThreadName:          goroutineName(dump.goroutine),
+				ThreadId:            dump.goroutine,
+				ThreadState:         goroutineState(dump),
+				StackTrace:          dump.stack.frames,
+				LockedMonitors:      []*command.TMonitorInfo{},
+				LockedSynchronizers: []string{},
+			},
+			Sampled:       dump.sampled,
+			TransactionId: &transactionID,
+			EntryPoint:    &entryPoint,
+		})
+	}
+	return res
+}
+
+// activeThreadLightDump lists the goroutines running the active
+// transactions without their stacks.
+func (d *commandDispatcher) activeThreadLightDump(cmd *command.TCmdActiveThreadLightDump) *command.TCmdActiveThreadLightDumpRes {
+	res := &command.TCmdActiveThreadLightDumpRes{ThreadDumps: []*command.TActiveThreadLightDump{}}
+	res.Type, res.SubType, res.Version = dumpRuntime()
+	for _, dump := range d.dumpActive(cmd.GetLimit(), cmd.ThreadNameList, cmd.LocalTraceIdList, 0) {
+		transactionID, entryPoint := dump.transactionID, dump.entryPoint
+		state := goroutineState(dump)
+		res.ThreadDumps = append(res.ThreadDumps, &command.TActiveThreadLightDump{
+			StartTime:    dump.start.UnixNano() / int64(time.Millisecond),
+			LocalTraceId: dump.localTraceID,
+			ThreadDump: &command.TThreadLightDump{
+				ThreadName:  goroutineName(dump.goroutine),
+				ThreadId:    dump.goroutine,
+				ThreadState: &state,
+			},
+			Sampled:       dump.sampled,
+			TransactionId: &transactionID,
+			EntryPoint:    &entryPoint,
+		})
+	}
+	return res
+}
+
+// dumpRuntime describes the runtime in the dump responses.
+func dumpRuntime() (*string, *string, *string) {
+	typ, subType, version := "GO", runtime.Compiler, runtime.Version()
+	return &typ, &subType, &version
+}
+
+func commandFailure(message string) (uint16, thrift.TStruct) {
+	return io.TTypeResult, &trace.TResult_{Success: false, Message: &message}
+}
@@ -0,0 +1,180 @@
+package pinpoint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+	"github.com/dingyalin/pinpoint-go-agent/internal/logger"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/command"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/io"
+)
+
+func encodeTestCommand(t *testing.T, ttype uint16, tstruct thrift.TStruct) []byte {
+	data, err := io.EncodeTstruct(ttype, tstruct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestCommandDispatcherEcho(t *testing.T) {
+	d := newCommandDispatcher(&activeTransactions{}, logger.ShimLogger{})
+	ttype, response := d.handleRequest(encodeTestCommand(t, io.TTypeCommandEcho, &command.TCommandEcho{Message: "hello"}))
+	if echo, ok := response.(*command.TCommandEcho); ttype != io.TTypeCommandEcho || !ok || echo.Message != "hello" {
+		t.Error(ttype, response)
+	}
+}
+
+func TestCommandDispatcherUnsupported(t *testing.T) {
+	d := newCommandDispatcher(&activeTransactions{}, logger.ShimLogger{})
+	ttype, response := d.handleRequest(encodeTestCommand(t, io.TTypeCommandThreadDump, &command.TCommandThreadDump{}))
+	if result, ok := response.(*trace.TResult_); ttype != io.TTypeResult || !ok || result.Success {
+		t.Error(ttype, response)
+	}
+	ttype, response = d.handleRequest([]byte{1, 2, 3})
+	if result, ok := response.(*trace.TResult_); ttype != io.TTypeResult || !ok || result.Success {
+		t.Error(ttype, response)
+	}
+	if next, _ := d.handleStream(encodeTestCommand(t, io.TTypeCommandEcho, &command.TCommandEcho{})); nil != next {
+		t.Error("echo streamed")
+	}
+}
+
+func TestCommandDispatcherActiveThreadCount(t *testing.T) {
+	now := time.Now()
+	active := &activeTransactions{}
+	for _, elapsed := range []time.Duration{
+		10 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 6 * time.Second, time.Minute,
+	} {
+		txn := &txn{}
+		txn.Start = now.Add(-elapsed)
+		active.add(txn)
+	}
+	ended := &txn{}
+	ended.Start = now
+	active.add(ended)
+	active.remove(ended)
+
+	d := newCommandDispatcher(active, logger.ShimLogger{})
+	res := d.activeThreadCount(now)
+	if res.HistogramSchemaType != activeThreadCountSchema || res.GetTimeStamp() != now.UnixNano()/int64(time.Millisecond) {
+		t.Error(res)
+	}
+	if !reflect.DeepEqual(res.ActiveThreadCount, []int32{2, 1, 2, 2}) {
+		t.Error(res.ActiveThreadCount)
+	}
+
+	next, interval := d.handleStream(encodeTestCommand(t, io.TTypeCmdActiveThreadCount, &command.TCmdActiveThreadCount{}))
+	if nil == next || interval != activeThreadCountInterval {
+		t.Fatal(interval)
+	}
+	if ttype, response := next(); ttype != io.TTypeCmdActiveThreadCountRes || len(response.(*command.TCmdActiveThreadCountRes).ActiveThreadCount) != 4 {
+		t.Error(ttype, response)
+	}
+}
+
+// blockedInTransaction starts a transaction on a new goroutine which blocks
+// until release is closed.
+func blockedInTransaction(active *activeTransactions, txn *txn, release chan struct{}) {
+	started := make(chan struct{})
+	go func() {
+		active.add(txn)
+		close(started)
+		<-release
+	}()
+	<-started
+}
+
+func TestCommandDispatcherActiveThreadDump(t *testing.T) {
+	now := time.Now()
+	active := &activeTransactions{trackGoroutines: true}
+	release := make(chan struct{})
+	defer close(release)
+
+	older := &txn{}
+	older.Start = now.Add(-time.Minute)
+	older.Name = "GET /slow"
+	older.TraceID = "agent^1^1"
+	older.SequenceID = 1
+	blockedInTransaction(active, older, release)
+
+	newer := &txn{}
+	newer.Start = now
+	newer.Name = "GET /fast"
+	newer.TraceID = "agent^1^2"
+	newer.SequenceID = 2
+	blockedInTransaction(active, newer, release)
+
+	d := newCommandDispatcher(active, logger.ShimLogger{})
+	ttype, response := d.handleRequest(encodeTestCommand(t, io.TTypeCmdActiveThreadDump, &command.TCmdActiveThreadDump{}))
+	res, ok := response.(*command.TCmdActiveThreadDumpRes)
+	if ttype != io.TTypeCmdActiveThreadDumpRes || !ok || len(res.ThreadDumps) != 2 {
+		t.Fatal(ttype, response)
+	}
+	if _, err := io.EncodeTstruct(ttype, res); err != nil {
+		t.Error(err)
+	}
+	dump := res.ThreadDumps[0]
+	if dump.GetTransactionId() != "agent^1^1" || dump.LocalTraceId != 1 || dump.GetEntryPoint() != "GET /slow" ||
+		dump.StartTime != older.Start.UnixNano()/int64(time.Millisecond) || !dump.Sampled {
+		t.Error(dump)
+	}
+	threadDump := dump.ThreadDump
+	if threadDump.ThreadState != command.TThreadState_WAITING || threadDump.ThreadName != goroutineName(threadDump.ThreadId) {
+		t.Error(threadDump.ThreadState, threadDump.ThreadName)
+	}
+	if len(threadDump.StackTrace) == 0 || len(threadDump.StackTrace) > maxActiveThreadDumpFrames ||
+		!strings.Contains(strings.Join(threadDump.StackTrace, "\n"), "blockedInTransaction") {
+		t.Error(threadDump.StackTrace)
+	}
+
+	limit := int32(1)
+	_, response = d.handleRequest(encodeTestCommand(t, io.TTypeCmdActiveThreadDump, &command.TCmdActiveThreadDump{Limit: &limit}))
+	if dumps := response.(*command.TCmdActiveThreadDumpRes).ThreadDumps; len(dumps) != 1 || dumps[0].LocalTraceId != 1 {
+		t.Error(dumps)
+	}
+
+	ttype, response = d.handleRequest(encodeTestCommand(t, io.TTypeCmdActiveThreadLightDump, &command.TCmdActiveThreadLightDump{
+		LocalTraceIdList: []int64{2},
+	}))
+	light, ok := response.(*command.TCmdActiveThreadLightDumpRes)
+	if ttype != io.TTypeCmdActiveThreadLightDumpRes || !ok || len(light.ThreadDumps) != 1 {
+		t.Fatal(ttype, response)
+	}
+	if dump := light.ThreadDumps[0]; dump.LocalTraceId != 2 || dump.ThreadDump.GetThreadState() != command.TThreadState_WAITING {
+		t.Error(dump)
+	}
+
+	_, response = d.handleRequest(encodeTestCommand(t, io.TTypeCmdActiveThreadLightDump, &command.TCmdActiveThreadLightDump{
+		ThreadNameList: []string{threadDump.ThreadName},
+	}))
+	if dumps := response.(*command.TCmdActiveThreadLightDumpRes).ThreadDumps; len(dumps) != 1 || dumps[0].LocalTraceId != 1 {
+		t.Error(dumps)
+	}
+}
+
+func TestCommandDispatcherActiveThreadDumpUntracked(t *testing.T) {
+	active := &activeTransactions{}
+	release := make(chan struct{})
+	defer close(release)
+
+	txn := &txn{}
+	txn.Start = time.Now()
+	txn.SequenceID = 1
+	blockedInTransaction(active, txn, release)
+
+	d := newCommandDispatcher(active, logger.ShimLogger{})
+	_, response := d.handleRequest(encodeTestCommand(t, io.TTypeCmdActiveThreadDump, &command.TCmdActiveThreadDump{}))
+	dumps := response.(*command.TCmdActiveThreadDumpRes).ThreadDumps
+	if len(dumps) != 1 || dumps[0].LocalTraceId != 1 {
+		t.Fatal(dumps)
+	}
+	if threadDump := dumps[0].ThreadDump; threadDump.ThreadId != 0 || len(threadDump.StackTrace) != 0 ||
+		threadDump.ThreadState != command.TThreadState_UNKNOWN {
+		t.Error(threadDump)
+	}
+}
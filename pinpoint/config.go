@@ -4,6 +4,7 @@
 package pinpoint
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -36,12 +37,39 @@ type Config struct {
 	// ApiMetaDataMapSize ...
 	APIMetaDataMapSize int
 
+	// SQLMetaDataMapSize is the number of normalized sql statements whose
+	// ids are cached before the cache is reset.
+	SQLMetaDataMapSize int
+
+	// StringMetaDataMapSize is the number of error class names whose ids
+	// are cached before the cache is reset.
+	StringMetaDataMapSize int
+
 	// listen ports
 	Ports string
 
 	Collector struct {
+		// Protocol selects how the agent talks to the collector.
+		// CollectorProtocolThrift, the default, sends thrift over the
+		// TCPPort, StatPort and SpanPort.  CollectorProtocolGRPC uses
+		// the gRPC services of Pinpoint 2.x collectors on the
+		// GRPCAgentPort, GRPCStatPort and GRPCSpanPort.
+		Protocol string
 		// IP
 		IP string
+		// Hosts lists the collector host names or IPs in order of
+		// preference.  IP is used when it is empty.  The agent fails
+		// over to the next host when connecting to the collector or
+		// its handshake fails.
+		Hosts []string
+		// DNSRefreshInterval is how often Hosts are resolved again.
+		// Zero resolves them once.
+		DNSRefreshInterval time.Duration
+		// StickyPrimary moves the connection back to the first host as
+		// soon as it accepts connections again.  Without it the agent
+		// stays on the host it failed over to.  Only the thrift
+		// protocol supports it.
+		StickyPrimary bool
 		// TCPPort tcp port
 		TCPPort int
 		// StatPort stat port
@@ -52,12 +80,140 @@ type Config struct {
 		Uploaded bool
 		// UploadedAgentStat
 		UploadedAgentStat bool
+		// StatCollectInterval is the interval between agent stat
+		// samples.  The samples are buffered and sent together in one
+		// batch every StatSendInterval.
+		StatCollectInterval time.Duration
+		StatSendInterval    time.Duration
 		// TCPConnTimeout tcp conn timeout
 		TCPConnTimeout time.Duration
+		// TCPPingInterval is the interval between pings on the tcp
+		// connection.  A lost connection is re-established, and the
+		// spool replayed, on the next ping.  Zero disables the pings;
+		// a lost connection is then checked for every minute.
+		TCPPingInterval time.Duration
+		// TCPRequestTimeout is how long to wait for the collector to
+		// acknowledge a tcp request such as agent info or api metadata.
+		TCPRequestTimeout time.Duration
+		// TCPRequestRetryCount is the number of times a tcp request
+		// that is rejected or not acknowledged is sent again.
+		TCPRequestRetryCount int
+		// GRPCAgentPort is the port of the agent and metadata services,
+		// GRPCStatPort of the stat service and GRPCSpanPort of the span
+		// service.  The gRPC transport shares TCPPingInterval,
+		// TCPRequestTimeout and TCPRequestRetryCount with the tcp one.
+		GRPCAgentPort int
+		GRPCStatPort  int
+		GRPCSpanPort  int
+		// SendQueueSize is the number of spans and agent stats that may
+		// wait to be encoded and sent to the collector.  Anything
+		// created while the queue is full is dropped.
+		SendQueueSize int
+		// SendWorkers is the number of goroutines sending the queue.
+		SendWorkers int
+		// SpanEventMaxCount and SpanMaxPacketSize bound the span events
+		// of a span sent in one packet.  Span events beyond either limit
+		// are sent in span chunks following the span.  Zero disables a
+		// limit.
+		SpanEventMaxCount int
+		SpanMaxPacketSize int
+		// TLS secures the connections to the collector: the tcp
+		// connection of the thrift protocol and every connection of
+		// the gRPC protocol.  The udp stat and span packets of the
+		// thrift protocol are always sent in plaintext.
+		TLS struct {
+			// Enabled controls whether the connections use TLS.
+			Enabled bool
+			// CAFile is a PEM file with the certificate authorities
+			// the collector certificate is verified with.  The
+			// system roots are used when it is empty.
+			CAFile string
+			// CertFile and KeyFile are the PEM files of the client
+			// certificate, for collectors requiring one.
+			CertFile string
+			KeyFile  string
+			// ServerName is the name the collector certificate is
+			// verified against.  It defaults to the collector host
+			// connected to.
+			ServerName string
+		}
+		// Spool keeps metadata requests and spans on disk while the
+		// collector is unreachable and sends them once it is back.
+		// Metadata is replayed before the spans which may refer to it.
+		// Only the thrift protocol uses the spool.
+		Spool struct {
+			// Dir is the directory the packets are written to.  An
+			// empty Dir disables the spool.  Every agent needs a
+			// directory of its own.
+			Dir string
+			// MaxSize is the number of bytes the spool may hold.
+			// The oldest packets are evicted to make room for new
+			// ones.
+			MaxSize int
+			// TTL is how long a packet is kept.  Zero keeps packets
+			// until they are evicted.
+			TTL time.Duration
+		}
 	}
 
+	// SamplingRate samples one in SamplingRate of the transactions started
+	// by this agent, with the default SamplingTypeCounter sampler.
+	// Transactions continuing a trace follow the sampling decision of the
+	// caller, sent in the Pinpoint-Sampled header.
 	SamplingRate int
 
+	// Sampling selects the sampler deciding which new traces are sampled.
+	Sampling struct {
+		// Type is SamplingTypeCounter, the default, SamplingTypePercent,
+		// SamplingTypeThroughput or SamplingTypeAdaptive.
+		Type string
+		// Percent is the percentage of the new traces sampled by
+		// SamplingTypePercent.
+		Percent float64
+		// PerSecond is the number of new traces sampled per second by
+		// SamplingTypeThroughput.
+		PerSecond int
+		// PerMinute is the number of new traces SamplingTypeAdaptive aims
+		// to sample per minute.
+		PerMinute int
+		// Rules are matched against the transactions, in order, before
+		// the sampler of Type.  For example
+		//
+		//	cfg.Sampling.Rules = []SamplingRule{
+		//		{Path: "/checkout", Percent: 100},
+		//		{Path: "/healthz", Percent: 0},
+		//	}
+		Rules []SamplingRule
+		// Sampler replaces the sampler of Type when set.  The Rules are
+		// still matched first.
+		Sampler Sampler
+		// TailBased defers the sampling decision to the end of the
+		// transactions, so that those which failed or were slow are
+		// always sent.  The others follow the decision of the caller or
+		// of the sampler.  The decision is local: the transactions
+		// called always receive a sampled trace, so that the ones kept
+		// are linked to their callees.  A transaction whose caller
+		// sent an unsampled trace (s0) is never kept, and passes s0 on,
+		// since the caller dropped its own part of the trace.
+		TailBased struct {
+			Enabled bool
+			// LatencyThreshold is the duration over which the
+			// transactions are kept.  Zero keeps only the failed
+			// ones.
+			LatencyThreshold time.Duration
+			// MaxSpanEvents bounds the span events every
+			// transaction records until it is decided.  Zero
+			// keeps the default limit of 1000.
+			MaxSpanEvents int
+		}
+	}
+
+	// RemoteAddrHeader is the header of inbound requests holding the
+	// address of the client, such as "X-Forwarded-For" behind a proxy.  The
+	// first address it lists is reported as the remote address of the
+	// span.  When empty or missing, the peer address is reported.
+	RemoteAddrHeader string
+
 	// License is your New Relic license key.
 	//
 	// https://docs.newrelic.com/docs/accounts/install-new-relic/account-setup/license-key
@@ -404,6 +560,15 @@ type AttributeDestinationConfig struct {
 	Exclude []string
 }
 
+// Values of Config.Collector.Protocol.
+const (
+	// CollectorProtocolThrift sends thrift over tcp and udp.
+	CollectorProtocolThrift = "thrift"
+	// CollectorProtocolGRPC uses the gRPC services of Pinpoint 2.x
+	// collectors.
+	CollectorProtocolGRPC = "grpc"
+)
+
 // defaultConfig creates a Config populated with default settings.
 func defaultConfig() Config {
 	c := Config{}
@@ -411,16 +576,35 @@ func defaultConfig() Config {
 	c.Enabled = true
 	c.ServiceType = io.ServiceTypeGo
 	c.APIMetaDataMapSize = 4096
+	c.SQLMetaDataMapSize = 4096
+	c.StringMetaDataMapSize = 4096
 
 	c.SamplingRate = 5 // 20%
 
+	c.Collector.Protocol = CollectorProtocolThrift
 	c.Collector.IP = "127.0.0.1"
+	c.Collector.DNSRefreshInterval = 60 * time.Second
 	c.Collector.TCPPort = 9994
 	c.Collector.StatPort = 9995
 	c.Collector.SpanPort = 9996
 	c.Collector.Uploaded = true
 	c.Collector.UploadedAgentStat = true
+	c.Collector.StatCollectInterval = 5 * time.Second
+	c.Collector.StatSendInterval = 30 * time.Second
 	c.Collector.TCPConnTimeout = 2 * time.Second
+	c.Collector.TCPPingInterval = 60 * time.Second
+	c.Collector.TCPRequestTimeout = 3 * time.Second
+	c.Collector.TCPRequestRetryCount = 2
+	c.Collector.GRPCAgentPort = 9991
+	c.Collector.GRPCStatPort = 9992
+	c.Collector.GRPCSpanPort = 9993
+	c.Collector.SendQueueSize = 1024
+	c.Collector.SendWorkers = 2
+	c.Collector.SpanEventMaxCount = 500
+	c.Collector.SpanMaxPacketSize = 65000
+	c.Collector.Spool.MaxSize = 64 * 1024 * 1024
+	c.Collector.Spool.TTL = time.Hour
+	c.Sampling.TailBased.LatencyThreshold = 3 * time.Second
 
 	c.Labels = make(map[string]string)
 	c.CustomInsightsEvents.Enabled = true
@@ -496,6 +680,15 @@ var (
 	errAgentIDLimit                     = fmt.Errorf("max of %d length agent id", agentIDLimit)
 	errHighSecurityWithSecurityPolicies = errors.New("SecurityPoliciesToken and HighSecurity are incompatible; please ensure HighSecurity is set to false if SecurityPoliciesToken is a non-empty string and a security policy has been set for your account")
 	errInfTracingServerless             = errors.New("ServerlessMode cannot be used with Infinite Tracing")
+	errCollectorProtocol                = fmt.Errorf("collector protocol must be %q or %q", CollectorProtocolThrift, CollectorProtocolGRPC)
+	errStatInterval                     = errors.New("Collector.StatCollectInterval and Collector.StatSendInterval must be positive")
+	errTCPPingInterval                  = errors.New("Collector.TCPPingInterval must not be negative")
+	errSpoolMaxSize                     = errors.New("Collector.Spool.MaxSize must be positive when Collector.Spool.Dir is set")
+	errSamplingType                     = fmt.Errorf("sampling type must be %q, %q, %q or %q", SamplingTypeCounter, SamplingTypePercent, SamplingTypeThroughput, SamplingTypeAdaptive)
+	errSamplingPercent                  = errors.New("Sampling.Percent and the percents of Sampling.Rules must be between 0 and 100")
+	errSamplingPerSecond                = errors.New("Sampling.PerSecond must be positive with the throughput sampler")
+	errSamplingPerMinute                = errors.New("Sampling.PerMinute must be positive with the adaptive sampler")
+	errSamplingTailBased                = errors.New("Sampling.TailBased.LatencyThreshold and MaxSpanEvents must not be negative")
 )
 
 // validate checks the config for improper fields.  If the config is invalid,
@@ -531,10 +724,60 @@ func (c Config) validate() error {
 	if len(c.AgentID) > agentIDLimit {
 		return errAgentIDLimit
 	}
+	switch c.Collector.Protocol {
+	case "", CollectorProtocolThrift:
+	case CollectorProtocolGRPC:
+		if !versionSupportsGRPCCollector {
+			return errGRPCCollectorUnsupportedVersion
+		}
+	default:
+		return errCollectorProtocol
+	}
+	if c.Collector.StatCollectInterval <= 0 || c.Collector.StatSendInterval <= 0 {
+		return errStatInterval
+	}
+	if c.Collector.TCPPingInterval < 0 {
+		return errTCPPingInterval
+	}
+	if "" != c.Collector.Spool.Dir && c.Collector.Spool.MaxSize <= 0 {
+		return errSpoolMaxSize
+	}
+	if err := c.validateSampling(); nil != err {
+		return err
+	}
 
 	return nil
 }
 
+func (c Config) validateSampling() error {
+	switch c.Sampling.Type {
+	case "", SamplingTypeCounter:
+	case SamplingTypePercent:
+		if c.Sampling.Percent < 0 || c.Sampling.Percent > 100 {
+			return errSamplingPercent
+		}
+	case SamplingTypeThroughput:
+		if nil == c.Sampling.Sampler && c.Sampling.PerSecond <= 0 {
+			return errSamplingPerSecond
+		}
+	case SamplingTypeAdaptive:
+		if nil == c.Sampling.Sampler && c.Sampling.PerMinute <= 0 {
+			return errSamplingPerMinute
+		}
+	default:
+		return errSamplingType
+	}
+	for _, rule := range c.Sampling.Rules {
+		if rule.Percent < 0 || rule.Percent > 100 {
+			return errSamplingPercent
+		}
+	}
+	if c.Sampling.TailBased.LatencyThreshold < 0 || c.Sampling.TailBased.MaxSpanEvents < 0 {
+		return errSamplingTailBased
+	}
+	return nil
+}
+
 func (c Config) validateTraceObserverConfig() (*observerURL, error) {
 	configHost := c.InfiniteTracing.TraceObserver.Host
 	if "" == configHost {
@@ -586,11 +829,19 @@ func copyConfigReferenceFields(cfg Config) Config {
 			cp.Labels[key] = val
 		}
 	}
+	if nil != cfg.Collector.Hosts {
+		cp.Collector.Hosts = make([]string, len(cfg.Collector.Hosts))
+		copy(cp.Collector.Hosts, cfg.Collector.Hosts)
+	}
 	if nil != cfg.ErrorCollector.IgnoreStatusCodes {
 		ignored := make([]int, len(cfg.ErrorCollector.IgnoreStatusCodes))
 		copy(ignored, cfg.ErrorCollector.IgnoreStatusCodes)
 		cp.ErrorCollector.IgnoreStatusCodes = ignored
 	}
+	if nil != cfg.Sampling.Rules {
+		cp.Sampling.Rules = make([]SamplingRule, len(cfg.Sampling.Rules))
+		copy(cp.Sampling.Rules, cfg.Sampling.Rules)
+	}
 
 	cp.Attributes = copyDestConfig(cfg.Attributes)
 	cp.ErrorCollector.Attributes = copyDestConfig(cfg.ErrorCollector.Attributes)
@@ -752,6 +1003,9 @@ type config struct {
 	metadata         map[string]string
 	hostname         string
 	traceObserverURL *observerURL
+	// collectorTLS is the TLS configuration of the collector connections,
+	// nil when Collector.TLS is not enabled.
+	collectorTLS *tls.Config
 }
 
 func (c Config) computeDynoHostname(getenv func(string) string) string {
@@ -785,6 +1039,10 @@ func newInternalConfig(cfg Config, getenv func(string) string, environ []string)
 	if err != nil {
 		return config{}, err
 	}
+	collectorTLS, err := cfg.collectorTLSConfig()
+	if err != nil {
+		return config{}, err
+	}
 	// Ensure that Logger is always set to avoid nil checks.
 	if nil == cfg.Logger {
 		cfg.Logger = logger.ShimLogger{}
@@ -803,6 +1061,7 @@ func newInternalConfig(cfg Config, getenv func(string) string, environ []string)
 		metadata:         gatherMetadata(environ),
 		hostname:         hostname,
 		traceObserverURL: obsURL,
+		collectorTLS:     collectorTLS,
 	}, nil
 }
 
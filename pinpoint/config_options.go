@@ -10,6 +10,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	yaml "gopkg.in/yaml.v2"
@@ -33,6 +34,56 @@ func ConfigSamplingRate(rate int) ConfigOption {
 	return func(cfg *Config) { cfg.SamplingRate = rate }
 }
 
+// ConfigSamplingPercent samples percent percent of the new traces at random.
+func ConfigSamplingPercent(percent float64) ConfigOption {
+	return func(cfg *Config) {
+		cfg.Sampling.Type = SamplingTypePercent
+		cfg.Sampling.Percent = percent
+	}
+}
+
+// ConfigSamplingThroughput samples at most perSecond new traces per second.
+func ConfigSamplingThroughput(perSecond int) ConfigOption {
+	return func(cfg *Config) {
+		cfg.Sampling.Type = SamplingTypeThroughput
+		cfg.Sampling.PerSecond = perSecond
+	}
+}
+
+// ConfigSamplingAdaptive samples about perMinute new traces per minute,
+// adjusting the share of the traces sampled as the traffic changes.
+func ConfigSamplingAdaptive(perMinute int) ConfigOption {
+	return func(cfg *Config) {
+		cfg.Sampling.Type = SamplingTypeAdaptive
+		cfg.Sampling.PerMinute = perMinute
+	}
+}
+
+// ConfigSamplingTailBased decides at the end of the transactions whether they
+// are sent, keeping those which failed or ran over latencyThreshold.
+func ConfigSamplingTailBased(latencyThreshold time.Duration) ConfigOption {
+	return func(cfg *Config) {
+		cfg.Sampling.TailBased.Enabled = true
+		cfg.Sampling.TailBased.LatencyThreshold = latencyThreshold
+	}
+}
+
+// ConfigSamplingRules sets the rules matched before the sampler.
+func ConfigSamplingRules(rules ...SamplingRule) ConfigOption {
+	return func(cfg *Config) { cfg.Sampling.Rules = rules }
+}
+
+// ConfigSampler replaces the sampler with a custom one.
+func ConfigSampler(sampler Sampler) ConfigOption {
+	return func(cfg *Config) { cfg.Sampling.Sampler = sampler }
+}
+
+// ConfigRemoteAddrHeader sets the header holding the client address of
+// inbound requests.
+func ConfigRemoteAddrHeader(header string) ConfigOption {
+	return func(cfg *Config) { cfg.RemoteAddrHeader = header }
+}
+
 // ConfigAgentID sets the agent id.
 func ConfigAgentID(agentID string) ConfigOption {
 	return func(cfg *Config) { cfg.AgentID = agentID }
@@ -43,6 +94,12 @@ func ConfigCollectorIP(collectorIP string) ConfigOption {
 	return func(cfg *Config) { cfg.Collector.IP = collectorIP }
 }
 
+// ConfigCollectorProtocol sets the collector protocol, CollectorProtocolThrift
+// or CollectorProtocolGRPC.
+func ConfigCollectorProtocol(protocol string) ConfigOption {
+	return func(cfg *Config) { cfg.Collector.Protocol = protocol }
+}
+
 // ConfigCollectorTCPPort sets the collector tcp port.
 func ConfigCollectorTCPPort(tcpPort int) ConfigOption {
 	return func(cfg *Config) { cfg.Collector.TCPPort = tcpPort }
@@ -102,6 +159,27 @@ func ConfigDebugLogger(w io.Writer) ConfigOption {
 // ConfigFromEnvironment populates the config based on environment variables:
 //
 //  PINPOINT_APP_NAME                                sets AppName
+//  PINPOINT_COLLECTOR_DNS_REFRESH_INTERVAL          sets Collector.DNSRefreshInterval using time.ParseDuration
+//  PINPOINT_COLLECTOR_GRPC_AGENT_PORT               sets Collector.GRPCAgentPort using strconv.Atoi
+//  PINPOINT_COLLECTOR_GRPC_SPAN_PORT                sets Collector.GRPCSpanPort using strconv.Atoi
+//  PINPOINT_COLLECTOR_GRPC_STAT_PORT                sets Collector.GRPCStatPort using strconv.Atoi
+//  PINPOINT_COLLECTOR_HOSTS                         sets Collector.Hosts using a comma-separated list, eg. "collector1.local,collector2.local"
+//  PINPOINT_COLLECTOR_PROTOCOL                      sets Collector.Protocol, "thrift" or "grpc"
+//  PINPOINT_COLLECTOR_SEND_QUEUE_SIZE               sets Collector.SendQueueSize using strconv.Atoi
+//  PINPOINT_COLLECTOR_SEND_WORKERS                  sets Collector.SendWorkers using strconv.Atoi
+//  PINPOINT_COLLECTOR_SPAN_EVENT_MAX_COUNT          sets Collector.SpanEventMaxCount using strconv.Atoi
+//  PINPOINT_COLLECTOR_SPAN_MAX_PACKET_SIZE          sets Collector.SpanMaxPacketSize using strconv.Atoi
+//  PINPOINT_COLLECTOR_SPOOL_DIR                     sets Collector.Spool.Dir
+//  PINPOINT_COLLECTOR_SPOOL_MAX_SIZE                sets Collector.Spool.MaxSize using strconv.Atoi
+//  PINPOINT_COLLECTOR_SPOOL_TTL                     sets Collector.Spool.TTL using time.ParseDuration
+//  PINPOINT_COLLECTOR_STAT_COLLECT_INTERVAL         sets Collector.StatCollectInterval using time.ParseDuration
+//  PINPOINT_COLLECTOR_STAT_SEND_INTERVAL            sets Collector.StatSendInterval using time.ParseDuration
+//  PINPOINT_COLLECTOR_STICKY_PRIMARY                sets Collector.StickyPrimary using strconv.ParseBool
+//  PINPOINT_COLLECTOR_TLS_CA_FILE                   sets Collector.TLS.CAFile
+//  PINPOINT_COLLECTOR_TLS_CERT_FILE                 sets Collector.TLS.CertFile
+//  PINPOINT_COLLECTOR_TLS_ENABLED                   sets Collector.TLS.Enabled using strconv.ParseBool
+//  PINPOINT_COLLECTOR_TLS_KEY_FILE                  sets Collector.TLS.KeyFile
+//  PINPOINT_COLLECTOR_TLS_SERVER_NAME               sets Collector.TLS.ServerName
 //  PINPOINT_ATTRIBUTES_EXCLUDE                      sets Attributes.Exclude using a comma-separated list, eg. "request.headers.host,request.method"
 //  PINPOINT_ATTRIBUTES_INCLUDE                      sets Attributes.Include using a comma-separated list
 //  PINPOINT_DISTRIBUTED_TRACING_ENABLED             sets DistributedTracer.Enabled using strconv.ParseBool
@@ -116,6 +194,16 @@ func ConfigDebugLogger(w io.Writer) ConfigOption {
 //  PINPOINT_LOG                                     sets Logger to log to either "stdout" or "stderr" (filenames are not supported)
 //  PINPOINT_LOG_LEVEL                               controls the PINPOINT_LOG level, must be "debug" for debug, or empty for info
 //  PINPOINT_PROCESS_HOST_DISPLAY_NAME               sets HostDisplayName
+//  PINPOINT_REMOTE_ADDR_HEADER                      sets RemoteAddrHeader
+//  PINPOINT_SAMPLING_PER_MINUTE                     sets Sampling.PerMinute using strconv.Atoi
+//  PINPOINT_SAMPLING_PER_SECOND                     sets Sampling.PerSecond using strconv.Atoi
+//  PINPOINT_SAMPLING_PERCENT                        sets Sampling.Percent using strconv.ParseFloat
+//  PINPOINT_SAMPLING_RATE                           sets SamplingRate using strconv.Atoi
+//  PINPOINT_SAMPLING_RULES                          sets Sampling.Rules using a semi-colon delimited list of comma-separated matchers followed by a colon and the percent, eg. "path=/checkout:100;method=GET,path=/healthz:0"
+//  PINPOINT_SAMPLING_TAIL_BASED_ENABLED             sets Sampling.TailBased.Enabled using strconv.ParseBool
+//  PINPOINT_SAMPLING_TAIL_BASED_LATENCY_THRESHOLD   sets Sampling.TailBased.LatencyThreshold using time.ParseDuration
+//  PINPOINT_SAMPLING_TAIL_BASED_MAX_SPAN_EVENTS     sets Sampling.TailBased.MaxSpanEvents using strconv.Atoi
+//  PINPOINT_SAMPLING_TYPE                           sets Sampling.Type, "counter", "percent", "throughput" or "adaptive"
 //  PINPOINT_SECURITY_POLICIES_TOKEN                 sets SecurityPoliciesToken
 //  PINPOINT_UTILIZATION_BILLING_HOSTNAME            sets Utilization.BillingHostname
 //  PINPOINT_UTILIZATION_LOGICAL_PROCESSORS          sets Utilization.LogicalProcessors using strconv.Atoi
@@ -151,6 +239,24 @@ func configFromEnvironment(getenv func(string) string) ConfigOption {
 				}
 			}
 		}
+		assignDuration := func(field *time.Duration, name string) {
+			if env := getenv(name); env != "" {
+				if d, err := time.ParseDuration(env); nil != err {
+					cfg.Error = fmt.Errorf("invalid %s value: %s", name, env)
+				} else {
+					*field = d
+				}
+			}
+		}
+		assignFloat := func(field *float64, name string) {
+			if env := getenv(name); env != "" {
+				if f, err := strconv.ParseFloat(env, 64); nil != err {
+					cfg.Error = fmt.Errorf("invalid %s value: %s", name, env)
+				} else {
+					*field = f
+				}
+			}
+		}
 		assignString := func(field *string, name string) {
 			if env := getenv(name); env != "" {
 				*field = env
@@ -166,10 +272,39 @@ func configFromEnvironment(getenv func(string) string) ConfigOption {
 		assignInt(&cfg.Collector.TCPPort, "PINPOINT_COLLECTOR_TCP_PORT")
 		assignInt(&cfg.Collector.StatPort, "PINPOINT_COLLECTOR_STAT_PORT")
 		assignInt(&cfg.Collector.SpanPort, "PINPOINT_COLLECTOR_SPAN_PORT")
+		assignString(&cfg.Collector.Protocol, "PINPOINT_COLLECTOR_PROTOCOL")
+		assignInt(&cfg.Collector.GRPCAgentPort, "PINPOINT_COLLECTOR_GRPC_AGENT_PORT")
+		assignInt(&cfg.Collector.GRPCStatPort, "PINPOINT_COLLECTOR_GRPC_STAT_PORT")
+		assignInt(&cfg.Collector.GRPCSpanPort, "PINPOINT_COLLECTOR_GRPC_SPAN_PORT")
+		assignInt(&cfg.Collector.SendQueueSize, "PINPOINT_COLLECTOR_SEND_QUEUE_SIZE")
+		assignInt(&cfg.Collector.SendWorkers, "PINPOINT_COLLECTOR_SEND_WORKERS")
+		assignInt(&cfg.Collector.SpanEventMaxCount, "PINPOINT_COLLECTOR_SPAN_EVENT_MAX_COUNT")
+		assignInt(&cfg.Collector.SpanMaxPacketSize, "PINPOINT_COLLECTOR_SPAN_MAX_PACKET_SIZE")
+		assignDuration(&cfg.Collector.StatCollectInterval, "PINPOINT_COLLECTOR_STAT_COLLECT_INTERVAL")
+		assignDuration(&cfg.Collector.StatSendInterval, "PINPOINT_COLLECTOR_STAT_SEND_INTERVAL")
+		assignDuration(&cfg.Collector.DNSRefreshInterval, "PINPOINT_COLLECTOR_DNS_REFRESH_INTERVAL")
+		assignBool(&cfg.Collector.StickyPrimary, "PINPOINT_COLLECTOR_STICKY_PRIMARY")
+		assignBool(&cfg.Collector.TLS.Enabled, "PINPOINT_COLLECTOR_TLS_ENABLED")
+		assignString(&cfg.Collector.TLS.CAFile, "PINPOINT_COLLECTOR_TLS_CA_FILE")
+		assignString(&cfg.Collector.TLS.CertFile, "PINPOINT_COLLECTOR_TLS_CERT_FILE")
+		assignString(&cfg.Collector.TLS.KeyFile, "PINPOINT_COLLECTOR_TLS_KEY_FILE")
+		assignString(&cfg.Collector.TLS.ServerName, "PINPOINT_COLLECTOR_TLS_SERVER_NAME")
+		assignString(&cfg.Collector.Spool.Dir, "PINPOINT_COLLECTOR_SPOOL_DIR")
+		assignInt(&cfg.Collector.Spool.MaxSize, "PINPOINT_COLLECTOR_SPOOL_MAX_SIZE")
+		assignDuration(&cfg.Collector.Spool.TTL, "PINPOINT_COLLECTOR_SPOOL_TTL")
 
 		assignBool(&cfg.HighSecurity, "PINPOINT_HIGH_SECURITY")
 		assignString(&cfg.Host, "PINPOINT_HOST")
 		assignString(&cfg.HostDisplayName, "PINPOINT_PROCESS_HOST_DISPLAY_NAME")
+		assignString(&cfg.RemoteAddrHeader, "PINPOINT_REMOTE_ADDR_HEADER")
+		assignInt(&cfg.SamplingRate, "PINPOINT_SAMPLING_RATE")
+		assignString(&cfg.Sampling.Type, "PINPOINT_SAMPLING_TYPE")
+		assignFloat(&cfg.Sampling.Percent, "PINPOINT_SAMPLING_PERCENT")
+		assignInt(&cfg.Sampling.PerSecond, "PINPOINT_SAMPLING_PER_SECOND")
+		assignInt(&cfg.Sampling.PerMinute, "PINPOINT_SAMPLING_PER_MINUTE")
+		assignBool(&cfg.Sampling.TailBased.Enabled, "PINPOINT_SAMPLING_TAIL_BASED_ENABLED")
+		assignDuration(&cfg.Sampling.TailBased.LatencyThreshold, "PINPOINT_SAMPLING_TAIL_BASED_LATENCY_THRESHOLD")
+		assignInt(&cfg.Sampling.TailBased.MaxSpanEvents, "PINPOINT_SAMPLING_TAIL_BASED_MAX_SPAN_EVENTS")
 		assignInt(&cfg.InfiniteTracing.SpanEvents.QueueSize, "PINPOINT_INFINITE_TRACING_SPAN_EVENTS_QUEUE_SIZE")
 
 		//assignString(&cfg.License, "PINPOINT_LICENSE_KEY")
@@ -190,6 +325,18 @@ func configFromEnvironment(getenv func(string) string) ConfigOption {
 			}
 		}
 
+		if env := getenv("PINPOINT_SAMPLING_RULES"); env != "" {
+			if rules := getSamplingRules(env); len(rules) > 0 {
+				cfg.Sampling.Rules = rules
+			} else {
+				cfg.Error = fmt.Errorf("invalid PINPOINT_SAMPLING_RULES value: %s", env)
+			}
+		}
+
+		if env := getenv("PINPOINT_COLLECTOR_HOSTS"); env != "" {
+			cfg.Collector.Hosts = strings.Split(env, ",")
+		}
+
 		if env := getenv("PINPOINT_ATTRIBUTES_INCLUDE"); env != "" {
 			cfg.Attributes.Include = strings.Split(env, ",")
 		}
@@ -267,16 +414,98 @@ func getLabels(env string) map[string]string {
 	return out
 }
 
+// getSamplingRules reads Sampling.Rules from the env string, a semi-colon
+// delimited list of rules (for example, "path=/checkout:100;method=GET,
+// path=/healthz:0").  Each rule is a comma-separated list of name, path or
+// method matchers, followed by a colon and the percent of the matching
+// transactions sampled.  It returns nil if any rule is invalid.
+func getSamplingRules(env string) []SamplingRule {
+	var rules []SamplingRule
+	for _, entry := range strings.Split(strings.Trim(env, ";\t\n\v\f\r "), ";") {
+		colon := strings.LastIndex(entry, ":")
+		if colon < 0 {
+			return nil
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSpace(entry[colon+1:]), 64)
+		if err != nil {
+			return nil
+		}
+		rule := SamplingRule{Percent: percent}
+		for _, matcher := range strings.Split(entry[:colon], ",") {
+			eq := strings.IndexByte(matcher, '=')
+			if eq < 0 {
+				return nil
+			}
+			value := strings.TrimSpace(matcher[eq+1:])
+			switch strings.TrimSpace(matcher[:eq]) {
+			case "name":
+				rule.Name = value
+			case "path":
+				rule.Path = value
+			case "method":
+				rule.Method = value
+			default:
+				return nil
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
 type yamlConfig struct {
-	Enabled      *bool  `yaml:"enabled"`
-	AppName      string `yaml:"app_name"`
-	AgentID      string `yaml:"agent_id"`
-	SamplingRate int    `yaml:"sampling_rate"`
-	Collector    struct {
-		IP       string `yaml:"ip"`
-		TCPPort  int    `yaml:"tcp_port"`
-		StatPort int    `yaml:"stat_port"`
-		SpanPort int    `yaml:"span_port"`
+	Enabled          *bool  `yaml:"enabled"`
+	AppName          string `yaml:"app_name"`
+	AgentID          string `yaml:"agent_id"`
+	SamplingRate     int    `yaml:"sampling_rate"`
+	RemoteAddrHeader string `yaml:"remote_addr_header"`
+	Sampling         struct {
+		Type      string  `yaml:"type"`
+		Percent   float64 `yaml:"percent"`
+		PerSecond int     `yaml:"per_second"`
+		PerMinute int     `yaml:"per_minute"`
+		Rules     []struct {
+			Name    string  `yaml:"name"`
+			Path    string  `yaml:"path"`
+			Method  string  `yaml:"method"`
+			Percent float64 `yaml:"percent"`
+		} `yaml:"rules"`
+		TailBased struct {
+			Enabled          *bool         `yaml:"enabled"`
+			LatencyThreshold time.Duration `yaml:"latency_threshold"`
+			MaxSpanEvents    int           `yaml:"max_span_events"`
+		} `yaml:"tail_based"`
+	}
+	Collector struct {
+		Protocol            string        `yaml:"protocol"`
+		IP                  string        `yaml:"ip"`
+		Hosts               []string      `yaml:"hosts"`
+		DNSRefreshInterval  time.Duration `yaml:"dns_refresh_interval"`
+		StickyPrimary       *bool         `yaml:"sticky_primary"`
+		TCPPort             int           `yaml:"tcp_port"`
+		StatPort            int           `yaml:"stat_port"`
+		SpanPort            int           `yaml:"span_port"`
+		GRPCAgentPort       int           `yaml:"grpc_agent_port"`
+		GRPCStatPort        int           `yaml:"grpc_stat_port"`
+		GRPCSpanPort        int           `yaml:"grpc_span_port"`
+		SendQueueSize       int           `yaml:"send_queue_size"`
+		SendWorkers         int           `yaml:"send_workers"`
+		SpanEventMaxCount   int           `yaml:"span_event_max_count"`
+		SpanMaxPacketSize   int           `yaml:"span_max_packet_size"`
+		StatCollectInterval time.Duration `yaml:"stat_collect_interval"`
+		StatSendInterval    time.Duration `yaml:"stat_send_interval"`
+		TLS                 struct {
+			Enabled    *bool  `yaml:"enabled"`
+			CAFile     string `yaml:"ca_file"`
+			CertFile   string `yaml:"cert_file"`
+			KeyFile    string `yaml:"key_file"`
+			ServerName string `yaml:"server_name"`
+		}
+		Spool struct {
+			Dir     string        `yaml:"dir"`
+			MaxSize int           `yaml:"max_size"`
+			TTL     time.Duration `yaml:"ttl"`
+		}
 	}
 	Log struct {
 		STD   string `yaml:"std"`
@@ -325,9 +554,104 @@ func configFromYaml(data []byte, err error) ConfigOption {
 		if yc.Collector.SpanPort != 0 {
 			cfg.Collector.SpanPort = yc.Collector.SpanPort
 		}
+		if yc.Collector.Protocol != "" {
+			cfg.Collector.Protocol = yc.Collector.Protocol
+		}
+		if yc.Collector.GRPCAgentPort != 0 {
+			cfg.Collector.GRPCAgentPort = yc.Collector.GRPCAgentPort
+		}
+		if yc.Collector.GRPCStatPort != 0 {
+			cfg.Collector.GRPCStatPort = yc.Collector.GRPCStatPort
+		}
+		if yc.Collector.GRPCSpanPort != 0 {
+			cfg.Collector.GRPCSpanPort = yc.Collector.GRPCSpanPort
+		}
+		if yc.Collector.SendQueueSize != 0 {
+			cfg.Collector.SendQueueSize = yc.Collector.SendQueueSize
+		}
+		if yc.Collector.SendWorkers != 0 {
+			cfg.Collector.SendWorkers = yc.Collector.SendWorkers
+		}
+		if yc.Collector.SpanEventMaxCount != 0 {
+			cfg.Collector.SpanEventMaxCount = yc.Collector.SpanEventMaxCount
+		}
+		if yc.Collector.SpanMaxPacketSize != 0 {
+			cfg.Collector.SpanMaxPacketSize = yc.Collector.SpanMaxPacketSize
+		}
+		if len(yc.Collector.Hosts) > 0 {
+			cfg.Collector.Hosts = yc.Collector.Hosts
+		}
+		if yc.Collector.DNSRefreshInterval != 0 {
+			cfg.Collector.DNSRefreshInterval = yc.Collector.DNSRefreshInterval
+		}
+		if yc.Collector.StickyPrimary != nil {
+			cfg.Collector.StickyPrimary = *yc.Collector.StickyPrimary
+		}
+		if yc.Collector.StatCollectInterval != 0 {
+			cfg.Collector.StatCollectInterval = yc.Collector.StatCollectInterval
+		}
+		if yc.Collector.StatSendInterval != 0 {
+			cfg.Collector.StatSendInterval = yc.Collector.StatSendInterval
+		}
+		if yc.Collector.TLS.Enabled != nil {
+			cfg.Collector.TLS.Enabled = *yc.Collector.TLS.Enabled
+		}
+		if yc.Collector.TLS.CAFile != "" {
+			cfg.Collector.TLS.CAFile = yc.Collector.TLS.CAFile
+		}
+		if yc.Collector.TLS.CertFile != "" {
+			cfg.Collector.TLS.CertFile = yc.Collector.TLS.CertFile
+		}
+		if yc.Collector.TLS.KeyFile != "" {
+			cfg.Collector.TLS.KeyFile = yc.Collector.TLS.KeyFile
+		}
+		if yc.Collector.TLS.ServerName != "" {
+			cfg.Collector.TLS.ServerName = yc.Collector.TLS.ServerName
+		}
+		if yc.Collector.Spool.Dir != "" {
+			cfg.Collector.Spool.Dir = yc.Collector.Spool.Dir
+		}
+		if yc.Collector.Spool.MaxSize != 0 {
+			cfg.Collector.Spool.MaxSize = yc.Collector.Spool.MaxSize
+		}
+		if yc.Collector.Spool.TTL != 0 {
+			cfg.Collector.Spool.TTL = yc.Collector.Spool.TTL
+		}
 		if yc.SamplingRate > 0 {
 			cfg.SamplingRate = yc.SamplingRate
 		}
+		if yc.RemoteAddrHeader != "" {
+			cfg.RemoteAddrHeader = yc.RemoteAddrHeader
+		}
+		if yc.Sampling.Type != "" {
+			cfg.Sampling.Type = yc.Sampling.Type
+		}
+		if yc.Sampling.Percent != 0 {
+			cfg.Sampling.Percent = yc.Sampling.Percent
+		}
+		if yc.Sampling.PerSecond != 0 {
+			cfg.Sampling.PerSecond = yc.Sampling.PerSecond
+		}
+		if yc.Sampling.PerMinute != 0 {
+			cfg.Sampling.PerMinute = yc.Sampling.PerMinute
+		}
+		if yc.Sampling.TailBased.Enabled != nil {
+			cfg.Sampling.TailBased.Enabled = *yc.Sampling.TailBased.Enabled
+		}
+		if yc.Sampling.TailBased.LatencyThreshold != 0 {
+			cfg.Sampling.TailBased.LatencyThreshold = yc.Sampling.TailBased.LatencyThreshold
+		}
+		if yc.Sampling.TailBased.MaxSpanEvents != 0 {
+			cfg.Sampling.TailBased.MaxSpanEvents = yc.Sampling.TailBased.MaxSpanEvents
+		}
+		for _, rule := range yc.Sampling.Rules {
+			cfg.Sampling.Rules = append(cfg.Sampling.Rules, SamplingRule{
+				Name:    rule.Name,
+				Path:    rule.Path,
+				Method:  rule.Method,
+				Percent: rule.Percent,
+			})
+		}
 
 		if std := yc.Log.STD; std != "" {
 			if dest := getLogDest(std); dest != nil {
@@ -6,6 +6,7 @@ package pinpoint
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestConfigFromYaml(t *testing.T) {
@@ -13,11 +14,52 @@ func TestConfigFromYaml(t *testing.T) {
 enabled: false
 app_name: my_app
 agent_id: my_agent
+remote_addr_header: X-Forwarded-For
+sampling_rate: 10
+sampling:
+  type: throughput
+  per_second: 20
+  per_minute: 600
+  tail_based:
+    enabled: true
+    latency_threshold: 2s
+    max_span_events: 200
+  rules:
+    - path: /checkout
+      percent: 100
+    - method: GET
+      path: /healthz
+      percent: 0
 collector:
   ip: 10.10.10.10
+  hosts:
+    - collector1.local
+    - collector2.local
+  dns_refresh_interval: 30s
+  sticky_primary: true
   tcp_port: 9984
   stat_port: 9985
   span_port: 9986
+  protocol: grpc
+  grpc_agent_port: 9981
+  grpc_stat_port: 9982
+  grpc_span_port: 9983
+  send_queue_size: 4096
+  send_workers: 4
+  span_event_max_count: 100
+  span_max_packet_size: 16000
+  stat_collect_interval: 2s
+  stat_send_interval: 1m
+  tls:
+    enabled: true
+    ca_file: /etc/pinpoint/ca.pem
+    cert_file: /etc/pinpoint/agent.pem
+    key_file: /etc/pinpoint/agent-key.pem
+    server_name: collector.local
+  spool:
+    dir: /var/spool/pinpoint
+    max_size: 1048576
+    ttl: 10m
 `
 
 	cfgOpt := configFromYaml([]byte(data), nil)
@@ -28,10 +70,43 @@ collector:
 	expect.Enabled = false
 	expect.AppName = "my_app"
 	expect.AgentID = "my_agent"
+	expect.RemoteAddrHeader = "X-Forwarded-For"
+	expect.SamplingRate = 10
+	expect.Sampling.Type = SamplingTypeThroughput
+	expect.Sampling.PerSecond = 20
+	expect.Sampling.PerMinute = 600
+	expect.Sampling.TailBased.Enabled = true
+	expect.Sampling.TailBased.LatencyThreshold = 2 * time.Second
+	expect.Sampling.TailBased.MaxSpanEvents = 200
+	expect.Sampling.Rules = []SamplingRule{
+		{Path: "/checkout", Percent: 100},
+		{Method: "GET", Path: "/healthz", Percent: 0},
+	}
 	expect.Collector.IP = "10.10.10.10"
 	expect.Collector.TCPPort = 9984
 	expect.Collector.StatPort = 9985
 	expect.Collector.SpanPort = 9986
+	expect.Collector.Protocol = CollectorProtocolGRPC
+	expect.Collector.GRPCAgentPort = 9981
+	expect.Collector.GRPCStatPort = 9982
+	expect.Collector.GRPCSpanPort = 9983
+	expect.Collector.SendQueueSize = 4096
+	expect.Collector.SendWorkers = 4
+	expect.Collector.SpanEventMaxCount = 100
+	expect.Collector.SpanMaxPacketSize = 16000
+	expect.Collector.StatCollectInterval = 2 * time.Second
+	expect.Collector.StatSendInterval = time.Minute
+	expect.Collector.Hosts = []string{"collector1.local", "collector2.local"}
+	expect.Collector.DNSRefreshInterval = 30 * time.Second
+	expect.Collector.StickyPrimary = true
+	expect.Collector.TLS.Enabled = true
+	expect.Collector.TLS.CAFile = "/etc/pinpoint/ca.pem"
+	expect.Collector.TLS.CertFile = "/etc/pinpoint/agent.pem"
+	expect.Collector.TLS.KeyFile = "/etc/pinpoint/agent-key.pem"
+	expect.Collector.TLS.ServerName = "collector.local"
+	expect.Collector.Spool.Dir = "/var/spool/pinpoint"
+	expect.Collector.Spool.MaxSize = 1048576
+	expect.Collector.Spool.TTL = 10 * time.Minute
 
 	if !reflect.DeepEqual(expect, cfg) {
 		t.Errorf("cfg   : %#v", cfg)
@@ -54,6 +129,48 @@ func TestConfigFromEnvironment(t *testing.T) {
 			return "9985"
 		case "PINPOINT_COLLECTOR_SPAN_PORT":
 			return "9986"
+		case "PINPOINT_COLLECTOR_PROTOCOL":
+			return "grpc"
+		case "PINPOINT_COLLECTOR_GRPC_AGENT_PORT":
+			return "9981"
+		case "PINPOINT_COLLECTOR_GRPC_STAT_PORT":
+			return "9982"
+		case "PINPOINT_COLLECTOR_GRPC_SPAN_PORT":
+			return "9983"
+		case "PINPOINT_COLLECTOR_SEND_QUEUE_SIZE":
+			return "4096"
+		case "PINPOINT_COLLECTOR_SEND_WORKERS":
+			return "4"
+		case "PINPOINT_COLLECTOR_SPAN_EVENT_MAX_COUNT":
+			return "100"
+		case "PINPOINT_COLLECTOR_SPAN_MAX_PACKET_SIZE":
+			return "16000"
+		case "PINPOINT_COLLECTOR_STAT_COLLECT_INTERVAL":
+			return "2s"
+		case "PINPOINT_COLLECTOR_STAT_SEND_INTERVAL":
+			return "1m"
+		case "PINPOINT_COLLECTOR_HOSTS":
+			return "collector1.local,collector2.local"
+		case "PINPOINT_COLLECTOR_DNS_REFRESH_INTERVAL":
+			return "30s"
+		case "PINPOINT_COLLECTOR_STICKY_PRIMARY":
+			return "true"
+		case "PINPOINT_COLLECTOR_TLS_ENABLED":
+			return "true"
+		case "PINPOINT_COLLECTOR_TLS_CA_FILE":
+			return "/etc/pinpoint/ca.pem"
+		case "PINPOINT_COLLECTOR_TLS_CERT_FILE":
+			return "/etc/pinpoint/agent.pem"
+		case "PINPOINT_COLLECTOR_TLS_KEY_FILE":
+			return "/etc/pinpoint/agent-key.pem"
+		case "PINPOINT_COLLECTOR_TLS_SERVER_NAME":
+			return "collector.local"
+		case "PINPOINT_COLLECTOR_SPOOL_DIR":
+			return "/var/spool/pinpoint"
+		case "PINPOINT_COLLECTOR_SPOOL_MAX_SIZE":
+			return "1048576"
+		case "PINPOINT_COLLECTOR_SPOOL_TTL":
+			return "10m"
 		case "PINPOINT_DISTRIBUTED_TRACING_ENABLED":
 			return "true"
 		case "PINPOINT_ENABLED":
@@ -64,6 +181,26 @@ func TestConfigFromEnvironment(t *testing.T) {
 			return "my host"
 		case "PINPOINT_PROCESS_HOST_DISPLAY_NAME":
 			return "my display host"
+		case "PINPOINT_REMOTE_ADDR_HEADER":
+			return "X-Real-Ip"
+		case "PINPOINT_SAMPLING_RATE":
+			return "10"
+		case "PINPOINT_SAMPLING_TYPE":
+			return "percent"
+		case "PINPOINT_SAMPLING_PERCENT":
+			return "12.5"
+		case "PINPOINT_SAMPLING_PER_SECOND":
+			return "20"
+		case "PINPOINT_SAMPLING_PER_MINUTE":
+			return "600"
+		case "PINPOINT_SAMPLING_TAIL_BASED_ENABLED":
+			return "true"
+		case "PINPOINT_SAMPLING_TAIL_BASED_LATENCY_THRESHOLD":
+			return "2s"
+		case "PINPOINT_SAMPLING_TAIL_BASED_MAX_SPAN_EVENTS":
+			return "200"
+		case "PINPOINT_SAMPLING_RULES":
+			return "path=/checkout:100;method=GET,path=/healthz:0"
 		case "PINPOINT_LABELS":
 			return "star:car;far:bar"
 		case "PINPOINT_ATTRIBUTES_INCLUDE":
@@ -82,11 +219,45 @@ func TestConfigFromEnvironment(t *testing.T) {
 	expect.Collector.TCPPort = 9984
 	expect.Collector.StatPort = 9985
 	expect.Collector.SpanPort = 9986
+	expect.Collector.Protocol = CollectorProtocolGRPC
+	expect.Collector.GRPCAgentPort = 9981
+	expect.Collector.GRPCStatPort = 9982
+	expect.Collector.GRPCSpanPort = 9983
+	expect.Collector.SendQueueSize = 4096
+	expect.Collector.SendWorkers = 4
+	expect.Collector.SpanEventMaxCount = 100
+	expect.Collector.SpanMaxPacketSize = 16000
+	expect.Collector.StatCollectInterval = 2 * time.Second
+	expect.Collector.StatSendInterval = time.Minute
+	expect.Collector.Hosts = []string{"collector1.local", "collector2.local"}
+	expect.Collector.DNSRefreshInterval = 30 * time.Second
+	expect.Collector.StickyPrimary = true
+	expect.Collector.TLS.Enabled = true
+	expect.Collector.TLS.CAFile = "/etc/pinpoint/ca.pem"
+	expect.Collector.TLS.CertFile = "/etc/pinpoint/agent.pem"
+	expect.Collector.TLS.KeyFile = "/etc/pinpoint/agent-key.pem"
+	expect.Collector.TLS.ServerName = "collector.local"
+	expect.Collector.Spool.Dir = "/var/spool/pinpoint"
+	expect.Collector.Spool.MaxSize = 1048576
+	expect.Collector.Spool.TTL = 10 * time.Minute
 	expect.DistributedTracer.Enabled = true
 	expect.Enabled = false
 	expect.HighSecurity = true
 	expect.Host = "my host"
 	expect.HostDisplayName = "my display host"
+	expect.RemoteAddrHeader = "X-Real-Ip"
+	expect.SamplingRate = 10
+	expect.Sampling.Type = SamplingTypePercent
+	expect.Sampling.Percent = 12.5
+	expect.Sampling.PerSecond = 20
+	expect.Sampling.PerMinute = 600
+	expect.Sampling.TailBased.Enabled = true
+	expect.Sampling.TailBased.LatencyThreshold = 2 * time.Second
+	expect.Sampling.TailBased.MaxSpanEvents = 200
+	expect.Sampling.Rules = []SamplingRule{
+		{Path: "/checkout", Percent: 100},
+		{Method: "GET", Path: "/healthz", Percent: 0},
+	}
 	expect.Labels = map[string]string{"star": "car", "far": "bar"}
 	expect.Attributes.Include = []string{"zip", "zap"}
 	expect.Attributes.Exclude = []string{"zop", "zup", "zep"}
@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dingyalin/pinpoint-go-agent/internal"
 	"github.com/dingyalin/pinpoint-go-agent/internal/crossagent"
@@ -99,12 +100,14 @@ func TestCopyConfigReferenceFieldsPresent(t *testing.T) {
 	cfg.SpanEvents.Attributes.Exclude = append(cfg.SpanEvents.Attributes.Exclude, "12")
 	cfg.TransactionTracer.Segments.Attributes.Include = append(cfg.TransactionTracer.Segments.Attributes.Include, "13")
 	cfg.TransactionTracer.Segments.Attributes.Exclude = append(cfg.TransactionTracer.Segments.Attributes.Exclude, "14")
+	cfg.Sampling.Rules = []SamplingRule{{Path: "/healthz"}}
 	cfg.Transport = &http.Transport{}
 	cfg.Logger = NewLogger(os.Stdout)
 
 	cp := copyConfigReferenceFields(cfg)
 
 	cfg.Labels["zop"] = "zup"
+	cfg.Sampling.Rules[0].Path = "/zap"
 	cfg.ErrorCollector.IgnoreStatusCodes[0] = 201
 	cfg.Attributes.Include[0] = "zap"
 	cfg.Attributes.Exclude[0] = "zap"
@@ -282,6 +285,9 @@ func TestCopyConfigReferenceFieldsPresent(t *testing.T) {
 	if out != expect {
 		t.Error(out)
 	}
+	if len(cp.Sampling.Rules) != 1 || cp.Sampling.Rules[0].Path != "/healthz" {
+		t.Error(cp.Sampling.Rules)
+	}
 }
 
 func TestCopyConfigReferenceFieldsAbsent(t *testing.T) {
@@ -498,6 +504,111 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidateCollectorProtocol(t *testing.T) {
+	c := defaultConfig()
+	c.AppName = "my app"
+	c.AgentID = "my agent"
+	for _, protocol := range []string{"", CollectorProtocolThrift, CollectorProtocolGRPC} {
+		c.Collector.Protocol = protocol
+		if err := c.validate(); nil != err {
+			t.Error(protocol, err)
+		}
+	}
+	c.Collector.Protocol = "udp"
+	if err := c.validate(); err != errCollectorProtocol {
+		t.Error(err)
+	}
+}
+
+func TestValidateStatInterval(t *testing.T) {
+	c := defaultConfig()
+	c.AppName = "my app"
+	c.AgentID = "my agent"
+	c.Collector.StatSendInterval = 0
+	if err := c.validate(); err != errStatInterval {
+		t.Error(err)
+	}
+	c = defaultConfig()
+	c.AppName = "my app"
+	c.AgentID = "my agent"
+	c.Collector.StatCollectInterval = -time.Second
+	if err := c.validate(); err != errStatInterval {
+		t.Error(err)
+	}
+}
+
+func TestValidateTCPPingInterval(t *testing.T) {
+	c := defaultConfig()
+	c.AppName = "my app"
+	c.AgentID = "my agent"
+	c.Collector.TCPPingInterval = 0
+	if err := c.validate(); err != nil {
+		t.Error(err)
+	}
+	c.Collector.TCPPingInterval = -time.Second
+	if err := c.validate(); err != errTCPPingInterval {
+		t.Error(err)
+	}
+}
+
+func TestValidateSpoolMaxSize(t *testing.T) {
+	c := defaultConfig()
+	c.AppName = "my app"
+	c.AgentID = "my agent"
+	c.Collector.Spool.MaxSize = 0
+	if err := c.validate(); err != nil {
+		t.Error(err)
+	}
+	c.Collector.Spool.Dir = "/var/spool/pinpoint"
+	if err := c.validate(); err != errSpoolMaxSize {
+		t.Error(err)
+	}
+}
+
+func TestValidateSampling(t *testing.T) {
+	c := defaultConfig()
+	c.AppName = "my app"
+	c.AgentID = "my agent"
+	c.Sampling.Type = "random"
+	if err := c.validate(); err != errSamplingType {
+		t.Error(err)
+	}
+	c.Sampling.Type = SamplingTypePercent
+	c.Sampling.Percent = 101
+	if err := c.validate(); err != errSamplingPercent {
+		t.Error(err)
+	}
+	c.Sampling.Percent = 50
+	c.Sampling.Rules = []SamplingRule{{Path: "/healthz", Percent: -1}}
+	if err := c.validate(); err != errSamplingPercent {
+		t.Error(err)
+	}
+	c.Sampling.Rules = nil
+	if err := c.validate(); err != nil {
+		t.Error(err)
+	}
+	c.Sampling.Type = SamplingTypeThroughput
+	if err := c.validate(); err != errSamplingPerSecond {
+		t.Error(err)
+	}
+	c.Sampling.PerSecond = 10
+	if err := c.validate(); err != nil {
+		t.Error(err)
+	}
+	c.Sampling.Type = SamplingTypeAdaptive
+	if err := c.validate(); err != errSamplingPerMinute {
+		t.Error(err)
+	}
+	c.Sampling.PerMinute = 600
+	if err := c.validate(); err != nil {
+		t.Error(err)
+	}
+	c.Sampling.TailBased.MaxSpanEvents = -1
+	if err := c.validate(); err != errSamplingTailBased {
+		t.Error(err)
+	}
+}
+
 func TestValidateCalled(t *testing.T) {
 	// Test that config validation is actually done when creating an
 	// application.
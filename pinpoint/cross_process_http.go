@@ -39,6 +39,13 @@ func httpHeaderToAppData(header http.Header) string {
 	return header.Get(cat.NewRelicAppDataName)
 }
 
+// Values of the Pinpoint-Sampled header.  Callers which do not sample a
+// trace send only pinpointSampledFalse, without the other headers.
+const (
+	pinpointSampledTrue  = "s1"
+	pinpointSampledFalse = "s0"
+)
+
 // httpHeaderToMetadata gets the cross process metadata from the relevant HTTP
 // headers.
 func httpHeaderToMetadata(header http.Header) (metadata crossProcessMetadata) {
@@ -47,6 +54,7 @@ func httpHeaderToMetadata(header http.Header) (metadata crossProcessMetadata) {
 	if header == nil {
 		return
 	}
+	metadata.PinpointSampled = header.Get(cat.PinpointSampledName)
 
 	// pinpointTraceid
 	pinpointTraceid := header.Get(cat.PinpointTraceidName)
@@ -94,6 +102,8 @@ func httpHeaderToMetadata(header http.Header) (metadata crossProcessMetadata) {
 		PinpointPspanid:        pinpointPspanid,
 		PinpointSpanid:         pinpointSpanid,
 		PinpointFlags:          header.Get(cat.PinpointFlagsName),
+		PinpointSampled:        metadata.PinpointSampled,
+		PinpointHost:           header.Get(cat.PinpointHostName),
 		PinpointTraceidEncoded: pinpointTraceidEncoded,
 	}
 }
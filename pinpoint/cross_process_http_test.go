@@ -140,6 +140,23 @@ func TestHTTPHeaderToMetadata(t *testing.T) {
 	}
 }
 
+func TestHTTPHeaderToMetadataSampled(t *testing.T) {
+	hdr := http.Header{}
+	hdr.Set(cat.PinpointSampledName, "s0")
+	if metadata := httpHeaderToMetadata(hdr); metadata.PinpointSampled != "s0" || metadata.PinpointTraceid != "" {
+		t.Error(metadata)
+	}
+
+	hdr.Set(cat.PinpointSampledName, "s1")
+	hdr.Set(cat.PinpointTraceidName, "caller^1^1")
+	hdr.Set(cat.PinpointPapptypeName, "1800")
+	hdr.Set(cat.PinpointPspanidName, "-1")
+	hdr.Set(cat.PinpointSpanidName, "7")
+	if metadata := httpHeaderToMetadata(hdr); metadata.PinpointSampled != "s1" || metadata.PinpointTraceid != "caller^1^1" {
+		t.Error(metadata)
+	}
+}
+
 func TestMetadataToHTTPHeader(t *testing.T) {
 	metadata := crossProcessMetadata{}
 
@@ -0,0 +1,119 @@
+package pinpoint
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/io"
+)
+
+var (
+	// dsnPasswordPattern matches the password of data source names such as
+	// "user:password@tcp(host:3306)/db", up to the last '@' like the mysql
+	// driver.
+	dsnPasswordPattern = regexp.MustCompile(`^([^:/@\s]+:)\S*@`)
+	// keyValuePasswordPattern matches the password of data source names such
+	// as "host=db user=app password=secret".
+	keyValuePasswordPattern = regexp.MustCompile(`(?i)(\bpassword=)('[^']*'|\S+)`)
+)
+
+// dataSource is a database pool reported in the data source panel of the
+// inspector.
+type dataSource struct {
+	key          interface{}
+	id           int32
+	serviceType  int16
+	databaseName string
+	url          string
+	// connections returns the connections in use and the maximum size of
+	// the pool.
+	connections func() (active int32, max int32)
+}
+
+// dataSourceRegistry holds the registered database pools.  The zero value
+// is ready to use.
+type dataSourceRegistry struct {
+	sync.Mutex
+	sources []dataSource
+}
+
+// register adds the pool identified by key.  It returns false if the pool
+// is already registered or maxDataSources pools are.
+func (r *dataSourceRegistry) register(key interface{}, product DatastoreProduct, databaseName string, url string,
+	connections func() (int32, int32)) bool {
+	r.Lock()
+	defer r.Unlock()
+	if len(r.sources) >= maxDataSources {
+		return false
+	}
+	for _, source := range r.sources {
+		if source.key == key {
+			return false
+		}
+	}
+	r.sources = append(r.sources, dataSource{
+		key:          key,
+		id:           int32(len(r.sources) + 1),
+		serviceType:  dataSourceServiceType(product),
+		databaseName: databaseName,
+		url:          maskDataSourceURL(url),
+		connections:  connections,
+	})
+	return true
+}
+
+// sample reads the connections of the registered pools.  It returns nil if
+// no pool is registered.
+func (r *dataSourceRegistry) sample() *pinpoint.TDataSourceList {
+	r.Lock()
+	sources := r.sources
+	r.Unlock()
+	if len(sources) == 0 {
+		return nil
+	}
+
+	list := make([]*pinpoint.TDataSource, 0, len(sources))
+	for _, source := range sources {
+		source := source
+		active, max := source.connections()
+		list = append(list, &pinpoint.TDataSource{
+			ID:                   source.id,
+			ServiceTypeCode:      &source.serviceType,
+			DatabaseName:         &source.databaseName,
+			URL:                  &source.url,
+			ActiveConnectionSize: active,
+			MaxConnectionSize:    &max,
+		})
+	}
+	return &pinpoint.TDataSourceList{DataSourceList: list}
+}
+
+// maskDataSourceURL returns url with its password masked, whether it is an
+// url or a data source name.
+func maskDataSourceURL(url string) string {
+	if strings.Contains(url, "://") {
+		url = urlPasswordPattern.ReplaceAllString(url, "${1}"+maskedValue+"@")
+	} else {
+		url = dsnPasswordPattern.ReplaceAllString(url, "${1}"+maskedValue+"@")
+	}
+	return keyValuePasswordPattern.ReplaceAllString(url, "${1}"+maskedValue)
+}
+
+// dataSourceServiceType returns the service type of the databases of
+// product.
+func dataSourceServiceType(product DatastoreProduct) int16 {
+	switch product {
+	case DatastoreMySQL:
+		return io.ServiceTypeMysql
+	case DatastoreMSSQL:
+		return io.ServiceTypeMssql
+	case DatastoreOracle:
+		return io.ServiceTypeOracle
+	case DatastorePostgres:
+		return io.ServiceTypePostgresql
+	default:
+		return io.ServiceTypeUnkonwnDB
+	}
+}
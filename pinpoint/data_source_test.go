@@ -0,0 +1,68 @@
+package pinpoint
+
+import (
+	"testing"
+
+	"github.com/dingyalin/pinpoint-go-agent/thrift/io"
+)
+
+func TestDataSourceRegistry(t *testing.T) {
+	var r dataSourceRegistry
+	if list := r.sample(); nil != list {
+		t.Error(list)
+	}
+
+	connections := func() (int32, int32) { return 3, 10 }
+	if !r.register("mysql", DatastoreMySQL, "orders", "mysql://app:secret@db:3306/orders", connections) {
+		t.Fatal("mysql not registered")
+	}
+	if r.register("mysql", DatastoreMySQL, "orders", "mysql://db:3306/orders", connections) {
+		t.Error("mysql registered twice")
+	}
+	if !r.register("sqlite", DatastoreSQLite, "cache", "file:cache.db", connections) {
+		t.Fatal("sqlite not registered")
+	}
+
+	list := r.sample().DataSourceList
+	if len(list) != 2 {
+		t.Fatal(list)
+	}
+	mysql := list[0]
+	if mysql.ID != 1 || mysql.GetServiceTypeCode() != io.ServiceTypeMysql || mysql.GetDatabaseName() != "orders" ||
+		mysql.GetURL() != "mysql://app:****@db:3306/orders" || mysql.ActiveConnectionSize != 3 || mysql.GetMaxConnectionSize() != 10 {
+		t.Error(mysql)
+	}
+	if sqlite := list[1]; sqlite.ID != 2 || sqlite.GetServiceTypeCode() != io.ServiceTypeUnkonwnDB {
+		t.Error(sqlite)
+	}
+}
+
+func TestDataSourceRegistryLimit(t *testing.T) {
+	var r dataSourceRegistry
+	for i := 0; i < maxDataSources; i++ {
+		if !r.register(i, DatastorePostgres, "db", "", func() (int32, int32) { return 0, 0 }) {
+			t.Fatal(i)
+		}
+	}
+	if r.register(maxDataSources, DatastorePostgres, "db", "", func() (int32, int32) { return 0, 0 }) {
+		t.Error("registered past the limit")
+	}
+	if list := r.sample().DataSourceList; len(list) != maxDataSources || list[0].GetServiceTypeCode() != io.ServiceTypePostgresql {
+		t.Error(len(list))
+	}
+}
+
+func TestMaskDataSourceURL(t *testing.T) {
+	for url, want := range map[string]string{
+		"postgres://app:secret@db:5432/orders?sslmode=disable": "postgres://app:****@db:5432/orders?sslmode=disable",
+		"app:s3cr@t@tcp(db:3306)/orders":                       "app:****@tcp(db:3306)/orders",
+		"host=db user=app password='a b' dbname=orders":        "host=db user=app password=**** dbname=orders",
+		"host=db user=app Password=secret":                     "host=db user=app Password=****",
+		"app@tcp(db:3306)/orders":                              "app@tcp(db:3306)/orders",
+		"file:cache.db?mode=ro":                                "file:cache.db?mode=ro",
+	} {
+		if got := maskDataSourceURL(url); got != want {
+			t.Errorf("%s: got %s, want %s", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,466 @@
+// +build go1.9
+// This build tag is necessary because GRPC/ProtoBuf libraries only support Go version 1.9 and up.
+
+package pinpoint
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+
+	pb "github.com/dingyalin/pinpoint-go-agent/internal/pinpoint_grpc_v1"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
+)
+
+const (
+	// versionSupportsGRPCCollector records whether we are using a
+	// supported version of Go for the grpc collector protocol
+	versionSupportsGRPCCollector = true
+)
+
+// gRPC metadata keys identifying the agent to the collector.
+const (
+	grpcHeaderAgentID         = "agentid"
+	grpcHeaderApplicationName = "applicationname"
+	grpcHeaderServiceType     = "servicetype"
+	grpcHeaderStartTime       = "starttime"
+	grpcHeaderSocketID        = "socketid"
+)
+
+// grpcEndpointsScheme names the resolver passing the collector addresses to
+// the connections of a client with endpoints.
+const grpcEndpointsScheme = "pinpoint"
+
+// grpcClient sends agent data to the gRPC services of Pinpoint 2.x
+// collectors.  Spans and stats are written to client streams which are
+// opened on first use and again after a failed send.  A ping session keeps
+// the agent known to the collector.
+type grpcClient struct {
+	grpcClientConfig
+
+	// ctx carries the agent metadata and is cancelled by close.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	agentConn *grpc.ClientConn
+	statConn  *grpc.ClientConn
+	spanConn  *grpc.ClientConn
+	agent     pb.AgentClient
+	metadata  pb.MetadataClient
+	// resolvers update the connections when the endpoints are resolved to
+	// other addresses.
+	resolvers []endpointsResolver
+
+	// streamMu protects the span and stat streams.
+	streamMu   sync.Mutex
+	spanStream pb.Span_SendSpanClient
+	statStream pb.Stat_SendAgentStatClient
+
+	// socketID and pingAttempts are only used by the keepAlive goroutine.
+	socketID     int32
+	pingAttempts int
+}
+
+func newGRPCClient(cfg grpcClientConfig) (collectorClient, error) {
+	dialOptions := []grpc.DialOption{
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoff.Config{
+				BaseDelay:  1 * time.Second,
+				Multiplier: 2,
+				MaxDelay:   60 * time.Second,
+			},
+		}),
+	}
+	if nil != cfg.tlsConfig {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(cfg.tlsConfig)))
+	} else {
+		dialOptions = append(dialOptions, grpc.WithInsecure())
+	}
+	if nil != cfg.dialer {
+		dialOptions = append(dialOptions, grpc.WithContextDialer(cfg.dialer))
+	}
+
+	client := &grpcClient{grpcClientConfig: cfg}
+	var err error
+	if client.agentConn, err = client.dial(cfg.agentAddress, cfg.agentPort, dialOptions); nil != err {
+		return nil, err
+	}
+	if client.statConn, err = client.dial(cfg.statAddress, cfg.statPort, dialOptions); nil != err {
+		client.agentConn.Close()
+		return nil, err
+	}
+	if client.spanConn, err = client.dial(cfg.spanAddress, cfg.spanPort, dialOptions); nil != err {
+		client.agentConn.Close()
+		client.statConn.Close()
+		return nil, err
+	}
+	client.agent = pb.NewAgentClient(client.agentConn)
+	client.metadata = pb.NewMetadataClient(client.agentConn)
+
+	md := metadata.Pairs(
+		grpcHeaderAgentID, cfg.agentInfo.AgentId,
+		grpcHeaderApplicationName, cfg.agentInfo.ApplicationName,
+		grpcHeaderServiceType, strconv.Itoa(int(cfg.agentInfo.ServiceType)),
+		grpcHeaderStartTime, strconv.FormatInt(cfg.agentInfo.StartTimestamp, 10),
+	)
+	client.ctx, client.cancel = context.WithCancel(metadata.NewOutgoingContext(context.Background(), md))
+	return client, nil
+}
+
+// endpointsResolver passes the collector addresses on port to a connection.
+// The connection fails over between them in order.  Each address carries the
+// host it was resolved from as its server name, which TLS verifies the
+// collector certificate against.
+type endpointsResolver struct {
+	*manual.Resolver
+	endpoints *collectorEndpoints
+	port      int
+}
+
+func (r endpointsResolver) update(addrs []string) {
+	r.UpdateState(r.state(addrs))
+}
+
+func (r endpointsResolver) state(addrs []string) resolver.State {
+	state := resolver.State{}
+	for _, addr := range addrs {
+		state.Addresses = append(state.Addresses, resolver.Address{
+			Addr:       net.JoinHostPort(addr, strconv.Itoa(r.port)),
+			ServerName: r.endpoints.hostOf(addr),
+		})
+	}
+	return state
+}
+
+// dial connects to address, or to the endpoints on port if the client has
+// endpoints.
+func (client *grpcClient) dial(address string, port int, dialOptions []grpc.DialOption) (*grpc.ClientConn, error) {
+	if nil == client.endpoints {
+		return grpc.Dial(address, dialOptions...)
+	}
+
+	r := endpointsResolver{
+		Resolver:  manual.NewBuilderWithScheme(grpcEndpointsScheme),
+		endpoints: client.endpoints,
+		port:      port,
+	}
+	r.InitialState(r.state(client.endpoints.addresses()))
+	conn, err := grpc.Dial(grpcEndpointsScheme+":///collector", append(dialOptions, grpc.WithResolvers(r))...)
+	if nil != err {
+		return nil, err
+	}
+	client.resolvers = append(client.resolvers, r)
+	return conn, nil
+}
+
+// refreshEndpoints resolves the endpoints every refresh interval and passes
+// changed addresses to the connections until done is closed.
+func (client *grpcClient) refreshEndpoints(done <-chan struct{}) {
+	if nil == client.endpoints || client.endpoints.refreshInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(client.endpoints.refreshInterval)
+	defer ticker.Stop()
+
+	// The addresses may have changed since the connections were dialed, so
+	// the first resolution is always passed on.
+	var addrs []string
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			resolved := client.endpoints.addresses()
+			if reflect.DeepEqual(addrs, resolved) {
+				continue
+			}
+			client.log.Info("collector addresses changed", map[string]interface{}{
+				"addresses": resolved,
+			})
+			addrs = resolved
+			for _, r := range client.resolvers {
+				r.update(addrs)
+			}
+		}
+	}
+}
+
+// RequestTStruct sends agent info and api metadata with unary calls.  Calls
+// which fail or are rejected are sent again up to requestRetries times.
+func (client *grpcClient) RequestTStruct(ttype uint16, tstruct thrift.TStruct) error {
+	if !client.uploaded {
+		return nil
+	}
+
+	var err error
+	for attempt := 0; attempt <= client.requestRetries; attempt++ {
+		err = client.request(ttype, tstruct)
+		if err == nil {
+			return nil
+		}
+		client.log.Debug("grpc request failed", map[string]interface{}{
+			"type":    ttype,
+			"attempt": attempt,
+			"err":     err.Error(),
+		})
+	}
+	return err
+}
+
+func (client *grpcClient) request(ttype uint16, tstruct thrift.TStruct) error {
+	ctx, cancel := context.WithTimeout(client.ctx, client.requestTimeout)
+	defer cancel()
+
+	var result *pb.PResult
+	var err error
+	switch v := tstruct.(type) {
+	case *pinpoint.TAgentInfo:
+		result, err = client.agent.RequestAgentInfo(ctx, toPAgentInfo(v))
+	case *trace.TApiMetaData:
+		result, err = client.metadata.RequestApiMetaData(ctx, toPApiMetaData(v))
+	case *trace.TSqlMetaData:
+		result, err = client.metadata.RequestSqlMetaData(ctx, toPSqlMetaData(v))
+	case *trace.TStringMetaData:
+		result, err = client.metadata.RequestStringMetaData(ctx, toPStringMetaData(v))
+	default:
+		return fmt.Errorf("tstruct type %d not supported by the grpc collector", ttype)
+	}
+	if err != nil {
+		return err
+	}
+	if !result.GetSuccess() {
+		return fmt.Errorf("grpc request rejected: %s", result.GetMessage())
+	}
+	return nil
+}
+
+// SendSpan implements collectorClient.
+func (client *grpcClient) SendSpan(tstruct thrift.TStruct) error {
+	tspan, ok := tstruct.(*trace.TSpan)
+	if !ok {
+		return fmt.Errorf("unexpected span %T", tstruct)
+	}
+	pspan, err := toPSpan(tspan)
+	if err != nil {
+		return err
+	}
+	return client.sendSpanMessage(&pb.PSpanMessage{
+		Field: &pb.PSpanMessage_Span{Span: pspan},
+	})
+}
+
+// SendSpanChunk implements collectorClient.
+func (client *grpcClient) SendSpanChunk(tstruct thrift.TStruct) error {
+	tspanChunk, ok := tstruct.(*trace.TSpanChunk)
+	if !ok {
+		return fmt.Errorf("unexpected span chunk %T", tstruct)
+	}
+	pspanChunk, err := toPSpanChunk(tspanChunk)
+	if err != nil {
+		return err
+	}
+	return client.sendSpanMessage(&pb.PSpanMessage{
+		Field: &pb.PSpanMessage_SpanChunk{SpanChunk: pspanChunk},
+	})
+}
+
+func (client *grpcClient) sendSpanMessage(message *pb.PSpanMessage) error {
+	if !client.uploaded {
+		return nil
+	}
+
+	client.streamMu.Lock()
+	defer client.streamMu.Unlock()
+
+	if nil == client.spanStream {
+		stream, err := pb.NewSpanClient(client.spanConn).SendSpan(client.ctx)
+		if err != nil {
+			return err
+		}
+		client.spanStream = stream
+	}
+	err := client.spanStream.Send(message)
+	if err != nil {
+		// The status of a broken stream is only reported by its
+		// response.
+		if _, recvErr := client.spanStream.CloseAndRecv(); nil != recvErr {
+			err = recvErr
+		}
+		client.spanStream = nil
+	}
+	return err
+}
+
+// SendAgentStat implements collectorClient.
+func (client *grpcClient) SendAgentStat(tstruct thrift.TStruct) error {
+	tagentStat, ok := tstruct.(*pinpoint.TAgentStat)
+	if !ok {
+		return fmt.Errorf("unexpected agent stat %T", tstruct)
+	}
+	return client.sendStatMessage(&pb.PStatMessage{
+		Field: &pb.PStatMessage_AgentStat{AgentStat: toPAgentStat(tagentStat)},
+	})
+}
+
+// SendAgentStatBatch implements collectorClient.
+func (client *grpcClient) SendAgentStatBatch(tstruct thrift.TStruct) error {
+	tagentStatBatch, ok := tstruct.(*pinpoint.TAgentStatBatch)
+	if !ok {
+		return fmt.Errorf("unexpected agent stat batch %T", tstruct)
+	}
+	return client.sendStatMessage(&pb.PStatMessage{
+		Field: &pb.PStatMessage_AgentStatBatch{AgentStatBatch: toPAgentStatBatch(tagentStatBatch)},
+	})
+}
+
+func (client *grpcClient) sendStatMessage(message *pb.PStatMessage) error {
+	if !client.uploaded {
+		return nil
+	}
+
+	client.streamMu.Lock()
+	defer client.streamMu.Unlock()
+
+	if nil == client.statStream {
+		stream, err := pb.NewStatClient(client.statConn).SendAgentStat(client.ctx)
+		if err != nil {
+			return err
+		}
+		client.statStream = stream
+	}
+	err := client.statStream.Send(message)
+	if err != nil {
+		if _, recvErr := client.statStream.CloseAndRecv(); nil != recvErr {
+			err = recvErr
+		}
+		client.statStream = nil
+	}
+	return err
+}
+
+// keepAlive runs the ping session until done is closed, opening a new
+// session with a backoff whenever the current one fails.
+func (client *grpcClient) keepAlive(done <-chan struct{}) {
+	defer client.close()
+
+	if !client.uploaded {
+		<-done
+		return
+	}
+
+	go client.refreshEndpoints(done)
+	for {
+		err := client.pingSession(done)
+		if nil == err {
+			return
+		}
+		client.log.Warn("grpc ping session failed", map[string]interface{}{
+			"address": client.agentAddress,
+			"err":     err.Error(),
+		})
+
+		select {
+		case <-done:
+			return
+		case <-time.After(tcpReconnectBackoff(client.pingAttempts)):
+		}
+		client.pingAttempts++
+	}
+}
+
+// pingSession opens a ping session and pings the collector until done is
+// closed, in which case nil is returned, or the session fails.
+func (client *grpcClient) pingSession(done <-chan struct{}) error {
+	client.socketID++
+	ctx := metadata.AppendToOutgoingContext(client.ctx, grpcHeaderSocketID, strconv.Itoa(int(client.socketID)))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.agent.PingSession(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The session is established once the collector answers the first
+	// ping.
+	if err := stream.Send(&pb.PPing{}); err != nil {
+		return err
+	}
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+	client.log.Debug("grpc ping session established", map[string]interface{}{
+		"address":  client.agentAddress,
+		"socketId": client.socketID,
+	})
+	client.pingAttempts = 0
+	if client.socketID > 1 && nil != client.reconnectHandler {
+		go client.reconnectHandler()
+	}
+
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			if _, err := stream.Recv(); err != nil {
+				recvErr <- err
+				return
+			}
+		}
+	}()
+
+	// Like the tcp keepalive, an interval of zero disables the pings: the
+	// nil channel never ticks.
+	var tick <-chan time.Time
+	if client.pingInterval > 0 {
+		ticker := time.NewTicker(client.pingInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-done:
+			stream.CloseSend()
+			return nil
+		case err := <-recvErr:
+			return err
+		case <-tick:
+			if err := stream.Send(&pb.PPing{}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// close flushes the span and stat streams and closes the connections.
+func (client *grpcClient) close() {
+	client.streamMu.Lock()
+	if nil != client.spanStream {
+		client.spanStream.CloseAndRecv()
+		client.spanStream = nil
+	}
+	if nil != client.statStream {
+		client.statStream.CloseAndRecv()
+		client.statStream = nil
+	}
+	client.streamMu.Unlock()
+
+	client.cancel()
+	client.agentConn.Close()
+	client.statConn.Close()
+	client.spanConn.Close()
+}
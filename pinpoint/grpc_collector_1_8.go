@@ -0,0 +1,13 @@
+// +build !go1.9
+
+package pinpoint
+
+const (
+	// versionSupportsGRPCCollector records whether we are using a
+	// supported version of Go for the grpc collector protocol
+	versionSupportsGRPCCollector = false
+)
+
+func newGRPCClient(cfg grpcClientConfig) (collectorClient, error) {
+	return nil, errGRPCCollectorUnsupportedVersion
+}
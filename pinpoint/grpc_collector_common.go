@@ -0,0 +1,46 @@
+package pinpoint
+
+import (
+	"crypto/tls"
+	"errors"
+	"time"
+
+	"github.com/dingyalin/pinpoint-go-agent/internal"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
+)
+
+type grpcClientConfig struct {
+	// agentAddress serves the agent and metadata services, statAddress
+	// the stat service and spanAddress the span service.
+	agentAddress string
+	statAddress  string
+	spanAddress  string
+	// endpoints, if set, replaces the addresses above by every collector
+	// address on agentPort, statPort and spanPort.
+	endpoints *collectorEndpoints
+	agentPort int
+	statPort  int
+	spanPort  int
+	uploaded  bool
+	// tlsConfig, if set, secures the connections.
+	tlsConfig *tls.Config
+	// pingInterval is the interval between pings on the ping session.
+	pingInterval time.Duration
+	// requestTimeout bounds every agent info and metadata request, and
+	// requestRetries is the number of times a failed request is sent again.
+	requestTimeout time.Duration
+	requestRetries int
+	// agentInfo identifies the agent in the metadata of every call.
+	agentInfo *pinpoint.TAgentInfo
+	// reconnectHandler is called after a lost ping session has been
+	// established again.
+	reconnectHandler func()
+	log              Logger
+
+	// dialer is only used for testing - it allows the client to connect
+	// directly to an in-memory gRPC server
+	dialer internal.DialerFunc
+}
+
+var errGRPCCollectorUnsupportedVersion = errors.New("non supported Go version - to use the grpc collector " +
+	"protocol, you must use at least version 1.9 or higher of Go")
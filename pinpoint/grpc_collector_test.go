@@ -0,0 +1,609 @@
+// +build go1.9
+// This build tag is necessary because GRPC/ProtoBuf libraries only support Go version 1.9 and up.
+
+package pinpoint
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/dingyalin/pinpoint-go-agent/internal/logger"
+	pb "github.com/dingyalin/pinpoint-go-agent/internal/pinpoint_grpc_v1"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
+	tio "github.com/dingyalin/pinpoint-go-agent/thrift/io"
+)
+
+// fakeGRPCCollector is an in-memory collector serving the agent, metadata,
+// span and stat services.
+type fakeGRPCCollector struct {
+	server   *grpc.Server
+	listener *bufconn.Listener
+
+	agentInfos      chan *pb.PAgentInfo
+	apiMetaDatas    chan *pb.PApiMetaData
+	sqlMetaDatas    chan *pb.PSqlMetaData
+	stringMetaDatas chan *pb.PStringMetaData
+	spans           chan *pb.PSpanMessage
+	stats           chan *pb.PStatMessage
+	sessions        chan metadata.MD
+
+	sync.Mutex
+	// result answers requests, every request succeeds when it is nil.
+	result func() *pb.PResult
+	// endSession ends the current ping session when closed.
+	endSession chan struct{}
+}
+
+func newFakeGRPCCollector(t *testing.T, opts ...grpc.ServerOption) *fakeGRPCCollector {
+	fc := &fakeGRPCCollector{
+		server:          grpc.NewServer(opts...),
+		listener:        bufconn.Listen(1024 * 1024),
+		agentInfos:      make(chan *pb.PAgentInfo, 10),
+		apiMetaDatas:    make(chan *pb.PApiMetaData, 10),
+		sqlMetaDatas:    make(chan *pb.PSqlMetaData, 10),
+		stringMetaDatas: make(chan *pb.PStringMetaData, 10),
+		spans:           make(chan *pb.PSpanMessage, 10),
+		stats:           make(chan *pb.PStatMessage, 10),
+		sessions:        make(chan metadata.MD, 10),
+		endSession:      make(chan struct{}),
+	}
+	pb.RegisterAgentServer(fc.server, fc)
+	pb.RegisterMetadataServer(fc.server, fc)
+	pb.RegisterSpanServer(fc.server, fc)
+	pb.RegisterStatServer(fc.server, fc)
+	go fc.server.Serve(fc.listener)
+	return fc
+}
+
+func (fc *fakeGRPCCollector) dialer(ctx context.Context, s string) (net.Conn, error) {
+	return fc.listener.Dial()
+}
+
+func (fc *fakeGRPCCollector) Close() {
+	fc.server.Stop()
+}
+
+func (fc *fakeGRPCCollector) response() *pb.PResult {
+	fc.Lock()
+	defer fc.Unlock()
+	if nil != fc.result {
+		return fc.result()
+	}
+	return &pb.PResult{Success: true}
+}
+
+func (fc *fakeGRPCCollector) RequestAgentInfo(ctx context.Context, in *pb.PAgentInfo) (*pb.PResult, error) {
+	fc.agentInfos <- in
+	return fc.response(), nil
+}
+
+func (fc *fakeGRPCCollector) PingSession(stream pb.Agent_PingSessionServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	fc.sessions <- md
+
+	fc.Lock()
+	endSession := fc.endSession
+	fc.Unlock()
+
+	pings := make(chan error, 1)
+	go func() {
+		for {
+			if _, err := stream.Recv(); err != nil {
+				pings <- err
+				return
+			}
+			stream.Send(&pb.PPing{})
+		}
+	}()
+	select {
+	case <-endSession:
+		return nil
+	case <-pings:
+		return nil
+	}
+}
+
+func (fc *fakeGRPCCollector) RequestSqlMetaData(ctx context.Context, in *pb.PSqlMetaData) (*pb.PResult, error) {
+	fc.sqlMetaDatas <- in
+	return fc.response(), nil
+}
+
+func (fc *fakeGRPCCollector) RequestApiMetaData(ctx context.Context, in *pb.PApiMetaData) (*pb.PResult, error) {
+	fc.apiMetaDatas <- in
+	return fc.response(), nil
+}
+
+func (fc *fakeGRPCCollector) RequestStringMetaData(ctx context.Context, in *pb.PStringMetaData) (*pb.PResult, error) {
+	fc.stringMetaDatas <- in
+	return fc.response(), nil
+}
+
+func (fc *fakeGRPCCollector) SendSpan(stream pb.Span_SendSpanServer) error {
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&empty.Empty{})
+		}
+		if err != nil {
+			return err
+		}
+		fc.spans <- message
+	}
+}
+
+func (fc *fakeGRPCCollector) SendAgentStat(stream pb.Stat_SendAgentStatServer) error {
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&empty.Empty{})
+		}
+		if err != nil {
+			return err
+		}
+		fc.stats <- message
+	}
+}
+
+func newTestGRPCClient(t *testing.T, fc *fakeGRPCCollector) *grpcClient {
+	client, err := newGRPCClient(grpcClientConfig{
+		agentAddress:   "bufnet",
+		statAddress:    "bufnet",
+		spanAddress:    "bufnet",
+		uploaded:       true,
+		pingInterval:   time.Hour,
+		requestTimeout: time.Second,
+		requestRetries: 2,
+		agentInfo: &pinpoint.TAgentInfo{
+			AgentId:         "agent",
+			ApplicationName: "app",
+			ServiceType:     tio.ServiceTypeGo,
+			StartTimestamp:  1234,
+		},
+		log:    logger.ShimLogger{},
+		dialer: fc.dialer,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.(*grpcClient)
+}
+
+func TestGRPCClientRequestAgentInfo(t *testing.T) {
+	fc := newFakeGRPCCollector(t)
+	defer fc.Close()
+	client := newTestGRPCClient(t, fc)
+	defer client.close()
+
+	serverInfo, serviceName := "Go", serviceKindHTTP
+	err := client.RequestTStruct(tio.TTypeAgentInfo, &pinpoint.TAgentInfo{
+		Hostname:    "host",
+		IP:          "10.0.0.1",
+		ServiceType: tio.ServiceTypeGo,
+		Pid:         42,
+		ServerMetaData: &pinpoint.TServerMetaData{
+			ServerInfo:   &serverInfo,
+			VmArgs_:      []string{"/usr/bin/server"},
+			ServiceInfos: []*pinpoint.TServiceInfo{{ServiceName: &serviceName, ServiceLibs: []string{"/users"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentInfo := <-fc.agentInfos
+	if agentInfo.Hostname != "host" || agentInfo.Ip != "10.0.0.1" ||
+		agentInfo.ServiceType != int32(tio.ServiceTypeGo) || agentInfo.Pid != 42 {
+		t.Error(agentInfo)
+	}
+	metaData := agentInfo.GetServerMetaData()
+	if metaData.GetServerInfo() != serverInfo || len(metaData.GetVmArg()) != 1 || len(metaData.GetServiceInfo()) != 1 ||
+		metaData.GetServiceInfo()[0].GetServiceName() != serviceName || metaData.GetServiceInfo()[0].GetServiceLib()[0] != "/users" {
+		t.Error(metaData)
+	}
+}
+
+func TestGRPCClientRequestRetry(t *testing.T) {
+	fc := newFakeGRPCCollector(t)
+	defer fc.Close()
+	client := newTestGRPCClient(t, fc)
+	defer client.close()
+
+	requests := 0
+	fc.result = func() *pb.PResult {
+		requests++
+		return &pb.PResult{Success: requests > 1, Message: "busy"}
+	}
+
+	err := client.RequestTStruct(tio.TTypeAPIMetadata, &trace.TApiMetaData{ApiId: 7, ApiInfo: "main.handler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first := <-fc.apiMetaDatas; first.ApiId != 7 || first.ApiInfo != "main.handler" {
+		t.Error(first)
+	}
+	<-fc.apiMetaDatas
+
+	client.requestRetries = 0
+	fc.result = func() *pb.PResult { return &pb.PResult{Message: "invalid"} }
+	err = client.RequestTStruct(tio.TTypeAPIMetadata, &trace.TApiMetaData{ApiId: 8})
+	if err == nil || err.Error() != "grpc request rejected: invalid" {
+		t.Error(err)
+	}
+}
+
+func TestGRPCClientRequestSQLMetaData(t *testing.T) {
+	fc := newFakeGRPCCollector(t)
+	defer fc.Close()
+	client := newTestGRPCClient(t, fc)
+	defer client.close()
+
+	err := client.RequestTStruct(tio.TTypeSQLMetadata, &trace.TSqlMetaData{SqlId: 3, Sql: "SELECT * FROM t WHERE id = 0#"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql := <-fc.sqlMetaDatas; sql.SqlId != 3 || sql.Sql != "SELECT * FROM t WHERE id = 0#" {
+		t.Error(sql)
+	}
+}
+
+func TestGRPCClientRequestStringMetaData(t *testing.T) {
+	fc := newFakeGRPCCollector(t)
+	defer fc.Close()
+	client := newTestGRPCClient(t, fc)
+	defer client.close()
+
+	err := client.RequestTStruct(tio.TTypeStringMetadata, &trace.TStringMetaData{StringId: 2, StringValue: "*url.Error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if str := <-fc.stringMetaDatas; str.StringId != 2 || str.StringValue != "*url.Error" {
+		t.Error(str)
+	}
+}
+
+func TestGRPCClientSendSpan(t *testing.T) {
+	fc := newFakeGRPCCollector(t)
+	defer fc.Close()
+	client := newTestGRPCClient(t, fc)
+	defer client.close()
+
+	sql := "SELECT 1"
+	endPoint := "localhost:8080"
+	apiID := int32(3)
+	err := client.SendSpan(&trace.TSpan{
+		TransactionId: encodeTraceID("agent", 1234, 5),
+		SpanId:        11,
+		ParentSpanId:  -1,
+		ServiceType:   tio.ServiceTypeGo,
+		EndPoint:      &endPoint,
+		ApiId:         &apiID,
+		SpanEventList: []*trace.TSpanEvent{{
+			Sequence:    0,
+			Depth:       1,
+			ServiceType: 2101,
+			NextSpanId:  -1,
+			Annotations: []*trace.TAnnotation{{
+				Key:   21,
+				Value: &trace.TAnnotationValue{StringValue: &sql},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	span := (<-fc.spans).GetSpan()
+	if span == nil {
+		t.Fatal("span not received")
+	}
+	tid := span.GetTransactionId()
+	if tid.GetAgentId() != "agent" || tid.GetAgentStartTime() != 1234 || tid.GetSequence() != 5 {
+		t.Error(tid)
+	}
+	if span.SpanId != 11 || span.ParentSpanId != -1 || span.ApiId != 3 ||
+		span.GetAcceptEvent().GetEndPoint() != endPoint {
+		t.Error(span)
+	}
+	events := span.GetSpanEvent()
+	if len(events) != 1 || events[0].Depth != 1 || events[0].NextEvent != nil ||
+		events[0].Annotation[0].GetValue().GetStringValue() != sql {
+		t.Error(events)
+	}
+
+	err = client.SendSpanChunk(&trace.TSpanChunk{
+		TransactionId: encodeTraceID("agent", 1234, 5),
+		SpanId:        11,
+		SpanEventList: []*trace.TSpanEvent{{Sequence: 1, NextSpanId: -1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunk := (<-fc.spans).GetSpanChunk(); chunk == nil || chunk.SpanId != 11 || len(chunk.SpanEvent) != 1 {
+		t.Error(chunk)
+	}
+}
+
+func TestGRPCClientSendAgentStat(t *testing.T) {
+	fc := newFakeGRPCCollector(t)
+	defer fc.Close()
+	client := newTestGRPCClient(t, fc)
+	defer client.close()
+
+	timestamp := int64(1000)
+	systemCPULoad := 0.25
+	gcCount, p99 := int64(3), 1.5
+	serviceType, maxConnections := int16(2100), int32(10)
+	err := client.SendAgentStat(&pinpoint.TAgentStat{
+		Timestamp: &timestamp,
+		Gc: &pinpoint.TJvmGc{
+			JvmMemoryHeapUsed: 64,
+			JvmGcDetailed:     &pinpoint.TJvmGcDetailed{JvmGcNewCount: &gcCount, JvmPoolMetaspaceUsed: &p99},
+		},
+		CpuLoad:      &pinpoint.TCpuLoad{SystemCpuLoad: &systemCPULoad},
+		Transaction:  &pinpoint.TTransaction{SampledNewCount: &gcCount},
+		ActiveTrace:  &pinpoint.TActiveTrace{Histogram: &pinpoint.TActiveTraceHistogram{ActiveTraceCount: []int32{1, 0, 2, 0}}},
+		ResponseTime: &pinpoint.TResponseTime{Avg: 12, Max: 30},
+		DataSourceList: &pinpoint.TDataSourceList{DataSourceList: []*pinpoint.TDataSource{
+			{ID: 1, ServiceTypeCode: &serviceType, ActiveConnectionSize: 2, MaxConnectionSize: &maxConnections},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat := (<-fc.stats).GetAgentStat()
+	if stat.GetTimestamp() != 1000 || stat.GetGc().GetJvmMemoryHeapUsed() != 64 ||
+		stat.GetGc().GetJvmGcDetailed().GetJvmGcNewCount() != 3 || stat.GetGc().GetJvmGcDetailed().GetJvmPoolMetaspaceUsed() != 1.5 ||
+		stat.GetCpuLoad().GetSystemCpuLoad() != 0.25 || stat.GetTransaction().GetSampledNewCount() != 3 ||
+		!reflect.DeepEqual(stat.GetActiveTrace().GetHistogram().GetActiveTraceCount(), []int32{1, 0, 2, 0}) ||
+		stat.GetResponseTime().GetAvg() != 12 || stat.GetResponseTime().GetMax() != 30 {
+		t.Error(stat)
+	}
+	if dataSources := stat.GetDataSourceList().GetDataSource(); len(dataSources) != 1 || dataSources[0].GetId() != 1 ||
+		dataSources[0].GetServiceTypeCode() != 2100 || dataSources[0].GetActiveConnectionSize() != 2 ||
+		dataSources[0].GetMaxConnectionSize() != 10 {
+		t.Error(dataSources)
+	}
+
+	later := int64(2000)
+	err = client.SendAgentStatBatch(&pinpoint.TAgentStatBatch{
+		AgentId:    "agent",
+		AgentStats: []*pinpoint.TAgentStat{{Timestamp: &timestamp}, {Timestamp: &later}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := (<-fc.stats).GetAgentStatBatch().GetAgentStat()
+	if len(batch) != 2 || batch[0].GetTimestamp() != 1000 || batch[1].GetTimestamp() != 2000 {
+		t.Error(batch)
+	}
+}
+
+func TestGRPCClientPingSession(t *testing.T) {
+	fc := newFakeGRPCCollector(t)
+	defer fc.Close()
+	client := newTestGRPCClient(t, fc)
+	reconnected := make(chan struct{}, 1)
+	client.reconnectHandler = func() { reconnected <- struct{}{} }
+
+	done := make(chan struct{})
+	defer close(done)
+	go client.keepAlive(done)
+
+	md := <-fc.sessions
+	if md.Get(grpcHeaderAgentID)[0] != "agent" ||
+		md.Get(grpcHeaderApplicationName)[0] != "app" ||
+		md.Get(grpcHeaderStartTime)[0] != "1234" ||
+		md.Get(grpcHeaderSocketID)[0] != "1" {
+		t.Error(md)
+	}
+
+	// A session ended by the collector is opened again.
+	fc.Lock()
+	close(fc.endSession)
+	fc.endSession = make(chan struct{})
+	fc.Unlock()
+
+	select {
+	case md = <-fc.sessions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ping session not reopened")
+	}
+	if id := md.Get(grpcHeaderSocketID)[0]; id != "2" {
+		t.Error(id)
+	}
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Error("reconnect handler not called")
+	}
+}
+
+func TestGRPCClientPingSessionZeroInterval(t *testing.T) {
+	fc := newFakeGRPCCollector(t)
+	defer fc.Close()
+	client := newTestGRPCClient(t, fc)
+	client.pingInterval = 0
+
+	done := make(chan struct{})
+	ended := make(chan error, 1)
+	go func() { ended <- client.pingSession(done) }()
+
+	select {
+	case <-fc.sessions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ping session not opened")
+	}
+	close(done)
+	select {
+	case err := <-ended:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("ping session not ended")
+	}
+}
+
+func TestGRPCClientEndpoints(t *testing.T) {
+	fc := newFakeGRPCCollector(t)
+	defer fc.Close()
+
+	var mu sync.Mutex
+	resolved := "10.0.0.1"
+	dialed := make(map[string]bool)
+	endpoints := newCollectorEndpoints([]string{"collector.local"}, 10*time.Millisecond)
+	endpoints.lookupHost = func(host string) ([]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return []string{resolved}, nil
+	}
+
+	client, err := newGRPCClient(grpcClientConfig{
+		endpoints:      endpoints,
+		agentPort:      9991,
+		statPort:       9992,
+		spanPort:       9993,
+		uploaded:       true,
+		pingInterval:   time.Hour,
+		requestTimeout: time.Second,
+		agentInfo:      &pinpoint.TAgentInfo{AgentId: "agent", ApplicationName: "app"},
+		log:            logger.ShimLogger{},
+		dialer: func(ctx context.Context, address string) (net.Conn, error) {
+			mu.Lock()
+			dialed[address] = true
+			mu.Unlock()
+			return fc.dialer(ctx, address)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RequestTStruct(tio.TTypeAgentInfo, &pinpoint.TAgentInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	waitDialed := func(want string) {
+		for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); {
+			mu.Lock()
+			ok := dialed[want]
+			mu.Unlock()
+			if ok {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal(want, "not dialed")
+	}
+	waitDialed("10.0.0.1:9991")
+
+	// A collector moved to another address is followed.
+	mu.Lock()
+	resolved = "10.0.0.2"
+	mu.Unlock()
+
+	done := make(chan struct{})
+	defer close(done)
+	go client.keepAlive(done)
+	waitDialed("10.0.0.2:9991")
+}
+
+func TestGRPCClientTLS(t *testing.T) {
+	tc := newTestCertificates(t)
+	defer tc.Close()
+	fc := newFakeGRPCCollector(t, grpc.Creds(credentials.NewTLS(tc.server)))
+	defer fc.Close()
+
+	cfg := tc.config()
+	cfg.Collector.TLS.ServerName = "collector.local"
+	tlsConfig, err := cfg.collectorTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newClient := func(tlsConfig *tls.Config) *grpcClient {
+		client, err := newGRPCClient(grpcClientConfig{
+			agentAddress:   "bufnet",
+			statAddress:    "bufnet",
+			spanAddress:    "bufnet",
+			tlsConfig:      tlsConfig,
+			uploaded:       true,
+			pingInterval:   time.Hour,
+			requestTimeout: time.Second,
+			agentInfo:      &pinpoint.TAgentInfo{AgentId: "agent", ApplicationName: "app"},
+			log:            logger.ShimLogger{},
+			dialer:         fc.dialer,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client.(*grpcClient)
+	}
+
+	client := newClient(tlsConfig)
+	defer client.close()
+	if err := client.RequestTStruct(tio.TTypeAgentInfo, &pinpoint.TAgentInfo{Hostname: "host"}); err != nil {
+		t.Fatal(err)
+	}
+	if agentInfo := <-fc.agentInfos; agentInfo.Hostname != "host" {
+		t.Error(agentInfo)
+	}
+
+	// A client without a certificate is refused by the collector.
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.Certificates = nil
+	anonymous := newClient(tlsConfig)
+	defer anonymous.close()
+	if err := anonymous.RequestTStruct(tio.TTypeAgentInfo, &pinpoint.TAgentInfo{}); err == nil {
+		t.Error("request without a client certificate accepted")
+	}
+}
+
+func TestDecodeTraceID(t *testing.T) {
+	agentID, startTime, sequence, err := decodeTraceID(encodeTraceID("my-agent", 1600000000000, 300))
+	if err != nil || agentID != "my-agent" || startTime != 1600000000000 || sequence != 300 {
+		t.Error(agentID, startTime, sequence, err)
+	}
+	if _, _, _, err := decodeTraceID([]byte{0, 20, 'a'}); err != errTraceIDInvalid {
+		t.Error(err)
+	}
+}
+
+func TestSetPinpointClientProtocol(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Logger = logger.ShimLogger{}
+	cfg.Collector.Protocol = CollectorProtocolGRPC
+	app := &app{config: config{Config: cfg}, Logger: cfg.Logger}
+	app.setPinpointClient()
+	client, ok := app.pinpointClient.(*grpcClient)
+	if !ok {
+		t.Fatalf("%T", app.pinpointClient)
+	}
+	client.close()
+	if client.agentAddress != "127.0.0.1:9991" || client.statAddress != "127.0.0.1:9992" ||
+		client.spanAddress != "127.0.0.1:9993" {
+		t.Error(client.agentAddress, client.statAddress, client.spanAddress)
+	}
+	// the grpc collector sends no thread dump commands
+	if app.activeTxns.trackGoroutines {
+		t.Error("goroutines tracked")
+	}
+
+	app.config.Collector.Protocol = CollectorProtocolThrift
+	app.setPinpointClient()
+	if _, ok := app.pinpointClient.(*PinpointClient); !ok {
+		t.Errorf("%T", app.pinpointClient)
+	}
+	if !app.activeTxns.trackGoroutines {
+		t.Error("goroutines not tracked")
+	}
+}
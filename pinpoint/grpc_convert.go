@@ -0,0 +1,344 @@
+// +build go1.9
+// This build tag is necessary because GRPC/ProtoBuf libraries only support Go version 1.9 and up.
+
+package pinpoint
+
+import (
+	"github.com/golang/protobuf/ptypes/wrappers"
+
+	pb "github.com/dingyalin/pinpoint-go-agent/internal/pinpoint_grpc_v1"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
+)
+
+// This file converts the thrift structs built by the agent into the
+// messages of the gRPC collector api.
+
+func toPAgentInfo(tagentInfo *pinpoint.TAgentInfo) *pb.PAgentInfo {
+	return &pb.PAgentInfo{
+		Hostname:       tagentInfo.Hostname,
+		Ip:             tagentInfo.IP,
+		Ports:          tagentInfo.Ports,
+		ServiceType:    int32(tagentInfo.ServiceType),
+		Pid:            tagentInfo.Pid,
+		AgentVersion:   tagentInfo.AgentVersion,
+		VmVersion:      tagentInfo.VmVersion,
+		EndTimestamp:   tagentInfo.GetEndTimestamp(),
+		EndStatus:      tagentInfo.GetEndStatus(),
+		ServerMetaData: toPServerMetaData(tagentInfo.ServerMetaData),
+	}
+}
+
+func toPServerMetaData(tserverMetaData *pinpoint.TServerMetaData) *pb.PServerMetaData {
+	if nil == tserverMetaData {
+		return nil
+	}
+	serviceInfos := make([]*pb.PServiceInfo, 0, len(tserverMetaData.ServiceInfos))
+	for _, info := range tserverMetaData.ServiceInfos {
+		serviceInfos = append(serviceInfos, &pb.PServiceInfo{
+			ServiceName: info.GetServiceName(),
+			ServiceLib:  info.ServiceLibs,
+		})
+	}
+	return &pb.PServerMetaData{
+		ServerInfo:  tserverMetaData.GetServerInfo(),
+		VmArg:       tserverMetaData.VmArgs_,
+		ServiceInfo: serviceInfos,
+	}
+}
+
+func toPApiMetaData(tapiMetaData *trace.TApiMetaData) *pb.PApiMetaData {
+	return &pb.PApiMetaData{
+		ApiId:   tapiMetaData.ApiId,
+		ApiInfo: tapiMetaData.ApiInfo,
+		Line:    tapiMetaData.GetLine(),
+		Type:    tapiMetaData.GetType(),
+	}
+}
+
+func toPSqlMetaData(tsqlMetaData *trace.TSqlMetaData) *pb.PSqlMetaData {
+	return &pb.PSqlMetaData{
+		SqlId: tsqlMetaData.SqlId,
+		Sql:   tsqlMetaData.Sql,
+	}
+}
+
+func toPStringMetaData(tstringMetaData *trace.TStringMetaData) *pb.PStringMetaData {
+	return &pb.PStringMetaData{
+		StringId:    tstringMetaData.StringId,
+		StringValue: tstringMetaData.StringValue,
+	}
+}
+
+func toPTransactionID(encoded []byte) (*pb.PTransactionId, error) {
+	agentID, startTime, sequence, err := decodeTraceID(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PTransactionId{
+		AgentId:        agentID,
+		AgentStartTime: startTime,
+		Sequence:       sequence,
+	}, nil
+}
+
+func toPSpan(tspan *trace.TSpan) (*pb.PSpan, error) {
+	transactionID, err := toPTransactionID(tspan.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	acceptEvent := &pb.PAcceptEvent{
+		Rpc:        tspan.GetRPC(),
+		EndPoint:   tspan.GetEndPoint(),
+		RemoteAddr: tspan.GetRemoteAddr(),
+	}
+	if tspan.IsSetParentApplicationName() {
+		acceptEvent.ParentInfo = &pb.PParentInfo{
+			ParentApplicationName: tspan.GetParentApplicationName(),
+			ParentApplicationType: int32(tspan.GetParentApplicationType()),
+			AcceptorHost:          tspan.GetAcceptorHost(),
+		}
+	}
+
+	return &pb.PSpan{
+		Version:                int32(tspan.Version),
+		TransactionId:          transactionID,
+		SpanId:                 tspan.SpanId,
+		ParentSpanId:           tspan.ParentSpanId,
+		StartTime:              tspan.StartTime,
+		Elapsed:                tspan.Elapsed,
+		ApiId:                  tspan.GetApiId(),
+		ServiceType:            int32(tspan.ServiceType),
+		AcceptEvent:            acceptEvent,
+		Annotation:             toPAnnotations(tspan.Annotations),
+		Flag:                   int32(tspan.Flag),
+		Err:                    tspan.GetErr(),
+		SpanEvent:              toPSpanEvents(tspan.SpanEventList),
+		ExceptionInfo:          toPIntStringValue(tspan.ExceptionInfo),
+		ApplicationServiceType: int32(tspan.GetApplicationServiceType()),
+		LoggingTransactionInfo: int32(tspan.GetLoggingTransactionInfo()),
+	}, nil
+}
+
+func toPSpanChunk(tspanChunk *trace.TSpanChunk) (*pb.PSpanChunk, error) {
+	transactionID, err := toPTransactionID(tspanChunk.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	pspanChunk := &pb.PSpanChunk{
+		Version:                int32(tspanChunk.Version),
+		TransactionId:          transactionID,
+		SpanId:                 tspanChunk.SpanId,
+		EndPoint:               tspanChunk.GetEndPoint(),
+		SpanEvent:              toPSpanEvents(tspanChunk.SpanEventList),
+		ApplicationServiceType: int32(tspanChunk.GetApplicationServiceType()),
+		KeyTime:                tspanChunk.GetKeyTime(),
+	}
+	// Thrift carries the async id on every event of an async chunk, gRPC
+	// once on the chunk.
+	if len(tspanChunk.SpanEventList) > 0 && tspanChunk.SpanEventList[0].IsSetAsyncId() {
+		first := tspanChunk.SpanEventList[0]
+		pspanChunk.LocalAsyncId = &pb.PLocalAsyncId{
+			AsyncId:  first.GetAsyncId(),
+			Sequence: int32(first.GetAsyncSequence()),
+		}
+	}
+	return pspanChunk, nil
+}
+
+func toPSpanEvents(tspanEvents []*trace.TSpanEvent) []*pb.PSpanEvent {
+	if len(tspanEvents) == 0 {
+		return nil
+	}
+	pspanEvents := make([]*pb.PSpanEvent, 0, len(tspanEvents))
+	for _, tspanEvent := range tspanEvents {
+		pspanEvent := &pb.PSpanEvent{
+			Sequence:      int32(tspanEvent.Sequence),
+			Depth:         tspanEvent.Depth,
+			StartElapsed:  tspanEvent.StartElapsed,
+			EndElapsed:    tspanEvent.EndElapsed,
+			ServiceType:   int32(tspanEvent.ServiceType),
+			Annotation:    toPAnnotations(tspanEvent.Annotations),
+			ApiId:         tspanEvent.GetApiId(),
+			ExceptionInfo: toPIntStringValue(tspanEvent.ExceptionInfo),
+			AsyncEvent:    tspanEvent.GetNextAsyncId(),
+		}
+		if tspanEvent.NextSpanId != -1 || tspanEvent.IsSetEndPoint() || tspanEvent.IsSetDestinationId() {
+			pspanEvent.NextEvent = &pb.PNextEvent{
+				Field: &pb.PNextEvent_MessageEvent{
+					MessageEvent: &pb.PMessageEvent{
+						NextSpanId:    tspanEvent.NextSpanId,
+						EndPoint:      tspanEvent.GetEndPoint(),
+						DestinationId: tspanEvent.GetDestinationId(),
+					},
+				},
+			}
+		}
+		pspanEvents = append(pspanEvents, pspanEvent)
+	}
+	return pspanEvents
+}
+
+func toPAnnotations(tannotations []*trace.TAnnotation) []*pb.PAnnotation {
+	if len(tannotations) == 0 {
+		return nil
+	}
+	pannotations := make([]*pb.PAnnotation, 0, len(tannotations))
+	for _, tannotation := range tannotations {
+		pannotations = append(pannotations, &pb.PAnnotation{
+			Key:   tannotation.Key,
+			Value: toPAnnotationValue(tannotation.Value),
+		})
+	}
+	return pannotations
+}
+
+func toPAnnotationValue(value *trace.TAnnotationValue) *pb.PAnnotationValue {
+	if nil == value {
+		return nil
+	}
+
+	pvalue := &pb.PAnnotationValue{}
+	switch {
+	case value.IsSetStringValue():
+		pvalue.Field = &pb.PAnnotationValue_StringValue{StringValue: value.GetStringValue()}
+	case value.IsSetBoolValue():
+		pvalue.Field = &pb.PAnnotationValue_BoolValue{BoolValue: value.GetBoolValue()}
+	case value.IsSetIntValue():
+		pvalue.Field = &pb.PAnnotationValue_IntValue{IntValue: value.GetIntValue()}
+	case value.IsSetLongValue():
+		pvalue.Field = &pb.PAnnotationValue_LongValue{LongValue: value.GetLongValue()}
+	case value.IsSetShortValue():
+		pvalue.Field = &pb.PAnnotationValue_ShortValue{ShortValue: int32(value.GetShortValue())}
+	case value.IsSetDoubleValue():
+		pvalue.Field = &pb.PAnnotationValue_DoubleValue{DoubleValue: value.GetDoubleValue()}
+	case value.IsSetBinaryValue():
+		pvalue.Field = &pb.PAnnotationValue_BinaryValue{BinaryValue: value.GetBinaryValue()}
+	case value.IsSetByteValue():
+		pvalue.Field = &pb.PAnnotationValue_ByteValue{ByteValue: int32(value.GetByteValue())}
+	case value.IsSetIntStringValue():
+		pvalue.Field = &pb.PAnnotationValue_IntStringValue{IntStringValue: toPIntStringValue(value.IntStringValue)}
+	case value.IsSetIntStringStringValue():
+		v := value.IntStringStringValue
+		pvalue.Field = &pb.PAnnotationValue_IntStringStringValue{
+			IntStringStringValue: &pb.PIntStringStringValue{
+				IntValue:     v.IntValue,
+				StringValue1: toStringValue(v.StringValue1),
+				StringValue2: toStringValue(v.StringValue2),
+			},
+		}
+	}
+	return pvalue
+}
+
+func toPIntStringValue(value *trace.TIntStringValue) *pb.PIntStringValue {
+	if nil == value {
+		return nil
+	}
+	return &pb.PIntStringValue{
+		IntValue:    value.IntValue,
+		StringValue: toStringValue(value.StringValue),
+	}
+}
+
+func toStringValue(s *string) *wrappers.StringValue {
+	if nil == s {
+		return nil
+	}
+	return &wrappers.StringValue{Value: *s}
+}
+
+func toPAgentStat(tagentStat *pinpoint.TAgentStat) *pb.PAgentStat {
+	pagentStat := &pb.PAgentStat{
+		Timestamp:       tagentStat.GetTimestamp(),
+		CollectInterval: tagentStat.GetCollectInterval(),
+		Metadata:        tagentStat.GetMetadata(),
+	}
+	if gc := tagentStat.Gc; nil != gc {
+		pagentStat.Gc = &pb.PJvmGc{
+			Type:                 pb.PJvmGcType(gc.Type),
+			JvmMemoryHeapUsed:    gc.JvmMemoryHeapUsed,
+			JvmMemoryHeapMax:     gc.JvmMemoryHeapMax,
+			JvmMemoryNonHeapUsed: gc.JvmMemoryNonHeapUsed,
+			JvmMemoryNonHeapMax:  gc.JvmMemoryNonHeapMax,
+			JvmGcOldCount:        gc.JvmGcOldCount,
+			JvmGcOldTime:         gc.JvmGcOldTime,
+			JvmGcDetailed:        toPJvmGcDetailed(gc.JvmGcDetailed),
+		}
+	}
+	if cpuLoad := tagentStat.CpuLoad; nil != cpuLoad {
+		pagentStat.CpuLoad = &pb.PCpuLoad{
+			JvmCpuLoad:    cpuLoad.GetJvmCpuLoad(),
+			SystemCpuLoad: cpuLoad.GetSystemCpuLoad(),
+		}
+	}
+	if transaction := tagentStat.Transaction; nil != transaction {
+		pagentStat.Transaction = &pb.PTransaction{
+			SampledNewCount:            transaction.GetSampledNewCount(),
+			SampledContinuationCount:   transaction.GetSampledContinuationCount(),
+			UnsampledNewCount:          transaction.GetUnsampledNewCount(),
+			UnsampledContinuationCount: transaction.GetUnsampledContinuationCount(),
+		}
+	}
+	if activeTrace := tagentStat.ActiveTrace; nil != activeTrace && nil != activeTrace.Histogram {
+		histogram := activeTrace.Histogram
+		pagentStat.ActiveTrace = &pb.PActiveTrace{
+			Histogram: &pb.PActiveTraceHistogram{
+				Version:             int32(histogram.Version),
+				HistogramSchemaType: histogram.GetHistogramSchemaType(),
+				ActiveTraceCount:    histogram.ActiveTraceCount,
+			},
+		}
+	}
+	if dataSourceList := tagentStat.DataSourceList; nil != dataSourceList {
+		pagentStat.DataSourceList = toPDataSourceList(dataSourceList)
+	}
+	if responseTime := tagentStat.ResponseTime; nil != responseTime {
+		pagentStat.ResponseTime = &pb.PResponseTime{
+			Avg: responseTime.Avg,
+			Max: responseTime.Max,
+		}
+	}
+	return pagentStat
+}
+
+func toPJvmGcDetailed(detailed *pinpoint.TJvmGcDetailed) *pb.PJvmGcDetailed {
+	if nil == detailed {
+		return nil
+	}
+	return &pb.PJvmGcDetailed{
+		JvmGcNewCount:            detailed.GetJvmGcNewCount(),
+		JvmGcNewTime:             detailed.GetJvmGcNewTime(),
+		JvmPoolCodeCacheUsed:     detailed.GetJvmPoolCodeCacheUsed(),
+		JvmPoolNewGenUsed:        detailed.GetJvmPoolNewGenUsed(),
+		JvmPoolOldGenUsed:        detailed.GetJvmPoolOldGenUsed(),
+		JvmPoolSurvivorSpaceUsed: detailed.GetJvmPoolSurvivorSpaceUsed(),
+		JvmPoolPermGenUsed:       detailed.GetJvmPoolPermGenUsed(),
+		JvmPoolMetaspaceUsed:     detailed.GetJvmPoolMetaspaceUsed(),
+	}
+}
+
+func toPDataSourceList(dataSourceList *pinpoint.TDataSourceList) *pb.PDataSourceList {
+	dataSources := make([]*pb.PDataSource, 0, len(dataSourceList.DataSourceList))
+	for _, dataSource := range dataSourceList.DataSourceList {
+		dataSources = append(dataSources, &pb.PDataSource{
+			Id:                   dataSource.ID,
+			ServiceTypeCode:      int32(dataSource.GetServiceTypeCode()),
+			DatabaseName:         dataSource.GetDatabaseName(),
+			Url:                  dataSource.GetURL(),
+			ActiveConnectionSize: dataSource.ActiveConnectionSize,
+			MaxConnectionSize:    dataSource.GetMaxConnectionSize(),
+		})
+	}
+	return &pb.PDataSourceList{DataSource: dataSources}
+}
+
+func toPAgentStatBatch(tagentStatBatch *pinpoint.TAgentStatBatch) *pb.PAgentStatBatch {
+	pagentStats := make([]*pb.PAgentStat, 0, len(tagentStatBatch.AgentStats))
+	for _, tagentStat := range tagentStatBatch.AgentStats {
+		pagentStats = append(pagentStats, toPAgentStat(tagentStat))
+	}
+	return &pb.PAgentStatBatch{AgentStat: pagentStats}
+}
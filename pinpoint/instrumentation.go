@@ -32,6 +32,7 @@ func WrapHandle(app *Application, pattern string, handler http.Handler) (string,
 	if app == nil {
 		return pattern, handler
 	}
+	app.app.RecordService(serviceKindHTTP, pattern)
 	return pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		txn := app.StartTransaction(r.Method + " " + pattern)
 		defer txn.End()
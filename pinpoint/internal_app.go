@@ -12,12 +12,14 @@ import (
 	"net/http"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/dingyalin/pinpoint-go-agent/internal"
 	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
 	tio "github.com/dingyalin/pinpoint-go-agent/thrift/io"
 )
 
@@ -34,8 +36,10 @@ type app struct {
 
 	startTime int64
 
-	pinpointClient PinpointClient
+	pinpointClient collectorClient
 	tagentInfo     pinpoint.TAgentInfo
+	// sendQueue sends spans and agent stats to the pinpointClient.
+	sendQueue *sendQueue
 
 	trObserver traceObserver
 
@@ -54,6 +58,11 @@ type app struct {
 	// merge the data into the next harvest.
 	shutdownStarted  chan struct{}
 	shutdownComplete chan struct{}
+	// closeCollector is closed by the processor goroutine once the final
+	// data has been sent, and collectorClosed once the collector client has
+	// closed its connections in response.
+	closeCollector  chan struct{}
+	collectorClosed chan struct{}
 
 	// Sends to these channels should not occur without a <-shutdownStarted
 	// select option to prevent deadlock.
@@ -61,9 +70,34 @@ type app struct {
 	collectorErrorChan chan rpmResponse
 	connectChan        chan *appRun
 	collectInterval    int64
-
-	apiID          int32
-	apiMetaDataMap map[string]int32
+	// gcStats keeps the previous memory sample of the agent stats.  It is
+	// only used by the processor goroutine.
+	gcStats gcStats
+	// containerStats samples the cgroup of the process, nil outside
+	// containers.  It is only used by the processor goroutine.
+	containerStats *containerStats
+	// txnCounts are the transactions counted and responseTimes the
+	// response times summed for the next agent stat.
+	txnCounts     transactionCounts
+	responseTimes responseTimes
+
+	// apiMetaData, sqlMetaData and stringMetaData hold the ids of the
+	// metadata registered with the collector by the sendQueue workers.
+	apiMetaData    metaDataCache
+	sqlMetaData    metaDataCache
+	stringMetaData metaDataCache
+
+	// sampler decides which transactions starting a new trace are sampled.
+	sampler Sampler
+
+	// activeTxns are the transactions in flight, counted by the active
+	// thread commands.
+	activeTxns activeTransactions
+	// services are the entry points of the application reported in the
+	// server metadata.
+	services serviceRegistry
+	// dataSources are the database pools reported in the agent stats.
+	dataSources dataSourceRegistry
 
 	// This mutex protects both `run` and `err`, both of which should only
 	// be accessed using getState and setState.
@@ -132,19 +166,166 @@ func (app *app) doHarvest(h *harvest, harvestStart time.Time, run *appRun) {
 
 func (app *app) setPinpointClient() {
 	collector := app.config.Collector
-	app.pinpointClient.uploaded = collector.Uploaded
-	app.pinpointClient.tcpAddress = fmt.Sprintf("%s:%d", collector.IP, collector.TCPPort)
-	app.pinpointClient.statAddress = fmt.Sprintf("%s:%d", collector.IP, collector.StatPort)
-	app.pinpointClient.spanAddress = fmt.Sprintf("%s:%d", collector.IP, collector.SpanPort)
-	app.pinpointClient.logger = app.Logger
+	hosts := collector.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{collector.IP}
+	}
+	endpoints := newCollectorEndpoints(hosts, collector.DNSRefreshInterval)
+	primary := hosts[0]
+
+	if collector.Protocol == CollectorProtocolGRPC {
+		client, err := newGRPCClient(grpcClientConfig{
+			agentAddress:     net.JoinHostPort(primary, strconv.Itoa(collector.GRPCAgentPort)),
+			statAddress:      net.JoinHostPort(primary, strconv.Itoa(collector.GRPCStatPort)),
+			spanAddress:      net.JoinHostPort(primary, strconv.Itoa(collector.GRPCSpanPort)),
+			endpoints:        endpoints,
+			agentPort:        collector.GRPCAgentPort,
+			statPort:         collector.GRPCStatPort,
+			spanPort:         collector.GRPCSpanPort,
+			tlsConfig:        app.config.collectorTLS,
+			uploaded:         collector.Uploaded,
+			pingInterval:     collector.TCPPingInterval,
+			requestTimeout:   collector.TCPRequestTimeout,
+			requestRetries:   collector.TCPRequestRetryCount,
+			agentInfo:        &app.tagentInfo,
+			reconnectHandler: app.sendAgentInfo,
+			log:              app.Logger,
+		})
+		if nil == err {
+			app.pinpointClient = client
+			if "" != collector.Spool.Dir {
+				app.Warn("the grpc collector protocol does not support the spool", map[string]interface{}{
+					"dir": collector.Spool.Dir,
+				})
+			}
+			app.Info("pinpoint grpc client", map[string]interface{}{
+				"hosts":     hosts,
+				"agentPort": collector.GRPCAgentPort,
+				"statPort":  collector.GRPCStatPort,
+				"spanPort":  collector.GRPCSpanPort,
+				"tls":       nil != app.config.collectorTLS,
+			})
+			return
+		}
+		app.Error("unable to create pinpoint grpc client, no data is uploaded", map[string]interface{}{
+			"err": err.Error(),
+		})
+		collector.Uploaded = false
+	}
+
+	pinpointClient := &PinpointClient{
+		uploaded:            collector.Uploaded,
+		tcpAddress:          net.JoinHostPort(primary, strconv.Itoa(collector.TCPPort)),
+		statAddress:         net.JoinHostPort(primary, strconv.Itoa(collector.StatPort)),
+		spanAddress:         net.JoinHostPort(primary, strconv.Itoa(collector.SpanPort)),
+		endpoints:           endpoints,
+		stickyPrimary:       collector.StickyPrimary,
+		tcpPort:             collector.TCPPort,
+		statPort:            collector.StatPort,
+		spanPort:            collector.SpanPort,
+		tlsConfig:           app.config.collectorTLS,
+		tcpConnTimeout:      collector.TCPConnTimeout,
+		tcpPingInterval:     collector.TCPPingInterval,
+		tcpRequestTimeout:   collector.TCPRequestTimeout,
+		tcpRequestRetries:   collector.TCPRequestRetryCount,
+		handshakeProperties: app.handshakeProperties(),
+		reconnectHandler:    app.sendAgentInfo,
+		commands:            newCommandDispatcher(&app.activeTxns, app.Logger),
+		logger:              app.Logger,
+	}
+	app.pinpointClient = pinpointClient
+	// The thread dump commands need the goroutine of the transactions.
+	app.activeTxns.trackGoroutines = true
+
+	if "" != collector.Spool.Dir {
+		spool, err := newDiskSpool(collector.Spool.Dir, int64(collector.Spool.MaxSize), collector.Spool.TTL, app.Logger)
+		if nil == err {
+			pinpointClient.spool = spool
+			app.Info("pinpoint spool", map[string]interface{}{
+				"dir":     collector.Spool.Dir,
+				"packets": spool.len(),
+			})
+		} else {
+			app.Error("unable to open the spool, no packets are spooled", map[string]interface{}{
+				"dir": collector.Spool.Dir,
+				"err": err.Error(),
+			})
+		}
+	}
 
 	app.Info("pinpoint client", map[string]interface{}{
-		"pinpointClient": fmt.Sprintf("%#v", app.pinpointClient),
+		"hosts":       hosts,
+		"tcpAddress":  pinpointClient.tcpAddress,
+		"statAddress": pinpointClient.statAddress,
+		"spanAddress": pinpointClient.spanAddress,
+		"tls":         nil != pinpointClient.tlsConfig,
 	})
 
-	if !app.pinpointClient.uploaded {
+	if !pinpointClient.uploaded {
 		app.Warn("pinpoint client set uploaded false", map[string]interface{}{
-			"uploaded": app.pinpointClient.uploaded,
+			"uploaded": pinpointClient.uploaded,
+		})
+	}
+}
+
+// handshakeProperties describes this agent in the tcp control handshake.
+func (app *app) handshakeProperties() map[string]interface{} {
+	agentInfo := app.tagentInfo
+	return map[string]interface{}{
+		handshakeHostName:        agentInfo.Hostname,
+		handshakeIP:              agentInfo.IP,
+		handshakeAgentID:         agentInfo.AgentId,
+		handshakeApplicationName: agentInfo.ApplicationName,
+		handshakeServiceType:     int32(agentInfo.ServiceType),
+		handshakePid:             agentInfo.Pid,
+		handshakeVersion:         agentInfo.AgentVersion,
+		handshakeStartTimestamp:  agentInfo.StartTimestamp,
+		// The collector forwards the commands of the pinpoint web
+		// only to agents supporting them.
+		handshakeSupportServer:      true,
+		handshakeSupportCommandList: supportedCommands(),
+	}
+}
+
+// agentEndStatusShutdown is the agent life cycle state reported in the final
+// agent info of an agent which shut down normally.
+const agentEndStatusShutdown int32 = 200
+
+// sendAgentEnd sends the agent info with the end timestamp and status to the
+// collector, waiting at most timeout for it to be acknowledged.
+func (app *app) sendAgentEnd(timeout time.Duration) {
+	agentInfo := app.agentInfo()
+	endTimestamp := time.Now().UnixNano() / 1e6
+	endStatus := agentEndStatusShutdown
+	agentInfo.EndTimestamp = &endTimestamp
+	agentInfo.EndStatus = &endStatus
+
+	sent := make(chan error, 1)
+	go func() {
+		sent <- app.pinpointClient.RequestTStruct(tio.TTypeAgentInfo, agentInfo)
+	}()
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case err := <-sent:
+		if err != nil {
+			app.Warn("sendAgentEnd failed", map[string]interface{}{
+				"err": err,
+			})
+		}
+	case <-t.C:
+		app.Warn("sendAgentEnd timeout exceeded", map[string]interface{}{
+			"timeout": timeout.String(),
+		})
+	}
+}
+
+// sendAgentInfo sends the agent info to the collector.
+func (app *app) sendAgentInfo() {
+	err := app.pinpointClient.RequestTStruct(tio.TTypeAgentInfo, app.agentInfo())
+	if err != nil {
+		app.Warn("sendAgentInfo failed", map[string]interface{}{
+			"err": err,
 		})
 	}
 }
@@ -152,7 +333,7 @@ func (app *app) setPinpointClient() {
 func (app *app) connectAttempt() (*internal.ConnectReply, rpmResponse) {
 	resp := rpmResponse{}
 
-	err := app.pinpointClient.SendTCPTStruct(tio.TTypeAgentInfo, &app.tagentInfo)
+	err := app.pinpointClient.RequestTStruct(tio.TTypeAgentInfo, app.agentInfo())
 	if err != nil {
 		resp.Err = err
 		return nil, resp
@@ -229,7 +410,7 @@ func (app *app) setTAgentInfo() {
 		StartTimestamp:  app.startTime,
 		EndTimestamp:    nil, // nil
 		EndStatus:       nil, // nil
-		ServerMetaData:  nil, // nil
+		ServerMetaData:  nil, // filled in by agentInfo
 		JvmInfo: &pinpoint.TJvmInfo{
 			VmVersion: &vmVersion,
 		},
@@ -309,8 +490,13 @@ func (app *app) process() {
 	var h *harvest
 	var run *appRun
 
-	agentStatTicker := time.NewTicker(time.Duration(app.collectInterval) * time.Millisecond)
+	// Agent stats are sampled every collect interval and sent in batches
+	// every send interval.
+	var agentStats []*pinpoint.TAgentStat
+	agentStatTicker := time.NewTicker(app.config.Collector.StatCollectInterval)
 	defer agentStatTicker.Stop()
+	agentStatBatchTicker := time.NewTicker(app.config.Collector.StatSendInterval)
+	defer agentStatBatchTicker.Stop()
 	agentInfoTicker := time.NewTicker(time.Hour)
 	defer agentInfoTicker.Stop()
 
@@ -319,22 +505,15 @@ func (app *app) process() {
 		case <-agentStatTicker.C:
 			// agent stat
 			if nil != run && app.config.Collector.UploadedAgentStat {
-				err := app.sendAgentStat()
-				if err != nil {
-					app.Warn("sendAgentStat failed", map[string]interface{}{
-						"err": err,
-					})
-				}
+				agentStats = append(agentStats, app.collectAgentStat())
 			}
+		case <-agentStatBatchTicker.C:
+			app.sendAgentStatBatch(agentStats)
+			agentStats = nil
 		case <-agentInfoTicker.C:
 			// agent info
 			if nil != run {
-				err := app.pinpointClient.SendTCPTStruct(tio.TTypeAgentInfo, &app.tagentInfo)
-				if err != nil {
-					app.Warn("sendAgentInfo failed", map[string]interface{}{
-						"err": err,
-					})
-				}
+				go app.sendAgentInfo()
 			}
 		case d := <-app.dataChan:
 			// span
@@ -343,6 +522,7 @@ func (app *app) process() {
 			}
 		case timeout := <-app.initiateShutdown:
 			close(app.shutdownStarted)
+			deadline := time.Now().Add(timeout)
 
 			// Remove the run before merging any final data to
 			// ensure a bounded number of receives from dataChan.
@@ -369,6 +549,32 @@ func (app *app) process() {
 				}
 				app.doHarvest(h, time.Now(), run)
 			}
+			app.sendAgentStatBatch(agentStats)
+
+			if err := app.sendQueue.shutdown(deadline.Sub(time.Now())); err != nil {
+				app.Error("send queue shutdown timeout exceeded", map[string]interface{}{
+					"err": err.Error(),
+				})
+			}
+			stats := app.sendQueue.stats()
+			app.Info("send queue stats", map[string]interface{}{
+				"queued":  stats.Queued,
+				"sent":    stats.Sent,
+				"failed":  stats.Failed,
+				"dropped": stats.Dropped,
+			})
+
+			app.sendAgentEnd(deadline.Sub(time.Now()))
+			close(app.closeCollector)
+			t := time.NewTimer(deadline.Sub(time.Now()))
+			select {
+			case <-app.collectorClosed:
+			case <-t.C:
+				app.Error("collector close timeout exceeded", map[string]interface{}{
+					"timeout": timeout.String(),
+				})
+			}
+			t.Stop()
 
 			close(app.shutdownComplete)
 			app.setObserver(nil)
@@ -512,9 +718,11 @@ func newApp(c config) *app {
 
 		shutdownStarted:    make(chan struct{}),
 		shutdownComplete:   make(chan struct{}),
+		closeCollector:     make(chan struct{}),
+		collectorClosed:    make(chan struct{}),
 		connectChan:        make(chan *appRun, 1),
 		collectorErrorChan: make(chan rpmResponse, 1),
-		collectInterval:    5000,
+		collectInterval:    c.Collector.StatCollectInterval.Nanoseconds() / 1e6,
 		dataChan:           make(chan appData, appDataChanSize),
 		rpmControls: rpmControls{
 			License: c.License,
@@ -535,6 +743,9 @@ func newApp(c config) *app {
 
 	app.setTAgentInfo()
 	app.setPinpointClient()
+	app.containerStats = newContainerStats()
+	app.sampler = newSampler(c.Config)
+	app.sendQueue = newSendQueue(c.Collector.SendQueueSize, c.Collector.SendWorkers, c.Logger)
 
 	if app.config.Enabled {
 		/*
@@ -550,8 +761,15 @@ func newApp(c config) *app {
 				}
 			}
 		*/
+		app.sendQueue.start()
 		go app.process()
 		go app.connectRoutine()
+		// The connection is kept until the send queue has drained and
+		// the end of the agent has been reported.
+		go func() {
+			app.pinpointClient.keepAlive(app.closeCollector)
+			close(app.collectorClosed)
+		}()
 	}
 
 	return app
@@ -608,15 +826,77 @@ func (app *app) setObserver(observer traceObserver) {
 	app.trObserver = observer
 }
 
-func (app *app) sendAgentStat() error {
-	tagentStat := getTAgentStat(app.config.AgentID, app.startTime, app.collectInterval)
-	err := app.pinpointClient.SendAgentStat(tagentStat)
-	app.Debug("sendAgentStat", map[string]interface{}{
+// collectAgentStat samples the agent stat.
+func (app *app) collectAgentStat() *pinpoint.TAgentStat {
+	tagentStat := getTAgentStat(app.config.AgentID, app.startTime, app.collectInterval, agentStatSources{
+		gc:            &app.gcStats,
+		container:     app.containerStats,
+		txnCounts:     &app.txnCounts,
+		active:        &app.activeTxns,
+		responseTimes: &app.responseTimes,
+		dataSources:   &app.dataSources,
+	})
+	app.Debug("collectAgentStat", map[string]interface{}{
 		"tagentStat": fmt.Sprintf("%#v", tagentStat),
 	})
+	return tagentStat
+}
+
+// sendAgentStatBatch queues the sampled agent stats for sending in one
+// batch.
+func (app *app) sendAgentStatBatch(tagentStats []*pinpoint.TAgentStat) {
+	if len(tagentStats) == 0 {
+		return
+	}
+	tagentStatBatch := &pinpoint.TAgentStatBatch{
+		AgentId:        app.config.AgentID,
+		StartTimestamp: app.startTime,
+		AgentStats:     tagentStats,
+	}
+	app.sendQueue.enqueue("SendAgentStatBatch", func() error {
+		return app.pinpointClient.SendAgentStatBatch(tagentStatBatch)
+	})
+}
 
-	return err
+// spanPacketLimits bounds the span events sent in one span packet.
+func (app *app) spanPacketLimits() spanPacketLimits {
+	return spanPacketLimits{
+		maxEvents: app.config.Collector.SpanEventMaxCount,
+		maxBytes:  app.config.Collector.SpanMaxPacketSize,
+	}
+}
+
+// sendTSpan sends tspan, moving the span events which do not fit into one
+// packet into span chunks sent after it.
+func (app *app) sendTSpan(tspan *trace.TSpan) error {
+	tspanChunks, err := splitTSpan(tspan, app.spanPacketLimits())
+	if err != nil {
+		return err
+	}
+	if err := app.pinpointClient.SendSpan(tspan); err != nil {
+		return err
+	}
+	for _, tspanChunk := range tspanChunks {
+		if err := app.pinpointClient.SendSpanChunk(tspanChunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// sendTSpanChunk sends tspanChunk in as many packets as its span events
+// need.
+func (app *app) sendTSpanChunk(tspanChunk *trace.TSpanChunk) error {
+	tspanChunks, err := splitTSpanChunk(tspanChunk, app.spanPacketLimits())
+	if err != nil {
+		return err
+	}
+	for _, tspanChunk := range tspanChunks {
+		if err := app.pinpointClient.SendSpanChunk(tspanChunk); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func newTransaction(thd *thread) *Transaction {
@@ -720,6 +1000,7 @@ func (app *app) RecordCustomMetric(name string, value float64) error {
 
 var (
 	_ internal.ServerlessWriter = &app{}
+	_ internal.ServiceRecorder  = &app{}
 )
 
 func (app *app) ServerlessWrite(arn string, writer io.Writer) {
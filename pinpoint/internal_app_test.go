@@ -6,8 +6,14 @@ package pinpoint
 import (
 	"errors"
 	"fmt"
+	"net"
+	"strconv"
 	"testing"
 	"time"
+
+	"github.com/dingyalin/pinpoint-go-agent/internal/logger"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
+	tio "github.com/dingyalin/pinpoint-go-agent/thrift/io"
 )
 
 func TestConnectBackoff(t *testing.T) {
@@ -71,3 +77,44 @@ func TestConfigOptionError(t *testing.T) {
 		t.Error("app not nil")
 	}
 }
+
+func TestShutdownReportsAgentEnd(t *testing.T) {
+	fc := newFakeCollector(t)
+	defer fc.Close()
+
+	host, port, _ := net.SplitHostPort(fc.listener.Addr().String())
+	cfg := defaultConfig()
+	cfg.AppName = "app"
+	cfg.AgentID = "agent"
+	cfg.Logger = logger.ShimLogger{}
+	cfg.Collector.IP = host
+	cfg.Collector.TCPPort, _ = strconv.Atoi(port)
+	c, err := newInternalConfig(cfg, func(string) string { return "" }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := newApp(c)
+	start := fc.expectPacket(t, tio.RequestTypeAppRequest)
+	if tstruct, _ := tio.DecodeTstruct(start.Payload); tstruct.(*pinpoint.TAgentInfo).IsSetEndTimestamp() {
+		t.Error(tstruct)
+	}
+
+	before := time.Now().UnixNano() / 1e6
+	app.Shutdown(5 * time.Second)
+	end := fc.expectPacket(t, tio.RequestTypeAppRequest)
+	tstruct, err := tio.DecodeTstruct(end.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentInfo := tstruct.(*pinpoint.TAgentInfo)
+	if agentInfo.GetEndTimestamp() < before || agentInfo.GetEndStatus() != agentEndStatusShutdown ||
+		agentInfo.StartTimestamp != app.startTime {
+		t.Error(agentInfo)
+	}
+	fc.expectPacket(t, tio.RequestTypeControlClientClose)
+	select {
+	case <-app.collectorClosed:
+	default:
+		t.Error("collector not closed on shutdown")
+	}
+}
@@ -63,7 +63,7 @@ func distributedTracingReplyFieldsSpansDisabled(reply *internal.ConnectReply) {
 
 func getDTHeaders(app *Application) http.Header {
 	hdrs := http.Header{}
-	app.StartTransaction("hello").thread.CreateDistributedTracePayload(hdrs, 0)
+	app.StartTransaction("hello").thread.CreateDistributedTracePayload(hdrs, 0, "")
 	return hdrs
 }
 
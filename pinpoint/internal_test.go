@@ -5,6 +5,7 @@ package pinpoint
 
 import (
 	"encoding/json"
+	"errors"
 	"math"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"github.com/dingyalin/pinpoint-go-agent/internal"
+	"github.com/dingyalin/pinpoint-go-agent/internal/cat"
 )
 
 var (
@@ -2134,3 +2136,338 @@ func TestMessageProducerSegmentNilSegment(t *testing.T) {
 	var s *MessageProducerSegment
 	s.End()
 }
+
+func testSamplingApplication(t *testing.T, samplingRate int) (*app, *Application) {
+	cfg := defaultConfig()
+	cfg.AppName = "my app"
+	cfg.AgentID = "agent"
+	cfg.SamplingRate = samplingRate
+	// Prevent spawning app goroutines in tests.
+	cfg.Enabled = false
+	c, err := newInternalConfig(cfg, func(string) string { return "" }, nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	a := newApp(c)
+	a.setState(a.placeholderRun, nil)
+	return a, newApplication(a)
+}
+
+func setSampledCaller(req *http.Request) {
+	req.Header.Set(cat.PinpointTraceidName, "caller^1^1")
+	req.Header.Set(cat.PinpointPapptypeName, "1800")
+	req.Header.Set(cat.PinpointPspanidName, "-1")
+	req.Header.Set(cat.PinpointSpanidName, "7")
+	req.Header.Set(cat.PinpointFlagsName, "2")
+	req.Header.Set(cat.PinpointSampledName, "s1")
+}
+
+func TestTransactionCountsSampling(t *testing.T) {
+	a, application := testSamplingApplication(t, 2)
+	counts := &a.txnCounts
+	counts.sample()
+
+	for i := 0; i < 4; i++ {
+		req, _ := http.NewRequest("GET", "http://example.com/hello", nil)
+		switch i {
+		case 0, 1:
+			// the caller decision wins over the sampling rate
+			setSampledCaller(req)
+		case 2:
+			req.Header.Set(cat.PinpointSampledName, "s0")
+		}
+		txn := application.StartTransaction("hello")
+		txn.SetWebRequestHTTP(req)
+		txn.End()
+	}
+	for i := 0; i < 2; i++ {
+		txn := application.StartTransaction("hello")
+		txn.End()
+	}
+	// only the three new traces are sampled one in two
+	got := counts.sample()
+	if got.GetSampledNewCount() != 2 || got.GetSampledContinuationCount() != 2 ||
+		got.GetUnsampledNewCount() != 1 || got.GetUnsampledContinuationCount() != 1 {
+		t.Error(got.GetSampledNewCount(), got.GetSampledContinuationCount(),
+			got.GetUnsampledNewCount(), got.GetUnsampledContinuationCount())
+	}
+}
+
+func TestUnsampledTransactionSpanEvents(t *testing.T) {
+	_, application := testSamplingApplication(t, 2)
+
+	var txns []*Transaction
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", "http://example.com/hello", nil)
+		txn := application.StartTransaction("hello")
+		txn.SetWebRequestHTTP(req)
+		txn.StartSegment("seg").End()
+		txn.End()
+		txns = append(txns, txn)
+	}
+
+	// the first transaction is sampled by the counter, the second is not
+	sampled, unsampled := txns[0].thread, txns[1].thread
+	if sampled.unsampled || len(sampled.SpanEvents) != 1 {
+		t.Error(sampled.unsampled, len(sampled.SpanEvents))
+	}
+	if !unsampled.unsampled || len(unsampled.SpanEvents) != 0 {
+		t.Error(unsampled.unsampled, len(unsampled.SpanEvents))
+	}
+	// the segments of unsampled transactions are still counted
+	if _, ok := unsampled.customSegments["seg"]; !ok {
+		t.Error(unsampled.customSegments)
+	}
+}
+
+func TestSpanHosts(t *testing.T) {
+	a, application := testSamplingApplication(t, 1)
+	a.placeholderRun.Config.RemoteAddrHeader = "X-Forwarded-For"
+
+	req, _ := http.NewRequest("GET", "http://example.com:8080/hello", nil)
+	req.RemoteAddr = "192.0.2.1:52100"
+	txn := application.StartTransaction("hello")
+	txn.SetWebRequestHTTP(req)
+	if addr := txn.thread.getRemoteAddr(); nil == addr || *addr != "192.0.2.1" {
+		t.Error(addr)
+	}
+	if endPoint := txn.thread.getEndPoint(); nil == endPoint || *endPoint != "example.com:8080" {
+		t.Error(endPoint)
+	}
+	if host := txn.thread.getAcceptorHost(); nil == host || *host != "example.com:8080" {
+		t.Error(host)
+	}
+
+	out, _ := http.NewRequest("GET", "http://backend.local:9090/api", nil)
+	seg := StartExternalSegment(txn, out)
+	if out.Header.Get(cat.PinpointHostName) != "backend.local:9090" {
+		t.Error(out.Header)
+	}
+	seg.End()
+
+	hdrs := http.Header{}
+	seg = &ExternalSegment{StartTime: txn.StartSegmentNow(), Host: "grpc.local:50051", NextSpanID: txn.NextSpanID()}
+	seg.InsertDistributedTraceHeaders(hdrs)
+	if hdrs.Get(cat.PinpointHostName) != "grpc.local:50051" || hdrs.Get(cat.PinpointSpanidName) == "" {
+		t.Error(hdrs)
+	}
+	seg.End()
+
+	hdrs = http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs, txn.NextSpanID())
+	if _, ok := hdrs[cat.PinpointHostName]; ok {
+		t.Error(hdrs)
+	}
+	txn.End()
+
+	req, _ = http.NewRequest("GET", "http://10.0.0.5:8080/hello", nil)
+	req.RemoteAddr = "10.0.0.1:52100"
+	setSampledCaller(req)
+	req.Header.Set(cat.PinpointHostName, "example.com")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	txn = application.StartTransaction("hello")
+	txn.SetWebRequestHTTP(req)
+	if addr := txn.thread.getRemoteAddr(); nil == addr || *addr != "203.0.113.7" {
+		t.Error(addr)
+	}
+	if endPoint := txn.thread.getEndPoint(); nil == endPoint || *endPoint != "10.0.0.5:8080" {
+		t.Error(endPoint)
+	}
+	if host := txn.thread.getAcceptorHost(); nil == host || *host != "example.com" {
+		t.Error(host)
+	}
+	txn.End()
+}
+
+func TestPinpointSampledPropagation(t *testing.T) {
+	_, application := testSamplingApplication(t, 1)
+
+	req, _ := http.NewRequest("GET", "http://example.com/hello", nil)
+	setSampledCaller(req)
+	txn := application.StartTransaction("hello")
+	txn.SetWebRequestHTTP(req)
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs, 42)
+	if hdrs.Get(cat.PinpointSampledName) != "s1" || hdrs.Get(cat.PinpointFlagsName) != "2" ||
+		hdrs.Get(cat.PinpointTraceidName) != "caller^1^1" || hdrs.Get(cat.PinpointSpanidName) != "42" {
+		t.Error(hdrs)
+	}
+	txn.End()
+
+	req, _ = http.NewRequest("GET", "http://example.com/hello", nil)
+	req.Header.Set(cat.PinpointSampledName, "s0")
+	txn = application.StartTransaction("hello")
+	txn.SetWebRequestHTTP(req)
+	if !txn.thread.unsampled {
+		t.Error("caller decision ignored")
+	}
+	hdrs = http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs, 42)
+	if len(hdrs) != 1 || hdrs.Get(cat.PinpointSampledName) != "s0" {
+		t.Error(hdrs)
+	}
+	txn.End()
+
+	txn = application.StartTransaction("hello")
+	hdrs = http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs, 42)
+	if hdrs.Get(cat.PinpointSampledName) != "s1" || hdrs.Get(cat.PinpointFlagsName) != "0" ||
+		hdrs.Get(cat.PinpointTraceidName) != txn.thread.TraceID {
+		t.Error(hdrs)
+	}
+	txn.End()
+}
+
+func TestSamplingRules(t *testing.T) {
+	a, application := testSamplingApplication(t, 1)
+	a.sampler = NewRuleSampler([]SamplingRule{
+		{Method: "GET", Path: "/healthz", Percent: 0},
+	}, NewCounterSampler(1))
+
+	for _, tc := range []struct {
+		path      string
+		unsampled bool
+	}{
+		{"/healthz", true},
+		{"/hello", false},
+	} {
+		req, _ := http.NewRequest("GET", "http://example.com"+tc.path, nil)
+		txn := application.StartTransaction("hello")
+		txn.SetWebRequestHTTP(req)
+		if txn.thread.unsampled != tc.unsampled {
+			t.Error(tc.path, txn.thread.unsampled)
+		}
+		txn.End()
+	}
+
+	// the caller decision wins over the rules
+	req, _ := http.NewRequest("GET", "http://example.com/healthz", nil)
+	setSampledCaller(req)
+	txn := application.StartTransaction("hello")
+	txn.SetWebRequestHTTP(req)
+	if txn.thread.unsampled {
+		t.Error("continuation unsampled")
+	}
+	txn.End()
+}
+
+func TestTailBasedSampling(t *testing.T) {
+	a, application := testSamplingApplication(t, 100)
+	a.placeholderRun.Config.Sampling.TailBased.Enabled = true
+	a.placeholderRun.Config.Sampling.TailBased.LatencyThreshold = time.Hour
+
+	// the first transaction is sampled by the counter
+	application.StartTransaction("first").End()
+
+	for _, tc := range []struct {
+		name      string
+		run       func(txn *Transaction)
+		unsampled bool
+	}{
+		{"ok", func(txn *Transaction) {}, true},
+		{"error", func(txn *Transaction) { txn.NoticeError(errors.New("boom")) }, false},
+		{"status", func(txn *Transaction) {
+			txn.SetWebResponse(&sampleResponseWriter{}).WriteHeader(503)
+		}, false},
+		{"not found", func(txn *Transaction) {
+			txn.SetWebResponse(&sampleResponseWriter{}).WriteHeader(404)
+		}, true},
+	} {
+		txn := application.StartTransaction(tc.name)
+		tc.run(txn)
+		txn.End()
+		if txn.thread.unsampled != tc.unsampled {
+			t.Error(tc.name, txn.thread.unsampled)
+		}
+	}
+
+	a.placeholderRun.Config.Sampling.TailBased.LatencyThreshold = time.Nanosecond
+	txn := application.StartTransaction("slow")
+	time.Sleep(time.Millisecond)
+	txn.End()
+	if txn.thread.unsampled {
+		t.Error("slow transaction dropped")
+	}
+}
+
+func TestTailBasedSamplingUnsampledCaller(t *testing.T) {
+	a, application := testSamplingApplication(t, 1)
+	a.placeholderRun.Config.Sampling.TailBased.Enabled = true
+	a.placeholderRun.Config.Sampling.TailBased.LatencyThreshold = time.Nanosecond
+
+	// the decision of a caller which sent s0 is final, even for failed or
+	// slow transactions
+	req, _ := http.NewRequest("GET", "http://example.com/hello", nil)
+	req.Header.Set(cat.PinpointSampledName, "s0")
+	txn := application.StartTransaction("hello")
+	txn.SetWebRequestHTTP(req)
+	txn.StartSegment("seg").End()
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs, 5)
+	if s := hdrs.Get(cat.PinpointSampledName); s != "s0" {
+		t.Error(s)
+	}
+	if id := hdrs.Get(cat.PinpointTraceidName); id != "" {
+		t.Error(id)
+	}
+	txn.NoticeError(errors.New("boom"))
+	time.Sleep(time.Millisecond)
+	txn.End()
+	if !txn.thread.unsampled {
+		t.Error("unsampled caller overridden")
+	}
+	if n := len(txn.thread.SpanEvents); n != 0 {
+		t.Error(n)
+	}
+}
+
+func TestTailBasedSamplingPropagates(t *testing.T) {
+	a, application := testSamplingApplication(t, 100)
+	application.StartTransaction("first").End()
+
+	// callees are sampled while the decision is pending
+	a.placeholderRun.Config.Sampling.TailBased.Enabled = true
+	txn := application.StartTransaction("hello")
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs, 5)
+	if s := hdrs.Get(cat.PinpointSampledName); s != "s1" {
+		t.Error(s)
+	}
+	if id := hdrs.Get(cat.PinpointTraceidName); id != txn.thread.TraceID {
+		t.Error(id, txn.thread.TraceID)
+	}
+	if id := hdrs.Get(cat.PinpointSpanidName); id != "5" {
+		t.Error(id)
+	}
+	if !txn.thread.unsampled {
+		t.Error("sampled")
+	}
+	txn.End()
+
+	// the trace of a sampled caller is continued
+	req, _ := http.NewRequest("GET", "http://example.com/hello", nil)
+	setSampledCaller(req)
+	txn = application.StartTransaction("hello")
+	txn.SetWebRequestHTTP(req)
+	hdrs = http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs, 5)
+	if s := hdrs.Get(cat.PinpointSampledName); s != "s1" {
+		t.Error(s)
+	}
+	if id := hdrs.Get(cat.PinpointTraceidName); id != "caller^1^1" {
+		t.Error(id)
+	}
+	if id := hdrs.Get(cat.PinpointPspanidName); id != "7" {
+		t.Error(id)
+	}
+	txn.End()
+
+	a.placeholderRun.Config.Sampling.TailBased.Enabled = false
+	txn = application.StartTransaction("hello")
+	hdrs = http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs, 5)
+	if s := hdrs.Get(cat.PinpointSampledName); s != "s0" {
+		t.Error(s)
+	}
+	txn.End()
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -18,6 +19,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"git.apache.org/thrift.git/lib/go/thrift"
 	"github.com/dingyalin/pinpoint-go-agent/internal"
 	"github.com/dingyalin/pinpoint-go-agent/internal/cat"
 	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
@@ -59,6 +61,35 @@ func encodeTraceID(agentID string, startTime int64, sequenceID int64) []byte {
 	return buffer.Bytes()
 }
 
+var errTraceIDInvalid = errors.New("invalid encoded trace id")
+
+// decodeTraceID reverses encodeTraceID.
+func decodeTraceID(data []byte) (agentID string, startTime int64, sequenceID int64, err error) {
+	if len(data) < 2 || data[0] != '\x00' {
+		return "", 0, 0, errTraceIDInvalid
+	}
+	data = data[1:]
+
+	agentIDLen, n := binary.Varint(data)
+	if n <= 0 || agentIDLen < 0 || int64(len(data)-n) < agentIDLen {
+		return "", 0, 0, errTraceIDInvalid
+	}
+	agentID = string(data[n : n+int(agentIDLen)])
+	data = data[n+int(agentIDLen):]
+
+	start, n := binary.Uvarint(data)
+	if n <= 0 {
+		return "", 0, 0, errTraceIDInvalid
+	}
+	data = data[n:]
+
+	sequence, n := binary.Uvarint(data)
+	if n <= 0 {
+		return "", 0, 0, errTraceIDInvalid
+	}
+	return agentID, int64(start), int64(sequence), nil
+}
+
 type txn struct {
 	app *app
 	*appRun
@@ -72,6 +103,14 @@ type txn struct {
 	sampledCalculated  bool
 
 	ignore bool
+	// unsampled transactions are tracked but not sent to the collector.
+	// It is decided by the sampler, unless the transaction continues a
+	// trace whose caller decided it.
+	unsampled       bool
+	samplingDecided bool
+
+	// remoteAddr is the address of the client of web transactions.
+	remoteAddr string
 
 	// wroteHeader prevents capturing multiple response code errors if the
 	// user erroneously calls WriteHeader multiple times.
@@ -144,13 +183,6 @@ func newTxn(app *app, run *appRun, name string) *thread {
 	}
 
 	sequenceID := nextSequenceID()
-	if app.config.SamplingRate > 1 && (sequenceID-1)%int64(app.config.SamplingRate) != 0 {
-		app.config.Logger.Debug("skip transaction", map[string]interface{}{
-			"SequenceID":   sequenceID,
-			"SamplingRate": app.config.SamplingRate,
-		})
-		return nil
-	}
 
 	txn := &txn{
 		app:    app,
@@ -181,6 +213,10 @@ func newTxn(app *app, run *appRun, name string) *thread {
 	txn.TxnTrace.StackTraceThreshold = txn.Config.TransactionTracer.Segments.StackTraceThreshold
 	txn.SlowQueriesEnabled = txn.Config.DatastoreTracer.SlowQuery.Enabled
 	txn.SlowQueryThreshold = txn.Config.DatastoreTracer.SlowQuery.Threshold
+	if txn.Config.Sampling.TailBased.Enabled {
+		txn.SpanEventLimit = txn.Config.Sampling.TailBased.MaxSpanEvents
+	}
+	txn.ShouldRecordSegments = txn.shouldRecordSegments
 
 	// Synthetics support is tied up with a transaction's Old CAT field,
 	// CrossProcess. To support Synthetics with either BetterCAT or Old CAT,
@@ -190,6 +226,8 @@ func newTxn(app *app, run *appRun, name string) *thread {
 	noGUID := txn.Config.DistributedTracer.Enabled
 	txn.CrossProcess.Init(doOldCAT, noGUID, run.Reply)
 
+	app.activeTxns.add(txn)
+
 	return &thread{
 		txn:    txn,
 		thread: &txn.mainThread,
@@ -210,6 +248,21 @@ func (thd *thread) logAPIError(err error, operation string, extraDetails map[str
 	thd.Config.Logger.Error("unable to "+operation, extraDetails)
 }
 
+// shouldRecordSegments reports whether the segments of the transaction are
+// recorded.  Unsampled transactions only count their segments, unless tail
+// based sampling may still keep them when they end.
+func (txn *txn) shouldRecordSegments() bool {
+	return !txn.unsampled || txn.mayKeepUnsampled()
+}
+
+// mayKeepUnsampled reports whether the transaction, if unsampled, may still be
+// kept when it ends.  The decision of a caller which sent s0 is final, since
+// the callees it made meanwhile were told s0 as well.
+func (txn *txn) mayKeepUnsampled() bool {
+	return txn.Config.Sampling.TailBased.Enabled &&
+		txn.CrossProcess.InboundMetadata.PinpointSampled != pinpointSampledFalse
+}
+
 func (txn *txn) shouldCollectSpanEvents() bool {
 	if !txn.Config.DistributedTracer.Enabled {
 		return false
@@ -272,17 +325,87 @@ func (txn *txn) SetWebRequest(r WebRequest) error {
 
 	// cross process
 	metadata := txn.CrossProcess.InboundMetadata
-	if metadata.PinpointTraceid != "" {
+	if metadata.PinpointSampled == pinpointSampledFalse {
+		txn.unsampled = true
+		txn.samplingDecided = true
+	} else if metadata.PinpointTraceid != "" {
+		txn.unsampled = false
+		txn.samplingDecided = true
 		txn.TraceID = metadata.PinpointTraceid
 		txn.TraceIDEncoded = metadata.PinpointTraceidEncoded
 		txn.SpanID = metadata.PinpointSpanid
 	}
 
 	requestAgentAttributes(txn.Attrs, r.Method, h, r.URL, r.Host)
+	txn.remoteAddr = remoteAddr(r, txn.Config.RemoteAddrHeader)
+
+	req := SamplingRequest{Name: txn.Name, Method: r.Method}
+	if nil != r.URL {
+		req.Path = r.URL.Path
+	}
+	txn.decideSampling(req)
 
 	return nil
 }
 
+// keepIfInteresting samples the unsampled transactions which failed or ran
+// over the latency threshold, when the decision is made at their end.
+func (txn *txn) keepIfInteresting() {
+	tail := txn.Config.Sampling.TailBased
+	if !txn.unsampled || !txn.mayKeepUnsampled() {
+		return
+	}
+	var reason string
+	if code, ok := txn.getStatusCode(); ok && code >= http.StatusInternalServerError {
+		reason = "status code"
+	} else if txn.HasErrors() {
+		reason = "error"
+	} else if tail.LatencyThreshold > 0 && txn.Duration >= tail.LatencyThreshold {
+		reason = "latency"
+	} else {
+		return
+	}
+	txn.unsampled = false
+	txn.Config.Logger.Debug("transaction kept", map[string]interface{}{
+		"SequenceID":  txn.SequenceID,
+		"name":        txn.Name,
+		"reason":      reason,
+		"duration_ms": txn.Duration.Seconds() * 1000.0,
+	})
+}
+
+// decideSampling asks the sampler whether the transaction, which starts a new
+// trace, is sampled.  It is decided once, when the request of web transactions
+// is known, or when the decision is first needed otherwise.
+func (txn *txn) decideSampling(req SamplingRequest) {
+	if txn.samplingDecided || nil == txn.app || nil == txn.app.sampler {
+		return
+	}
+	txn.samplingDecided = true
+	txn.unsampled = !txn.app.sampler.IsSampled(req)
+	if txn.unsampled {
+		txn.Config.Logger.Debug("unsampled transaction", map[string]interface{}{
+			"SequenceID": txn.SequenceID,
+			"name":       req.Name,
+			"path":       req.Path,
+		})
+	}
+}
+
+// remoteAddr returns the address of the client of r: the first address
+// listed by its header named addrHeader if present, its peer IP otherwise.
+func remoteAddr(r WebRequest, addrHeader string) string {
+	if "" != addrHeader && nil != r.Header {
+		if addrs := r.Header.Get(addrHeader); "" != addrs {
+			return strings.TrimSpace(strings.SplitN(addrs, ",", 2)[0])
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); nil == err {
+		return host
+	}
+	return r.RemoteAddr
+}
+
 type dummyResponseWriter struct{}
 
 func (rw dummyResponseWriter) Header() http.Header { return nil }
@@ -388,22 +511,16 @@ func (txn *txn) MergeIntoHarvest(h *harvest) {
 		}
 	*/
 
+	// Encoding may send api metadata, so it is left to the send queue
+	// together with the span.
 	if txn.IsAsync {
-		spanChunk := txn.toAsyncTSpanChunk()
-		err := txn.app.pinpointClient.SendSpanChunk(spanChunk)
-		if err != nil {
-			txn.app.Warn("SendSpanChunk failed", map[string]interface{}{
-				"err": err,
-			})
-		}
+		txn.app.sendQueue.enqueue("SendSpanChunk", func() error {
+			return txn.app.sendTSpanChunk(txn.toAsyncTSpanChunk())
+		})
 	} else {
-		tspan := txn.toTSpan()
-		err := txn.app.pinpointClient.SendSpan(tspan)
-		if err != nil {
-			txn.app.Warn("SendSpan failed", map[string]interface{}{
-				"err": err,
-			})
-		}
+		txn.app.sendQueue.enqueue("SendSpan", func() error {
+			return txn.app.sendTSpan(txn.toTSpan())
+		})
 	}
 
 }
@@ -482,6 +599,9 @@ func (thd *thread) End(recovered interface{}) error {
 	}
 
 	txn.finished = true
+	if nil != txn.app {
+		txn.app.activeTxns.remove(txn)
+	}
 
 	if nil != recovered {
 		e := txnErrorFromPanic(time.Now(), recovered)
@@ -490,6 +610,14 @@ func (thd *thread) End(recovered interface{}) error {
 	}
 
 	txn.markEnd(time.Now(), thd.thread)
+	txn.decideSampling(SamplingRequest{Name: txn.Name})
+	txn.keepIfInteresting()
+	if nil != txn.app {
+		metadata := txn.CrossProcess.InboundMetadata
+		continuation := "" != metadata.PinpointTraceid || pinpointSampledFalse == metadata.PinpointSampled
+		txn.app.txnCounts.count(!txn.unsampled, continuation)
+		txn.app.responseTimes.add(txn.Duration)
+	}
 	txn.freezeName()
 	// Make a sampling decision if there have been no segments or outbound
 	// payloads.
@@ -570,7 +698,7 @@ func (thd *thread) End(recovered interface{}) error {
 		}
 	*/
 
-	if !txn.ignore {
+	if !txn.ignore && !txn.unsampled {
 		txn.app.Consume(txn.Reply.RunID, txn)
 		/*
 			if observer := txn.app.getObserver(); nil != observer {
@@ -591,7 +719,7 @@ func (thd *thread) End(recovered interface{}) error {
 }
 
 // db
-func handeDatastoreSpanEvent(evt *spanEvent, tSpanEvent *trace.TSpanEvent) {
+func (txn *txn) handeDatastoreSpanEvent(evt *spanEvent, tSpanEvent *trace.TSpanEvent) {
 	isDatastore := false
 	if evt.Component == string(DatastoreMySQL) {
 		// serviceType
@@ -623,14 +751,31 @@ func handeDatastoreSpanEvent(evt *spanEvent, tSpanEvent *trace.TSpanEvent) {
 
 	// sql
 	statement := evt.AgentAttributes.getStringValue(SpanAttributeDBStatement)
-	if statement != "" {
+	if statement == "" {
+		return
+	}
+	sql, output := normalizeSQL(statement)
+	sqlID := txn.getSQLID(sql)
+	if sqlID == nil {
+		// the sql could not be registered, send it as it is
 		tSpanEvent.Annotations = append(tSpanEvent.Annotations, &trace.TAnnotation{
 			Key: io.TAnnotationSQL,
 			Value: &trace.TAnnotationValue{
 				StringValue: &statement,
 			},
 		})
+		return
 	}
+	sqlValue := &trace.TIntStringStringValue{IntValue: *sqlID}
+	if output != "" {
+		sqlValue.StringValue1 = &output
+	}
+	tSpanEvent.Annotations = append(tSpanEvent.Annotations, &trace.TAnnotation{
+		Key: io.TAnnotationSQLID,
+		Value: &trace.TAnnotationValue{
+			IntStringStringValue: sqlValue,
+		},
+	})
 
 }
 
@@ -702,7 +847,10 @@ func (txn *txn) getTSpanEventList() []*trace.TSpanEvent {
 			AsyncSequence: asyncSequence,
 		}
 		handeExternalSpanEvent(evt, tSpanEvent)
-		handeDatastoreSpanEvent(evt, tSpanEvent)
+		txn.handeDatastoreSpanEvent(evt, tSpanEvent)
+		tSpanEvent.ExceptionInfo = txn.getExceptionInfo(
+			evt.AgentAttributes.getStringValue(SpanAttributeErrorClass),
+			evt.AgentAttributes.getStringValue(SpanAttributeErrorMessage))
 
 		config.Logger.Debug("TSpanEvent", map[string]interface{}{
 			"TSpanEvent":      fmt.Sprintf("%#v", tSpanEvent),
@@ -741,7 +889,8 @@ func (txn *txn) getRPC() *string {
 	return nil
 }
 
-func (txn *txn) getAcceptorHost() *string {
+// getEndPoint returns the host the request of web transactions was sent to.
+func (txn *txn) getEndPoint() *string {
 	agentAttributeValue, ok := txn.Attrs.Agent[AttributeRequestHost]
 	if ok {
 		return &agentAttributeValue.stringVal
@@ -750,33 +899,62 @@ func (txn *txn) getAcceptorHost() *string {
 	return nil
 }
 
-func (txn *txn) getAnnotationsErr() (annotations []*trace.TAnnotation, err int32) {
+// getAcceptorHost returns the host the caller sent the request to, which may
+// differ from the end point behind proxies and load balancers.
+func (txn *txn) getAcceptorHost() *string {
+	if host := txn.CrossProcess.InboundMetadata.PinpointHost; host != "" {
+		return &host
+	}
+	return txn.getEndPoint()
+}
+
+func (txn *txn) getRemoteAddr() *string {
+	if txn.remoteAddr == "" {
+		return nil
+	}
+	remoteAddr := txn.remoteAddr
+	return &remoteAddr
+}
+
+// getStatusCode returns the status code of the response of web transactions.
+func (txn *txn) getStatusCode() (int, bool) {
 	agentAttributeValue, ok := txn.Attrs.Agent[AttributeResponseCode]
-	if ok {
-		statusCode, ok := agentAttributeValue.otherVal.(int)
-		if ok {
-			intValue := int32(statusCode)
-			annotations = append(annotations, &trace.TAnnotation{
-				Key: io.TAnnotationHTTPStatusCode,
-				Value: &trace.TAnnotationValue{
-					IntValue: &intValue,
-				},
-			})
-			// err
-			if txn.responseCodeIsError(statusCode) {
-				err = 1
-			}
+	if !ok {
+		return 0, false
+	}
+	statusCode, ok := agentAttributeValue.otherVal.(int)
+	return statusCode, ok
+}
+
+func (txn *txn) getAnnotationsErr() (annotations []*trace.TAnnotation, err int32, exceptionInfo *trace.TIntStringValue) {
+	if statusCode, ok := txn.getStatusCode(); ok {
+		intValue := int32(statusCode)
+		annotations = append(annotations, &trace.TAnnotation{
+			Key: io.TAnnotationHTTPStatusCode,
+			Value: &trace.TAnnotationValue{
+				IntValue: &intValue,
+			},
+		})
+		// err
+		if txn.responseCodeIsError(statusCode) {
+			err = 1
 		}
 	}
 
-	for _, errData := range txn.Errors {
+	for i, errData := range txn.Errors {
+		err = 1
+		if i == 0 {
+			// the first error is reported as the exception of the span
+			if exceptionInfo = txn.getExceptionInfo(errData.Klass, errData.Msg); exceptionInfo != nil {
+				continue
+			}
+		}
 		annotations = append(annotations, &trace.TAnnotation{
 			Key: io.TAnnotationException,
 			Value: &trace.TAnnotationValue{
 				StringValue: &errData.Msg,
 			},
 		})
-		err = 1
 	}
 
 	return
@@ -796,40 +974,85 @@ func (txn *txn) getParentApplication() (pAppName *string, pAppType *int16, pSpan
 }
 
 func (txn *txn) getAPIID(apiName string) *int32 {
-	if txn.app.apiMetaDataMap == nil {
-		txn.app.apiMetaDataMap = make(map[string]int32)
-	}
-	if apiID, ok := txn.app.apiMetaDataMap[apiName]; ok {
-		return &apiID
-	}
+	config := txn.Config
+	return txn.getMetaDataID(&txn.app.apiMetaData, apiName, config.APIMetaDataMapSize, io.TTypeAPIMetadata, "TApiMetaData",
+		func(apiID int32) thrift.TStruct {
+			return &trace.TApiMetaData{
+				AgentId:        config.AgentID,
+				AgentStartTime: txn.app.startTime,
+				ApiId:          apiID,
+				ApiInfo:        apiName,
+			}
+		})
+}
 
-	apiID := txn.app.apiID + 1 // from 1
-	if len(txn.app.apiMetaDataMap) > txn.Config.APIMetaDataMapSize {
-		txn.app.apiMetaDataMap = make(map[string]int32)
-	}
+// getSQLID returns the id of the normalized sql, registering it with the
+// collector the first time it is seen.  It returns nil if the registration
+// failed.
+func (txn *txn) getSQLID(sql string) *int32 {
+	config := txn.Config
+	return txn.getMetaDataID(&txn.app.sqlMetaData, sql, config.SQLMetaDataMapSize, io.TTypeSQLMetadata, "TSqlMetaData",
+		func(sqlID int32) thrift.TStruct {
+			return &trace.TSqlMetaData{
+				AgentId:        config.AgentID,
+				AgentStartTime: txn.app.startTime,
+				SqlId:          sqlID,
+				Sql:            sql,
+			}
+		})
+}
 
+// getStringID returns the id of str, registering it with the collector the
+// first time it is seen.  It returns nil if the registration failed.
+func (txn *txn) getStringID(str string) *int32 {
 	config := txn.Config
-	tapiMetaData := &trace.TApiMetaData{
-		AgentId:        config.AgentID,
-		AgentStartTime: txn.app.startTime,
-		ApiId:          apiID,
-		ApiInfo:        apiName,
-	}
-	err := txn.app.pinpointClient.SendTCPTStruct(io.TTypeAPIMetadata, tapiMetaData)
-	if err != nil {
-		txn.app.Warn("SendTApiMetaData failed", map[string]interface{}{
-			"err": err,
+	return txn.getMetaDataID(&txn.app.stringMetaData, str, config.StringMetaDataMapSize, io.TTypeStringMetadata, "TStringMetaData",
+		func(stringID int32) thrift.TStruct {
+			return &trace.TStringMetaData{
+				AgentId:        config.AgentID,
+				AgentStartTime: txn.app.startTime,
+				StringId:       stringID,
+				StringValue:    str,
+			}
+		})
+}
+
+// getMetaDataID returns the id of value in cache.  The first time value is
+// seen, the metadata newTStruct builds for its new id is registered with the
+// collector as ttype, and nil is returned if the registration failed.
+func (txn *txn) getMetaDataID(cache *metaDataCache, value string, maxSize int, ttype uint16, name string,
+	newTStruct func(id int32) thrift.TStruct) *int32 {
+	id, ok := cache.id(value, maxSize, func(id int32) error {
+		tstruct := newTStruct(id)
+		if err := txn.app.pinpointClient.RequestTStruct(ttype, tstruct); err != nil {
+			txn.app.Warn("Send"+name+" failed", map[string]interface{}{
+				"err": err,
+			})
+			return err
+		}
+		txn.Config.Logger.Debug(name, map[string]interface{}{
+			name: fmt.Sprintf("%#v", tstruct),
 		})
-		time.Sleep(time.Second)
+		return nil
+	})
+	if !ok {
 		return nil
 	}
+	return &id
+}
 
-	txn.app.apiMetaDataMap[apiName] = apiID
-	txn.app.apiID++
-	config.Logger.Debug("TApiMetaData", map[string]interface{}{
-		"TApiMetaData": fmt.Sprintf("%#v", tapiMetaData),
-	})
-	return &apiID
+// getExceptionInfo returns the exception info of an error of class with msg,
+// the class being referenced by its string id.  It returns nil if class is
+// empty or could not be registered.
+func (txn *txn) getExceptionInfo(class string, msg string) *trace.TIntStringValue {
+	if class == "" {
+		return nil
+	}
+	classID := txn.getStringID(class)
+	if classID == nil {
+		return nil
+	}
+	return &trace.TIntStringValue{IntValue: *classID, StringValue: &msg}
 }
 
 func (txn *txn) toAsyncTSpanChunk() *trace.TSpanChunk {
@@ -856,7 +1079,7 @@ func (txn *txn) toAsyncTSpanChunk() *trace.TSpanChunk {
 
 func (txn *txn) toTSpan() *trace.TSpan {
 	config := txn.Config
-	annotations, err := txn.getAnnotationsErr()
+	annotations, err, exceptionInfo := txn.getAnnotationsErr()
 	pAppName, pAppType, pSpanID := txn.getParentApplication()
 
 	tspan := &trace.TSpan{
@@ -870,8 +1093,8 @@ func (txn *txn) toTSpan() *trace.TSpan {
 		Elapsed:                int32(txn.Duration.Milliseconds()),
 		RPC:                    txn.getRPC(),
 		ServiceType:            io.ServiceTypeGoMethod,
-		EndPoint:               nil, // nil
-		RemoteAddr:             nil, // nil
+		EndPoint:               txn.getEndPoint(),
+		RemoteAddr:             txn.getRemoteAddr(),
 		Annotations:            annotations,
 		Flag:                   0,
 		Err:                    &err,
@@ -880,7 +1103,7 @@ func (txn *txn) toTSpan() *trace.TSpan {
 		ParentApplicationType:  pAppType,
 		AcceptorHost:           txn.getAcceptorHost(),
 		ApiId:                  txn.getAPIID(txn.FinalName),
-		ExceptionInfo:          nil, // nil
+		ExceptionInfo:          exceptionInfo,
 		ApplicationServiceType: &config.ServiceType,
 		LoggingTransactionInfo: nil, // nil
 	}
@@ -1437,6 +1660,17 @@ func oldCATOutboundHeaders(txn *txn) http.Header {
 	return metadataToHTTPHeader(metadata)
 }
 
+// externalSegmentHost returns the host, with its port if any, called by s.
+func externalSegmentHost(s *ExternalSegment) string {
+	if "" != s.Host {
+		return s.Host
+	}
+	if u, err := externalSegmentURL(s); nil == err && nil != u {
+		return u.Host
+	}
+	return ""
+}
+
 func outboundHeaders(s *ExternalSegment) http.Header {
 	thd := s.StartTime.thread
 	header := http.Header{}
@@ -1449,7 +1683,7 @@ func outboundHeaders(s *ExternalSegment) http.Header {
 
 	// hdr may be empty, or it may contain headers.  If DistributedTracer
 	// is enabled, add more to the existing hdr
-	thd.CreateDistributedTracePayload(header, s.NextSpanID)
+	thd.CreateDistributedTracePayload(header, s.NextSpanID, externalSegmentHost(s))
 
 	return header
 }
@@ -1458,7 +1692,10 @@ const (
 	maxSampledDistributedPayloads = 35
 )
 
-func (thd *thread) CreateDistributedTracePayload(hdrs http.Header, nextSpanID int64) {
+// CreateDistributedTracePayload adds the headers linking the transaction to
+// the callee of an outbound call to hdrs.  host is the host the call is sent
+// to, it is omitted when empty.
+func (thd *thread) CreateDistributedTracePayload(hdrs http.Header, nextSpanID int64, host string) {
 	txn := thd.txn
 	txn.Lock()
 	defer txn.Unlock()
@@ -1542,6 +1779,13 @@ func (thd *thread) CreateDistributedTracePayload(hdrs http.Header, nextSpanID in
 		}
 		hdrs.Set(DistributedTraceW3CTraceStateHeader, p.W3CTraceState())
 	*/
+	// Transactions decided at their end may yet be kept, so that their
+	// callees are always sampled.
+	txn.decideSampling(SamplingRequest{Name: txn.Name})
+	if txn.unsampled && !txn.mayKeepUnsampled() {
+		hdrs.Set(cat.PinpointSampledName, pinpointSampledFalse)
+		return
+	}
 	if nextSpanID == 0 || nextSpanID == -1 {
 		return
 	}
@@ -1564,7 +1808,15 @@ func (thd *thread) CreateDistributedTracePayload(hdrs http.Header, nextSpanID in
 	hdrs.Set(cat.PinpointPapptypeName, strconv.Itoa(int(txn.app.config.ServiceType)))
 	hdrs.Set(cat.PinpointPspanidName, strconv.FormatInt(spanID, 10))
 	hdrs.Set(cat.PinpointSpanidName, strconv.FormatInt(nextSpanID, 10))
-	hdrs.Set(cat.PinpointFlagsName, "0")
+	flags := inboundMetadata.PinpointFlags
+	if flags == "" {
+		flags = "0"
+	}
+	hdrs.Set(cat.PinpointFlagsName, flags)
+	hdrs.Set(cat.PinpointSampledName, pinpointSampledTrue)
+	if host != "" {
+		hdrs.Set(cat.PinpointHostName, host)
+	}
 }
 
 var (
@@ -968,3 +968,55 @@ func Test_encodeTraceID(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoteAddr(t *testing.T) {
+	forwarded := http.Header{}
+	forwarded.Set("X-Forwarded-For", " 203.0.113.7 , 10.0.0.1")
+	for _, tc := range []struct {
+		req    WebRequest
+		header string
+		expect string
+	}{
+		{WebRequest{RemoteAddr: "192.0.2.1:52100"}, "", "192.0.2.1"},
+		{WebRequest{RemoteAddr: "[2001:db8::1]:52100"}, "", "2001:db8::1"},
+		{WebRequest{RemoteAddr: "@"}, "", "@"},
+		{WebRequest{RemoteAddr: "192.0.2.1:52100", Header: forwarded}, "X-Forwarded-For", "203.0.113.7"},
+		{WebRequest{RemoteAddr: "192.0.2.1:52100", Header: forwarded}, "", "192.0.2.1"},
+		{WebRequest{RemoteAddr: "192.0.2.1:52100", Header: forwarded}, "X-Real-Ip", "192.0.2.1"},
+		{WebRequest{}, "X-Forwarded-For", ""},
+	} {
+		if addr := remoteAddr(tc.req, tc.header); addr != tc.expect {
+			t.Errorf("%#v %q: got %q, want %q", tc.req, tc.header, addr, tc.expect)
+		}
+	}
+}
+
+func BenchmarkNewTxn(b *testing.B) {
+	cfg := defaultConfig()
+	cfg.AppName = "my app"
+	cfg.AgentID = "agent"
+	// Prevent spawning app goroutines in benchmarks.
+	cfg.Enabled = false
+	c, err := newInternalConfig(cfg, func(string) string { return "" }, nil)
+	if nil != err {
+		b.Fatal(err)
+	}
+	app := newApp(c)
+
+	for _, tc := range []struct {
+		name            string
+		trackGoroutines bool
+	}{
+		{"untracked", false},
+		{"goroutines", true},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			app.activeTxns.trackGoroutines = tc.trackGoroutines
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				txn := newTxn(app, app.placeholderRun, "hello")
+				app.activeTxns.remove(txn.txn)
+			}
+		})
+	}
+}
@@ -30,6 +30,27 @@ const (
 	maxTxnErrors      = 5
 	maxTxnSlowQueries = 10
 
+	// active thread dumps
+	// maxActiveThreadDumps is the maximum number of transactions in an
+	// active thread dump.
+	maxActiveThreadDumps = 100
+	// maxActiveThreadDumpFrames is the maximum number of stack frames
+	// dumped per transaction.
+	maxActiveThreadDumpFrames = 64
+	// maxGoroutineDumpSize is the maximum size of the stack traces of all
+	// goroutines read for an active thread dump.
+	maxGoroutineDumpSize = 16 * 1024 * 1024
+
+	// server metadata
+	// maxServiceEntryPoints is the maximum number of entry points, such as
+	// http patterns or grpc methods, reported per kind.
+	maxServiceEntryPoints = 256
+
+	// data sources
+	// maxDataSources is the maximum number of database pools reported in
+	// the agent stats.
+	maxDataSources = 20
+
 	startingTxnTraceNodes = 16
 	maxTxnTraceNodes      = 256
 
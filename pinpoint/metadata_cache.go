@@ -0,0 +1,65 @@
+package pinpoint
+
+import "sync"
+
+// metaDataCache assigns the ids of one kind of metadata, such as api names,
+// sql statements or strings, which the collector must know before spans can
+// refer to them.  A value is registered the first time its id is asked for.
+// The registration runs without the lock held, so that a slow collector only
+// holds up the callers waiting for the same value.
+type metaDataCache struct {
+	sync.Mutex
+	lastID int32
+	ids    map[string]int32
+	// inflight are the registrations in progress, by value.
+	inflight map[string]*metaDataCall
+}
+
+// metaDataCall is the registration of a value, which the callers asking for
+// the same value in the meantime wait for.
+type metaDataCall struct {
+	done chan struct{}
+	id   int32
+	ok   bool
+}
+
+// id returns the id of value, calling register with a new id the first time
+// value is seen.  The id is kept only if register succeeds, and ok is false
+// otherwise.  The cache is emptied once it holds more than maxSize values.
+func (c *metaDataCache) id(value string, maxSize int, register func(id int32) error) (id int32, ok bool) {
+	c.Lock()
+	if id, ok := c.ids[value]; ok {
+		c.Unlock()
+		return id, true
+	}
+	if call, ok := c.inflight[value]; ok {
+		c.Unlock()
+		<-call.done
+		return call.id, call.ok
+	}
+	c.lastID++ // from 1
+	call := &metaDataCall{done: make(chan struct{}), id: c.lastID}
+	if nil == c.inflight {
+		c.inflight = make(map[string]*metaDataCall)
+	}
+	c.inflight[value] = call
+	c.Unlock()
+
+	err := register(call.id)
+
+	c.Lock()
+	delete(c.inflight, value)
+	if nil == err {
+		if nil == c.ids || len(c.ids) > maxSize {
+			c.ids = make(map[string]int32)
+		}
+		c.ids[value] = call.id
+		call.ok = true
+	} else if c.lastID == call.id {
+		// Reuse the id unless another value has taken the next one.
+		c.lastID--
+	}
+	c.Unlock()
+	close(call.done)
+	return call.id, call.ok
+}
@@ -0,0 +1,88 @@
+package pinpoint
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMetaDataCacheID(t *testing.T) {
+	var c metaDataCache
+	var registered []int32
+	register := func(id int32) error {
+		registered = append(registered, id)
+		return nil
+	}
+	errDown := errors.New("collector down")
+
+	if id, ok := c.id("a", 10, func(int32) error { return errDown }); ok {
+		t.Error(id)
+	}
+	if id, ok := c.id("a", 10, register); !ok || id != 1 {
+		t.Error(id, ok)
+	}
+	if id, ok := c.id("a", 10, register); !ok || id != 1 {
+		t.Error(id, ok)
+	}
+	if id, ok := c.id("b", 10, register); !ok || id != 2 {
+		t.Error(id, ok)
+	}
+	if len(registered) != 2 {
+		t.Error(registered)
+	}
+
+	// The ids are forgotten, not reused, once the cache is full.
+	if id, ok := c.id("c", 1, register); !ok || id != 3 || len(c.ids) != 1 {
+		t.Error(id, ok, c.ids)
+	}
+}
+
+func TestMetaDataCacheConcurrent(t *testing.T) {
+	var c metaDataCache
+	var calls int32
+	release := make(chan struct{})
+	slow := func(id int32) error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	ids := make([]int32, 5)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ids[i], _ = c.id("slow", 10, slow)
+		}(i)
+	}
+
+	// Other values are not held up by the registration in progress.
+	fast := make(chan int32, 1)
+	go func() {
+		// Wait for the slow registration to start.
+		for atomic.LoadInt32(&calls) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		id, _ := c.id("fast", 10, func(int32) error { return nil })
+		fast <- id
+	}()
+	select {
+	case <-fast:
+	case <-time.After(5 * time.Second):
+		t.Fatal("registration blocked by another value")
+	}
+
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Error(n)
+	}
+	for _, id := range ids {
+		if id != ids[0] {
+			t.Error(ids)
+		}
+	}
+}
@@ -246,6 +246,21 @@ func (s *ExternalSegment) SetStatusCode(code int) {
 	s.statusCode = &code
 }
 
+// InsertDistributedTraceHeaders adds the headers linking the transaction to
+// the callee of the segment to hdrs, including the host it is called on.  Use
+// it instead of Transaction.InsertDistributedTraceHeaders for calls which do
+// not use an *http.Request, after setting the Host or URL of the segment.
+func (s *ExternalSegment) InsertDistributedTraceHeaders(hdrs http.Header) {
+	if nil == s {
+		return
+	}
+	for key, values := range s.outboundHeaders() {
+		for _, value := range values {
+			hdrs.Set(key, value)
+		}
+	}
+}
+
 // outboundHeaders returns the headers that should be attached to the external
 // request.
 func (s *ExternalSegment) outboundHeaders() http.Header {
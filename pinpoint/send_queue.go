@@ -0,0 +1,158 @@
+package pinpoint
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sendQueue hands spans and agent stats to worker goroutines which encode
+// and send them to the collector.  Neither transactions nor the process
+// goroutine wait on the network: items arriving while the queue is full are
+// dropped.
+type sendQueue struct {
+	// The counters are accessed atomically and must stay at the start of
+	// the struct to be 64-bit aligned.
+	queued  uint64
+	sent    uint64
+	failed  uint64
+	dropped uint64
+
+	items   chan sendItem
+	workers int
+	log     Logger
+
+	// done is closed by shutdown, after which the workers send what is
+	// left in items and exit.
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// sendItem is a unit of work for the send queue.  send encodes and sends
+// the item, name identifies it in logs.
+type sendItem struct {
+	name string
+	send func() error
+}
+
+// sendQueueStats is a snapshot of the send queue counters.
+type sendQueueStats struct {
+	Queued  uint64
+	Sent    uint64
+	Failed  uint64
+	Dropped uint64
+}
+
+var errSendQueueTimeout = errors.New("timeout exceeded while waiting for the send queue to drain")
+
+func newSendQueue(size int, workers int, log Logger) *sendQueue {
+	if size < 0 {
+		size = 0
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return &sendQueue{
+		items:   make(chan sendItem, size),
+		workers: workers,
+		log:     log,
+		done:    make(chan struct{}),
+	}
+}
+
+// start starts the worker goroutines.
+func (q *sendQueue) start() {
+	q.wg.Add(q.workers)
+	for i := 0; i < q.workers; i++ {
+		go q.work()
+	}
+}
+
+func (q *sendQueue) work() {
+	defer q.wg.Done()
+	for {
+		select {
+		case item := <-q.items:
+			q.send(item)
+		case <-q.done:
+			for {
+				select {
+				case item := <-q.items:
+					q.send(item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *sendQueue) send(item sendItem) {
+	if err := item.send(); nil != err {
+		atomic.AddUint64(&q.failed, 1)
+		q.log.Warn(item.name+" failed", map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+	atomic.AddUint64(&q.sent, 1)
+}
+
+// enqueue adds an item to the queue without blocking.  It returns false if
+// the item was dropped because the queue is full or shut down.
+func (q *sendQueue) enqueue(name string, send func() error) bool {
+	select {
+	case <-q.done:
+		atomic.AddUint64(&q.dropped, 1)
+		return false
+	default:
+	}
+
+	select {
+	case q.items <- sendItem{name: name, send: send}:
+		atomic.AddUint64(&q.queued, 1)
+		return true
+	default:
+		atomic.AddUint64(&q.dropped, 1)
+		if q.log.DebugEnabled() {
+			q.log.Debug("send queue full, item dropped", map[string]interface{}{
+				"item":       name,
+				"queue size": cap(q.items),
+			})
+		}
+		return false
+	}
+}
+
+// stats returns the current counters.
+func (q *sendQueue) stats() sendQueueStats {
+	return sendQueueStats{
+		Queued:  atomic.LoadUint64(&q.queued),
+		Sent:    atomic.LoadUint64(&q.sent),
+		Failed:  atomic.LoadUint64(&q.failed),
+		Dropped: atomic.LoadUint64(&q.dropped),
+	}
+}
+
+// shutdown stops accepting items and blocks until the workers have sent the
+// queued items or the timeout is hit.
+func (q *sendQueue) shutdown(timeout time.Duration) error {
+	q.closeOnce.Do(func() { close(q.done) })
+
+	drained := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+		return nil
+	case <-timer.C:
+		return errSendQueueTimeout
+	}
+}
@@ -0,0 +1,133 @@
+package pinpoint
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+
+	"github.com/dingyalin/pinpoint-go-agent/internal/logger"
+)
+
+func TestSendQueueDropsWhenFull(t *testing.T) {
+	q := newSendQueue(2, 1, logger.ShimLogger{})
+	sent := make(chan string, 3)
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		q.enqueue(name, func() error {
+			sent <- name
+			return nil
+		})
+	}
+	if stats := q.stats(); stats.Queued != 2 || stats.Dropped != 1 {
+		t.Errorf("%+v", stats)
+	}
+
+	q.start()
+	if err := q.shutdown(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := <-sent, <-sent; a != "a" || b != "b" || len(sent) != 0 {
+		t.Error(a, b, len(sent))
+	}
+	if stats := q.stats(); stats.Sent != 2 || stats.Failed != 0 {
+		t.Errorf("%+v", stats)
+	}
+}
+
+func TestSendQueueFailed(t *testing.T) {
+	q := newSendQueue(1, 1, logger.ShimLogger{})
+	q.start()
+	q.enqueue("SendSpan", func() error { return errors.New("unreachable") })
+	if err := q.shutdown(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if stats := q.stats(); stats.Queued != 1 || stats.Sent != 0 || stats.Failed != 1 {
+		t.Errorf("%+v", stats)
+	}
+}
+
+func TestSendQueueShutdown(t *testing.T) {
+	q := newSendQueue(1, 1, logger.ShimLogger{})
+	q.start()
+	block := make(chan struct{})
+	defer close(block)
+	q.enqueue("SendSpan", func() error {
+		<-block
+		return nil
+	})
+	if err := q.shutdown(10 * time.Millisecond); err != errSendQueueTimeout {
+		t.Error(err)
+	}
+	if q.enqueue("SendSpan", func() error { return nil }) {
+		t.Error("item queued after shutdown")
+	}
+	if stats := q.stats(); stats.Dropped != 1 {
+		t.Errorf("%+v", stats)
+	}
+}
+
+// blockingCollectorClient blocks every send until release is closed.
+type blockingCollectorClient struct {
+	release chan struct{}
+}
+
+func (c blockingCollectorClient) RequestTStruct(ttype uint16, tstruct thrift.TStruct) error {
+	<-c.release
+	return nil
+}
+func (c blockingCollectorClient) SendSpan(tstruct thrift.TStruct) error {
+	<-c.release
+	return nil
+}
+func (c blockingCollectorClient) SendSpanChunk(tstruct thrift.TStruct) error {
+	<-c.release
+	return nil
+}
+func (c blockingCollectorClient) SendAgentStat(tstruct thrift.TStruct) error {
+	<-c.release
+	return nil
+}
+func (c blockingCollectorClient) SendAgentStatBatch(tstruct thrift.TStruct) error {
+	<-c.release
+	return nil
+}
+func (c blockingCollectorClient) keepAlive(done <-chan struct{}) {}
+
+func TestMergeIntoHarvestDoesNotBlockOnCollector(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Logger = logger.ShimLogger{}
+	client := blockingCollectorClient{release: make(chan struct{})}
+	app := &app{
+		config:         config{Config: cfg},
+		pinpointClient: client,
+		sendQueue:      newSendQueue(2, 1, cfg.Logger),
+		Logger:         cfg.Logger,
+	}
+	app.sendQueue.start()
+
+	finished := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			txn := &txn{app: app, appRun: &appRun{Config: app.config}}
+			txn.IsAsync = true
+			txn.MergeIntoHarvest(nil)
+		}
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("MergeIntoHarvest blocked on the collector")
+	}
+
+	close(client.release)
+	if err := app.sendQueue.shutdown(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	stats := app.sendQueue.stats()
+	if stats.Dropped == 0 || stats.Queued+stats.Dropped != 10 || stats.Sent != stats.Queued {
+		t.Errorf("%+v", stats)
+	}
+}
@@ -0,0 +1,148 @@
+package pinpoint
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
+)
+
+const (
+	// serviceKindHTTP are the patterns of the handlers wrapped by
+	// WrapHandle and WrapHandleFunc.
+	serviceKindHTTP = "net/http"
+
+	maskedValue = "****"
+)
+
+var (
+	// secretArgPattern matches the names of the command line flags whose
+	// values are masked.
+	secretArgPattern = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|credential|key)`)
+	// urlPasswordPattern matches the password of the user info in urls.
+	urlPasswordPattern = regexp.MustCompile(`(://[^:/@\s]+:)[^@/\s]+@`)
+)
+
+// serviceRegistry records the service entry points of the application by
+// kind.  The zero value is ready to use.
+type serviceRegistry struct {
+	sync.Mutex
+	services map[string]map[string]struct{}
+}
+
+// record adds the entry point name of kind.  At most maxServiceEntryPoints
+// entry points are kept per kind.
+func (r *serviceRegistry) record(kind string, name string) {
+	r.Lock()
+	defer r.Unlock()
+	if nil == r.services {
+		r.services = make(map[string]map[string]struct{})
+	}
+	names := r.services[kind]
+	if nil == names {
+		names = make(map[string]struct{})
+		r.services[kind] = names
+	}
+	if len(names) < maxServiceEntryPoints {
+		names[name] = struct{}{}
+	}
+}
+
+// serviceInfos returns one TServiceInfo per kind listing its entry points,
+// both sorted.
+func (r *serviceRegistry) serviceInfos() []*pinpoint.TServiceInfo {
+	r.Lock()
+	defer r.Unlock()
+	infos := make([]*pinpoint.TServiceInfo, 0, len(r.services))
+	for kind, names := range r.services {
+		kind := kind
+		libs := make([]string, 0, len(names))
+		for name := range names {
+			libs = append(libs, name)
+		}
+		sort.Strings(libs)
+		infos = append(infos, &pinpoint.TServiceInfo{ServiceName: &kind, ServiceLibs: libs})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].GetServiceName() < infos[j].GetServiceName()
+	})
+	return infos
+}
+
+// RecordService implements internal.ServiceRecorder.
+func (app *app) RecordService(kind string, name string) {
+	if nil == app {
+		return
+	}
+	app.services.record(kind, name)
+}
+
+// agentInfo returns the agent info with the current server metadata.
+func (app *app) agentInfo() *pinpoint.TAgentInfo {
+	agentInfo := app.tagentInfo
+	agentInfo.ServerMetaData = app.serverMetaData()
+	return &agentInfo
+}
+
+// serverMetaData describes the running binary: its runtime, its command line
+// with secrets masked, its modules, its labels and its entry points.
+func (app *app) serverMetaData() *pinpoint.TServerMetaData {
+	serverInfo := fmt.Sprintf("Go %s %s/%s GOMAXPROCS=%d",
+		runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.GOMAXPROCS(0))
+
+	var infos []*pinpoint.TServiceInfo
+	if main, deps, ok := readBuildInfo(); ok {
+		infos = append(infos, &pinpoint.TServiceInfo{ServiceName: &main, ServiceLibs: deps})
+	}
+	if len(app.config.Labels) > 0 {
+		name := "labels"
+		labels := make([]string, 0, len(app.config.Labels))
+		for key, val := range app.config.Labels {
+			labels = append(labels, key+"="+val)
+		}
+		sort.Strings(labels)
+		infos = append(infos, &pinpoint.TServiceInfo{ServiceName: &name, ServiceLibs: labels})
+	}
+	infos = append(infos, app.services.serviceInfos()...)
+
+	return &pinpoint.TServerMetaData{
+		ServerInfo:   &serverInfo,
+		VmArgs_:      maskArgs(os.Args),
+		ServiceInfos: infos,
+	}
+}
+
+// maskArgs returns args with the values of the flags named like secrets and
+// the passwords of urls masked.  Both "-flag=value" and "-flag value" are
+// recognized.
+func maskArgs(args []string) []string {
+	masked := make([]string, len(args))
+	maskNext := false
+	for i, arg := range args {
+		if maskNext {
+			maskNext = false
+			if !strings.HasPrefix(arg, "-") {
+				masked[i] = maskedValue
+				continue
+			}
+		}
+		masked[i] = urlPasswordPattern.ReplaceAllString(arg, "${1}"+maskedValue+"@")
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if eq := strings.IndexByte(name, '='); eq >= 0 {
+			if secretArgPattern.MatchString(name[:eq]) {
+				masked[i] = arg[:len(arg)-len(name)+eq+1] + maskedValue
+			}
+		} else if secretArgPattern.MatchString(name) {
+			maskNext = true
+		}
+	}
+	return masked
+}
@@ -0,0 +1,79 @@
+package pinpoint
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestMaskArgs(t *testing.T) {
+	args := []string{
+		"/usr/bin/server",
+		"-port=8080",
+		"--db-password=hunter2",
+		"-apiToken", "abc",
+		"-secret-file", "-verbose",
+		"-dsn", "mysql://user:hunter2@db:3306/app",
+		"positional",
+	}
+	want := []string{
+		"/usr/bin/server",
+		"-port=8080",
+		"--db-password=****",
+		"-apiToken", "****",
+		"-secret-file", "-verbose",
+		"-dsn", "mysql://user:****@db:3306/app",
+		"positional",
+	}
+	if masked := maskArgs(args); !reflect.DeepEqual(masked, want) {
+		t.Error(masked)
+	}
+}
+
+func TestServerMetaData(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Labels = map[string]string{"zone": "a", "env": "prod"}
+	app := &app{config: config{Config: cfg}}
+	WrapHandle(&Application{app: app}, "/users", http.NotFoundHandler())
+	WrapHandleFunc(&Application{app: app}, "/orders", func(http.ResponseWriter, *http.Request) {})
+	app.RecordService("grpc", "helloworld.Greeter/SayHello")
+	app.RecordService("grpc", "helloworld.Greeter/SayHello")
+
+	metaData := app.agentInfo().ServerMetaData
+	if !strings.Contains(metaData.GetServerInfo(), runtime.Version()) || !strings.Contains(metaData.GetServerInfo(), runtime.GOOS+"/"+runtime.GOARCH) {
+		t.Error(metaData.GetServerInfo())
+	}
+	if len(metaData.VmArgs_) == 0 {
+		t.Error(metaData.VmArgs_)
+	}
+
+	services := make(map[string][]string)
+	var names []string
+	for _, info := range metaData.ServiceInfos {
+		services[info.GetServiceName()] = info.ServiceLibs
+		names = append(names, info.GetServiceName())
+	}
+	if !reflect.DeepEqual(services["labels"], []string{"env=prod", "zone=a"}) ||
+		!reflect.DeepEqual(services[serviceKindHTTP], []string{"/orders", "/users"}) ||
+		!reflect.DeepEqual(services["grpc"], []string{"helloworld.Greeter/SayHello"}) {
+		t.Error(services)
+	}
+	if n := len(names); n < 3 || names[n-2] != "grpc" || names[n-1] != serviceKindHTTP {
+		t.Error(names)
+	}
+	if nil != app.tagentInfo.ServerMetaData {
+		t.Error("agent info modified")
+	}
+}
+
+func TestServiceRegistryLimit(t *testing.T) {
+	var r serviceRegistry
+	for i := 0; i < maxServiceEntryPoints+10; i++ {
+		r.record("grpc", string(rune('a'+i%26))+strings.Repeat("x", i))
+	}
+	if infos := r.serviceInfos(); len(infos) != 1 || len(infos[0].ServiceLibs) != maxServiceEntryPoints {
+		t.Error(len(infos))
+	}
+}
@@ -0,0 +1,123 @@
+package pinpoint
+
+import (
+	"sort"
+
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/io"
+)
+
+// spanPacketOverhead bounds the bytes a packet adds to its span or span chunk
+// and their events: the packet header and the header of the event list.
+const spanPacketOverhead = 16
+
+// spanPacketLimits bounds the span events sent in a single span or span
+// chunk packet.  A limit which is not positive is not applied.
+type spanPacketLimits struct {
+	maxEvents int
+	maxBytes  int
+}
+
+func (limits spanPacketLimits) fits(events int, bytes int) bool {
+	if limits.maxEvents > 0 && events > limits.maxEvents {
+		return false
+	}
+	if limits.maxBytes > 0 && bytes > limits.maxBytes {
+		return false
+	}
+	return true
+}
+
+// splitTSpan keeps as many span events in tspan as fit into one packet and
+// moves the others into span chunks of the same transaction and span.  The
+// events are ordered by sequence first, so that the root event stays in the
+// span.
+func splitTSpan(tspan *trace.TSpan, limits spanPacketLimits) ([]*trace.TSpanChunk, error) {
+	events := tspan.SpanEventList
+	tspan.SpanEventList = nil
+	spanSize, err := io.EncodedSize(tspan)
+	tspan.SpanEventList = events
+	if err != nil {
+		return nil, err
+	}
+
+	template := &trace.TSpanChunk{
+		AgentId:                tspan.AgentId,
+		ApplicationName:        tspan.ApplicationName,
+		AgentStartTime:         tspan.AgentStartTime,
+		ServiceType:            tspan.ServiceType,
+		TransactionId:          tspan.TransactionId,
+		SpanId:                 tspan.SpanId,
+		EndPoint:               tspan.EndPoint,
+		ApplicationServiceType: tspan.ApplicationServiceType,
+		KeyTime:                &tspan.StartTime,
+		Version:                tspan.Version,
+	}
+	chunkSize, err := io.EncodedSize(template)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Sequence < events[j].Sequence
+	})
+	groups, err := groupSpanEvents(events, spanSize, chunkSize, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	tspan.SpanEventList = groups[0]
+	return newTSpanChunks(template, groups[1:]), nil
+}
+
+// splitTSpanChunk splits a span chunk whose events do not fit into one
+// packet into several span chunks.
+func splitTSpanChunk(tspanChunk *trace.TSpanChunk, limits spanPacketLimits) ([]*trace.TSpanChunk, error) {
+	events := tspanChunk.SpanEventList
+	tspanChunk.SpanEventList = nil
+	chunkSize, err := io.EncodedSize(tspanChunk)
+	tspanChunk.SpanEventList = events
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := groupSpanEvents(events, chunkSize, chunkSize, limits)
+	if err != nil {
+		return nil, err
+	}
+	return newTSpanChunks(tspanChunk, groups), nil
+}
+
+func newTSpanChunks(template *trace.TSpanChunk, groups [][]*trace.TSpanEvent) []*trace.TSpanChunk {
+	tspanChunks := make([]*trace.TSpanChunk, 0, len(groups))
+	for _, group := range groups {
+		tspanChunk := *template
+		tspanChunk.SpanEventList = group
+		tspanChunks = append(tspanChunks, &tspanChunk)
+	}
+	return tspanChunks
+}
+
+// groupSpanEvents splits events into groups that fit into one packet, the
+// first one of firstSize bytes without events and the others of size bytes.
+// An event too large for any packet is sent on its own.  At least one group
+// is returned.
+func groupSpanEvents(events []*trace.TSpanEvent, firstSize int, size int, limits spanPacketLimits) ([][]*trace.TSpanEvent, error) {
+	groups := [][]*trace.TSpanEvent{}
+	group := []*trace.TSpanEvent{}
+	groupSize := firstSize + spanPacketOverhead
+	for _, event := range events {
+		eventSize, err := io.EncodedSize(event)
+		if err != nil {
+			return nil, err
+		}
+		if len(group) > 0 && !limits.fits(len(group)+1, groupSize+eventSize) {
+			groups = append(groups, group)
+			group = []*trace.TSpanEvent{}
+			groupSize = size + spanPacketOverhead
+		}
+		group = append(group, event)
+		groupSize += eventSize
+	}
+	return append(groups, group), nil
+}
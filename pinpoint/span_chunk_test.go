@@ -0,0 +1,158 @@
+package pinpoint
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/io"
+)
+
+func testTSpan(events int, annotation string) *trace.TSpan {
+	tspan := &trace.TSpan{
+		AgentId:         "agent",
+		ApplicationName: "app",
+		AgentStartTime:  1234,
+		TransactionId:   encodeTraceID("agent", 1234, 1),
+		SpanId:          99,
+		ParentSpanId:    -1,
+		StartTime:       5678,
+		ServiceType:     io.ServiceTypeGo,
+	}
+	// The root event is last, as built by getTSpanEventList.
+	for i := 1; i <= events; i++ {
+		value := annotation
+		tspan.SpanEventList = append(tspan.SpanEventList, &trace.TSpanEvent{
+			Sequence:   int16(i),
+			Depth:      2,
+			NextSpanId: -1,
+			Annotations: []*trace.TAnnotation{{
+				Key:   io.TAnnotationAPI,
+				Value: &trace.TAnnotationValue{StringValue: &value},
+			}},
+		})
+	}
+	tspan.SpanEventList = append(tspan.SpanEventList, &trace.TSpanEvent{
+		Sequence:   0,
+		Depth:      1,
+		NextSpanId: -1,
+	})
+	return tspan
+}
+
+func TestSplitTSpanWithinLimits(t *testing.T) {
+	tspan := testTSpan(3, "event")
+	tspanChunks, err := splitTSpan(tspan, spanPacketLimits{maxEvents: 4, maxBytes: 65000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tspanChunks) != 0 || len(tspan.SpanEventList) != 4 {
+		t.Error(len(tspanChunks), len(tspan.SpanEventList))
+	}
+}
+
+func TestSplitTSpanMaxEvents(t *testing.T) {
+	tspan := testTSpan(9, "event")
+	tspanChunks, err := splitTSpan(tspan, spanPacketLimits{maxEvents: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tspan.SpanEventList) != 4 || tspan.SpanEventList[0].Sequence != 0 {
+		t.Error(tspan.SpanEventList)
+	}
+	if len(tspanChunks) != 2 || len(tspanChunks[0].SpanEventList) != 4 || len(tspanChunks[1].SpanEventList) != 2 {
+		t.Fatal(tspanChunks)
+	}
+
+	sequence := int16(0)
+	for _, events := range [][]*trace.TSpanEvent{tspan.SpanEventList, tspanChunks[0].SpanEventList, tspanChunks[1].SpanEventList} {
+		for _, event := range events {
+			if event.Sequence != sequence {
+				t.Error(event.Sequence, sequence)
+			}
+			sequence++
+		}
+	}
+	for _, tspanChunk := range tspanChunks {
+		if !bytes.Equal(tspanChunk.TransactionId, tspan.TransactionId) || tspanChunk.SpanId != tspan.SpanId ||
+			tspanChunk.AgentId != tspan.AgentId || tspanChunk.GetKeyTime() != tspan.StartTime {
+			t.Error(tspanChunk)
+		}
+	}
+}
+
+func TestSplitTSpanMaxBytes(t *testing.T) {
+	maxBytes := 4000
+	tspan := testTSpan(20, strings.Repeat("x", 500))
+	tspanChunks, err := splitTSpan(tspan, spanPacketLimits{maxBytes: maxBytes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tspanChunks) < 2 {
+		t.Fatal(len(tspanChunks))
+	}
+
+	data, err := io.EncodeTstruct(io.TTypeSpan, tspan)
+	if err != nil || len(data) > maxBytes {
+		t.Error(len(data), err)
+	}
+	events := len(tspan.SpanEventList)
+	for _, tspanChunk := range tspanChunks {
+		data, err := io.EncodeTstruct(io.TTypeSpanChunk, tspanChunk)
+		if err != nil || len(data) > maxBytes {
+			t.Error(len(data), err)
+		}
+		events += len(tspanChunk.SpanEventList)
+	}
+	if events != 21 {
+		t.Error(events)
+	}
+}
+
+func TestSplitTSpanOversizedEvent(t *testing.T) {
+	tspan := testTSpan(2, strings.Repeat("x", 2000))
+	tspanChunks, err := splitTSpan(tspan, spanPacketLimits{maxBytes: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every event is sent, events too large on their own.
+	if len(tspan.SpanEventList) != 1 || len(tspanChunks) != 2 ||
+		len(tspanChunks[0].SpanEventList) != 1 || len(tspanChunks[1].SpanEventList) != 1 {
+		t.Error(tspan.SpanEventList, tspanChunks)
+	}
+}
+
+func TestSplitTSpanChunk(t *testing.T) {
+	asyncID := int32(3)
+	tspanChunk := &trace.TSpanChunk{
+		AgentId:       "agent",
+		TransactionId: encodeTraceID("agent", 1234, 1),
+		SpanId:        99,
+		ServiceType:   io.ServiceTypeGoAsyncMethod,
+	}
+	for i := 0; i < 5; i++ {
+		tspanChunk.SpanEventList = append(tspanChunk.SpanEventList, &trace.TSpanEvent{
+			Sequence: int16(i),
+			AsyncId:  &asyncID,
+		})
+	}
+
+	tspanChunks, err := splitTSpanChunk(tspanChunk, spanPacketLimits{maxEvents: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tspanChunks) != 3 {
+		t.Fatal(len(tspanChunks))
+	}
+	for i, c := range tspanChunks {
+		if c.SpanId != 99 || c.ServiceType != io.ServiceTypeGoAsyncMethod || c.SpanEventList[0].Sequence != int16(2*i) {
+			t.Error(c)
+		}
+	}
+
+	tspanChunks, err = splitTSpanChunk(tspanChunk, spanPacketLimits{})
+	if err != nil || len(tspanChunks) != 1 || len(tspanChunks[0].SpanEventList) != 5 {
+		t.Error(tspanChunks, err)
+	}
+}
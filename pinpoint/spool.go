@@ -0,0 +1,303 @@
+package pinpoint
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// spoolKind is the kind of a spooled packet, which decides how it is sent
+// when the spool is replayed.
+type spoolKind string
+
+const (
+	// spoolMeta packets are metadata requests sent over tcp.
+	spoolMeta spoolKind = "meta"
+	// spoolSpan packets are spans and span chunks sent over udp.
+	spoolSpan spoolKind = "span"
+
+	spoolFileSuffix = ".pkt"
+)
+
+var errSpoolPacketTooLarge = errors.New("packet exceeds the spool size")
+
+// diskSpool keeps encoded packets in a directory while the collector is
+// unreachable, one file per packet.  The spool holds at most maxSize bytes,
+// evicting the oldest packets first, and drops packets older than ttl.
+// Packets left by a previous run of the agent are replayed as well.
+type diskSpool struct {
+	dir     string
+	maxSize int64
+	ttl     time.Duration
+	log     Logger
+
+	sync.Mutex
+	// entries are the spooled packets, oldest first.
+	entries []spoolEntry
+	size    int64
+	seq     uint64
+	metas   int
+}
+
+type spoolEntry struct {
+	name    string
+	kind    spoolKind
+	created time.Time
+	size    int64
+}
+
+// spoolFileName encodes the sequence number, creation time and kind of a
+// packet, so that the spool can be restored from the directory alone.
+func spoolFileName(seq uint64, created time.Time, kind spoolKind) string {
+	return fmt.Sprintf("%020d-%d-%s%s", seq, created.UnixNano(), kind, spoolFileSuffix)
+}
+
+func parseSpoolFileName(name string) (seq uint64, entry spoolEntry, ok bool) {
+	if !strings.HasSuffix(name, spoolFileSuffix) {
+		return 0, entry, false
+	}
+	parts := strings.Split(strings.TrimSuffix(name, spoolFileSuffix), "-")
+	if len(parts) != 3 {
+		return 0, entry, false
+	}
+	seq, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, entry, false
+	}
+	nanos, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, entry, false
+	}
+	kind := spoolKind(parts[2])
+	if kind != spoolMeta && kind != spoolSpan {
+		return 0, entry, false
+	}
+	return seq, spoolEntry{name: name, kind: kind, created: time.Unix(0, nanos)}, true
+}
+
+// newDiskSpool opens the spool in dir, creating the directory if needed.
+func newDiskSpool(dir string, maxSize int64, ttl time.Duration, log Logger) (*diskSpool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	spool := &diskSpool{
+		dir:     dir,
+		maxSize: maxSize,
+		ttl:     ttl,
+		log:     log,
+	}
+	seqs := make(map[string]uint64, len(files))
+	for _, file := range files {
+		seq, entry, ok := parseSpoolFileName(file.Name())
+		if !ok || file.IsDir() {
+			continue
+		}
+		entry.size = file.Size()
+		seqs[entry.name] = seq
+		spool.entries = append(spool.entries, entry)
+		if seq > spool.seq {
+			spool.seq = seq
+		}
+	}
+	sort.Slice(spool.entries, func(i, j int) bool {
+		return seqs[spool.entries[i].name] < seqs[spool.entries[j].name]
+	})
+	for _, entry := range spool.entries {
+		spool.size += entry.size
+		if entry.kind == spoolMeta {
+			spool.metas++
+		}
+	}
+
+	spool.Lock()
+	spool.evictLocked(time.Now(), 0)
+	spool.Unlock()
+	return spool, nil
+}
+
+// put stores the packet data, evicting the oldest packets if the spool would
+// exceed its size.
+func (spool *diskSpool) put(kind spoolKind, data []byte) error {
+	size := int64(len(data))
+	if size > spool.maxSize {
+		return errSpoolPacketTooLarge
+	}
+
+	spool.Lock()
+	defer spool.Unlock()
+
+	now := time.Now()
+	spool.evictLocked(now, size)
+
+	spool.seq++
+	entry := spoolEntry{
+		name:    spoolFileName(spool.seq, now, kind),
+		kind:    kind,
+		created: now,
+		size:    size,
+	}
+	// Write to a temporary file first, so that an interrupted write does
+	// not leave a truncated packet to be replayed.
+	path := filepath.Join(spool.dir, entry.name)
+	if err := ioutil.WriteFile(path+".tmp", data, 0600); err != nil {
+		os.Remove(path + ".tmp")
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		os.Remove(path + ".tmp")
+		return err
+	}
+
+	spool.entries = append(spool.entries, entry)
+	spool.size += size
+	if kind == spoolMeta {
+		spool.metas++
+	}
+	return nil
+}
+
+// evictLocked removes expired packets and then the oldest packets until
+// there is room for another extra bytes.
+func (spool *diskSpool) evictLocked(now time.Time, extra int64) {
+	for len(spool.entries) > 0 {
+		oldest := spool.entries[0]
+		expired := spool.ttl > 0 && now.Sub(oldest.created) > spool.ttl
+		if !expired && spool.size+extra <= spool.maxSize {
+			return
+		}
+		spool.forgetLocked(oldest)
+		spool.entries = spool.entries[1:]
+		if spool.log.DebugEnabled() {
+			spool.log.Debug("spooled packet evicted", map[string]interface{}{
+				"file":    oldest.name,
+				"expired": expired,
+			})
+		}
+	}
+}
+
+// forgetLocked deletes the file of entry and takes it out of the spool
+// totals.  The caller takes it out of the entries.
+func (spool *diskSpool) forgetLocked(entry spoolEntry) {
+	os.Remove(filepath.Join(spool.dir, entry.name))
+	spool.size -= entry.size
+	if entry.kind == spoolMeta {
+		spool.metas--
+	}
+}
+
+// removeLocked deletes the packets named in names which are still spooled,
+// in a single pass over the entries.
+func (spool *diskSpool) removeLocked(names map[string]bool) {
+	kept := spool.entries[:0]
+	for _, entry := range spool.entries {
+		if names[entry.name] {
+			spool.forgetLocked(entry)
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	for i := len(kept); i < len(spool.entries); i++ {
+		spool.entries[i] = spoolEntry{}
+	}
+	spool.entries = kept
+}
+
+// len returns the number of spooled packets.
+func (spool *diskSpool) len() int {
+	spool.Lock()
+	defer spool.Unlock()
+	return len(spool.entries)
+}
+
+// hasMeta reports whether metadata packets are spooled.  Spans must then be
+// spooled too, since they may refer to metadata the collector has not
+// received yet.
+func (spool *diskSpool) hasMeta() bool {
+	spool.Lock()
+	defer spool.Unlock()
+	return spool.metas > 0
+}
+
+// pending returns the packets to replay: the metadata packets first, then the
+// spans, each oldest first.
+func (spool *diskSpool) pending() []spoolEntry {
+	spool.Lock()
+	defer spool.Unlock()
+
+	spool.evictLocked(time.Now(), 0)
+	entries := make([]spoolEntry, 0, len(spool.entries))
+	for _, kind := range []spoolKind{spoolMeta, spoolSpan} {
+		for _, entry := range spool.entries {
+			if entry.kind == kind {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// replay sends the spooled packets with send, deleting each one which was
+// sent, until the spool is empty or send fails.  Packets spooled while
+// replaying are sent as well.  It returns the number of packets sent.
+func (spool *diskSpool) replay(send func(kind spoolKind, data []byte) error) (int, error) {
+	sent := 0
+	for {
+		entries := spool.pending()
+		if len(entries) == 0 {
+			return sent, nil
+		}
+		n, err := spool.replayEntries(entries, send)
+		sent += n
+		if err != nil {
+			return sent, err
+		}
+	}
+}
+
+// replayEntries sends entries until send fails.  The file of every packet
+// done with is deleted right away, so that it is not sent again if the agent
+// stops, and the entries are updated once for the whole batch.
+func (spool *diskSpool) replayEntries(entries []spoolEntry, send func(kind spoolKind, data []byte) error) (int, error) {
+	sent := 0
+	done := make(map[string]bool, len(entries))
+	defer func() {
+		spool.Lock()
+		spool.removeLocked(done)
+		spool.Unlock()
+	}()
+
+	for _, entry := range entries {
+		path := filepath.Join(spool.dir, entry.name)
+		data, err := ioutil.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+			// Evicted since pending returned it.
+		case err != nil:
+			spool.log.Warn("unable to read spooled packet", map[string]interface{}{
+				"file": entry.name,
+				"err":  err.Error(),
+			})
+		default:
+			if err := send(entry.kind, data); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+		os.Remove(path)
+		done[entry.name] = true
+	}
+	return sent, nil
+}
@@ -0,0 +1,159 @@
+package pinpoint
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dingyalin/pinpoint-go-agent/internal/logger"
+)
+
+func newTestSpool(t *testing.T, maxSize int64, ttl time.Duration) (*diskSpool, func()) {
+	dir, err := ioutil.TempDir("", "pinpoint-spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spool, err := newDiskSpool(dir, maxSize, ttl, logger.ShimLogger{})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return spool, func() { os.RemoveAll(dir) }
+}
+
+// replayAll replays spool and returns the packets in the order sent.
+func replayAll(t *testing.T, spool *diskSpool) []string {
+	var packets []string
+	_, err := spool.replay(func(kind spoolKind, data []byte) error {
+		packets = append(packets, string(kind)+":"+string(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return packets
+}
+
+func TestDiskSpoolReplayOrder(t *testing.T) {
+	spool, cleanup := newTestSpool(t, 1024, time.Hour)
+	defer cleanup()
+
+	spool.put(spoolSpan, []byte("span1"))
+	spool.put(spoolMeta, []byte("api1"))
+	spool.put(spoolSpan, []byte("span2"))
+	spool.put(spoolMeta, []byte("api2"))
+	if spool.len() != 4 || !spool.hasMeta() {
+		t.Fatal(spool.len(), spool.hasMeta())
+	}
+
+	packets := replayAll(t, spool)
+	want := []string{"meta:api1", "meta:api2", "span:span1", "span:span2"}
+	if !reflect.DeepEqual(packets, want) {
+		t.Error(packets)
+	}
+	if spool.len() != 0 || spool.hasMeta() || spool.size != 0 {
+		t.Error(spool.len(), spool.hasMeta(), spool.size)
+	}
+	if files, _ := ioutil.ReadDir(spool.dir); len(files) != 0 {
+		t.Error(len(files))
+	}
+}
+
+func TestDiskSpoolReplayInterrupted(t *testing.T) {
+	spool, cleanup := newTestSpool(t, 1024, time.Hour)
+	defer cleanup()
+
+	spool.put(spoolMeta, []byte("api1"))
+	spool.put(spoolSpan, []byte("span1"))
+	errDown := errors.New("collector down")
+	sent, err := spool.replay(func(kind spoolKind, data []byte) error {
+		if kind == spoolSpan {
+			return errDown
+		}
+		return nil
+	})
+	if sent != 1 || err != errDown {
+		t.Error(sent, err)
+	}
+	if spool.len() != 1 || spool.hasMeta() || spool.size != int64(len("span1")) {
+		t.Error(spool.len(), spool.hasMeta(), spool.size)
+	}
+	if packets := replayAll(t, spool); !reflect.DeepEqual(packets, []string{"span:span1"}) {
+		t.Error(packets)
+	}
+}
+
+func TestDiskSpoolEviction(t *testing.T) {
+	spool, cleanup := newTestSpool(t, 10, time.Hour)
+	defer cleanup()
+
+	spool.put(spoolMeta, []byte("aaaa"))
+	spool.put(spoolSpan, []byte("bbbb"))
+	spool.put(spoolSpan, []byte("cccc"))
+	if spool.len() != 2 || spool.hasMeta() {
+		t.Error(spool.len(), spool.hasMeta())
+	}
+	if err := spool.put(spoolSpan, []byte("too large!!")); err != errSpoolPacketTooLarge {
+		t.Error(err)
+	}
+	if packets := replayAll(t, spool); !reflect.DeepEqual(packets, []string{"span:bbbb", "span:cccc"}) {
+		t.Error(packets)
+	}
+}
+
+func TestDiskSpoolReplayWhilePut(t *testing.T) {
+	spool, cleanup := newTestSpool(t, 1024, time.Hour)
+	defer cleanup()
+
+	spool.put(spoolSpan, []byte("span1"))
+	spool.put(spoolSpan, []byte("span2"))
+	var packets []string
+	_, err := spool.replay(func(kind spoolKind, data []byte) error {
+		if string(data) == "span1" {
+			spool.put(spoolMeta, []byte("api1"))
+		}
+		packets = append(packets, string(kind)+":"+string(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"span:span1", "span:span2", "meta:api1"}; !reflect.DeepEqual(packets, want) {
+		t.Error(packets)
+	}
+	if spool.len() != 0 || spool.hasMeta() || spool.size != 0 {
+		t.Error(spool.len(), spool.hasMeta(), spool.size)
+	}
+}
+
+func TestDiskSpoolRestore(t *testing.T) {
+	spool, cleanup := newTestSpool(t, 1024, time.Hour)
+	defer cleanup()
+
+	spool.put(spoolSpan, []byte("span1"))
+	spool.put(spoolMeta, []byte("api1"))
+	// Packets older than the TTL and unknown files are ignored.
+	expired := filepath.Join(spool.dir, spoolFileName(0, time.Now().Add(-2*time.Hour), spoolSpan))
+	ioutil.WriteFile(expired, []byte("expired"), 0600)
+	ioutil.WriteFile(filepath.Join(spool.dir, "unknown.txt"), []byte("unknown"), 0600)
+
+	restored, err := newDiskSpool(spool.dir, 1024, time.Hour, logger.ShimLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.len() != 2 || !restored.hasMeta() {
+		t.Error(restored.len(), restored.hasMeta())
+	}
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Error(err)
+	}
+	restored.put(spoolSpan, []byte("span2"))
+	packets := replayAll(t, restored)
+	if !reflect.DeepEqual(packets, []string{"meta:api1", "span:span1", "span:span2"}) {
+		t.Error(packets)
+	}
+}
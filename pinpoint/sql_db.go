@@ -0,0 +1,33 @@
+// +build go1.11
+
+package pinpoint
+
+import (
+	"database/sql"
+)
+
+// RegisterSQLDB adds the connection pool of db to the data source panel of
+// the inspector.  On each agent stat the connections in use are reported as
+// the active connections, and the maximum set with db.SetMaxOpenConns as the
+// maximum size, or the open connections if there is no maximum.  product
+// selects the service type of the pool, and url is reported with its
+// password masked.  At most 20 pools are reported.
+func (app *Application) RegisterSQLDB(db *sql.DB, product DatastoreProduct, databaseName string, url string) {
+	if nil == app || nil == app.app || nil == db {
+		return
+	}
+	registered := app.app.dataSources.register(db, product, databaseName, url, func() (int32, int32) {
+		stats := db.Stats()
+		max := stats.MaxOpenConnections
+		if max <= 0 {
+			max = stats.OpenConnections
+		}
+		return int32(stats.InUse), int32(max)
+	})
+	if !registered {
+		app.app.Warn("sql db already registered or too many registered", map[string]interface{}{
+			"databaseName": databaseName,
+			"limit":        maxDataSources,
+		})
+	}
+}
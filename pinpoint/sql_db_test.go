@@ -0,0 +1,46 @@
+// +build go1.11
+
+package pinpoint
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestRegisterSQLDB(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.AppName = "my app"
+	cfg.AgentID = "agent"
+	// Prevent spawning app goroutines in tests.
+	cfg.Enabled = false
+	c, err := newInternalConfig(cfg, func(string) string { return "" }, nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	app := newApplication(newApp(c))
+
+	db := sql.OpenDB(testConnector{})
+	defer db.Close()
+	db.SetMaxOpenConns(5)
+	app.RegisterSQLDB(db, DatastoreMySQL, "orders", "app:secret@tcp(db:3306)/orders")
+	app.RegisterSQLDB(db, DatastoreMySQL, "orders", "app:secret@tcp(db:3306)/orders")
+
+	conn, err := db.Conn(context.Background())
+	if nil != err {
+		t.Fatal(err)
+	}
+	list := app.app.dataSources.sample().DataSourceList
+	if len(list) != 1 || list[0].ActiveConnectionSize != 1 || list[0].GetMaxConnectionSize() != 5 {
+		t.Fatal(list)
+	}
+
+	conn.Close()
+	db.SetMaxOpenConns(0)
+	if source := app.app.dataSources.sample().DataSourceList[0]; source.ActiveConnectionSize != 0 || source.GetMaxConnectionSize() != 1 {
+		t.Error(source)
+	}
+
+	var nilApp *Application
+	nilApp.RegisterSQLDB(db, DatastoreMySQL, "orders", "")
+}
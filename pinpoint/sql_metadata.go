@@ -0,0 +1,132 @@
+package pinpoint
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+const (
+	// sqlNumberMarker and sqlStringMarker follow the index of the literal
+	// a normalized statement was stripped of, like the java agent:
+	// "WHERE id = 0# AND name = 1$".
+	sqlNumberMarker = '#'
+	sqlStringMarker = '$'
+	// sqlOutputSeparator separates the literals in the output parameter of
+	// the sql id annotation.  Separators within literals are doubled.
+	sqlOutputSeparator = ","
+)
+
+// normalizeSQL replaces the number and string literals of sql with their
+// indexes, so that statements differing only by their literals share one
+// sql metadata.  It returns the normalized statement and the literals
+// joined into the output parameter of the sql id annotation.  Comments,
+// quoted identifiers and placeholders are left as they are.
+func normalizeSQL(sql string) (string, string) {
+	var normalized bytes.Buffer
+	var output []string
+	literal := func(value string, marker byte) {
+		normalized.WriteString(strconv.Itoa(len(output)))
+		normalized.WriteByte(marker)
+		output = append(output, strings.Replace(value, sqlOutputSeparator, sqlOutputSeparator+sqlOutputSeparator, -1))
+	}
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case '\'' == c:
+			end, closed := sqlQuoteEnd(sql, i)
+			value := sql[i+1 : end]
+			if closed {
+				value = value[:len(value)-1]
+			}
+			literal(strings.Replace(value, "''", "'", -1), sqlStringMarker)
+			i = end
+		case '"' == c || '`' == c:
+			end, _ := sqlQuoteEnd(sql, i)
+			normalized.WriteString(sql[i:end])
+			i = end
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			normalized.WriteString(sql[i : i+end])
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i
+			} else {
+				end += 4
+			}
+			normalized.WriteString(sql[i : i+end])
+			i += end
+		case isSQLIdentifierByte(c) && !isSQLDigit(c), '$' == c:
+			// Identifiers, keywords and placeholders such as $1 may
+			// contain digits which are not literals.
+			end := i + 1
+			for end < len(sql) && isSQLIdentifierByte(sql[end]) {
+				end++
+			}
+			normalized.WriteString(sql[i:end])
+			i = end
+		case isSQLDigit(c) || ('.' == c && i+1 < len(sql) && isSQLDigit(sql[i+1])):
+			end := sqlNumberEnd(sql, i)
+			literal(sql[i:end], sqlNumberMarker)
+			i = end
+		default:
+			normalized.WriteByte(c)
+			i++
+		}
+	}
+	return normalized.String(), strings.Join(output, sqlOutputSeparator)
+}
+
+// sqlQuoteEnd returns the index after the quote closing the one at start, or
+// the length of sql if the quote is not closed.  Doubled quotes and quotes
+// escaped with a backslash do not close it.
+func sqlQuoteEnd(sql string, start int) (int, bool) {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			i++
+		case quote:
+			if i+1 < len(sql) && quote == sql[i+1] {
+				i++
+				continue
+			}
+			return i + 1, true
+		}
+	}
+	return len(sql), false
+}
+
+// sqlNumberEnd returns the index after the number literal at start, such as
+// 42, 1.5, .5 or 6.02e23.
+func sqlNumberEnd(sql string, start int) int {
+	i := start
+	for i < len(sql) && (isSQLDigit(sql[i]) || '.' == sql[i]) {
+		i++
+	}
+	if i < len(sql) && ('e' == sql[i] || 'E' == sql[i]) {
+		exp := i + 1
+		if exp < len(sql) && ('+' == sql[exp] || '-' == sql[exp]) {
+			exp++
+		}
+		if exp < len(sql) && isSQLDigit(sql[exp]) {
+			for i = exp; i < len(sql) && isSQLDigit(sql[i]); i++ {
+			}
+		}
+	}
+	return i
+}
+
+func isSQLDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isSQLIdentifierByte(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || isSQLDigit(c) || '_' == c || c >= 0x80
+}
@@ -0,0 +1,34 @@
+package pinpoint
+
+import "testing"
+
+func TestNormalizeSQL(t *testing.T) {
+	for _, tc := range []struct {
+		sql        string
+		normalized string
+		output     string
+	}{
+		{"SELECT * FROM users", "SELECT * FROM users", ""},
+		{"SELECT * FROM users WHERE id = 42", "SELECT * FROM users WHERE id = 0#", "42"},
+		{"SELECT * FROM users WHERE name = 'bob' AND age > 1.5", "SELECT * FROM users WHERE name = 0$ AND age > 1#", "bob,1.5"},
+		{"INSERT INTO t2 (a, b) VALUES ('x,y', -6.02e23)", "INSERT INTO t2 (a, b) VALUES (0$, -1#)", "x,,y,6.02e23"},
+		{"SELECT 'it''s', 'a\\'b'", "SELECT 0$, 1$", "it's,a\\'b"},
+		{"SELECT \"col1\", `t1`.x1 FROM t1 WHERE y = ?", "SELECT \"col1\", `t1`.x1 FROM t1 WHERE y = ?", ""},
+		{"SELECT * FROM t WHERE id = $1 AND v = .5", "SELECT * FROM t WHERE id = $1 AND v = 0#", ".5"},
+		{"SELECT 1 /* limit 10 */ -- 'x' 2\nFROM dual", "SELECT 0# /* limit 10 */ -- 'x' 2\nFROM dual", "1"},
+		{"SELECT 'unterminated", "SELECT 0$", "unterminated"},
+	} {
+		normalized, output := normalizeSQL(tc.sql)
+		if normalized != tc.normalized || output != tc.output {
+			t.Errorf("%q: got %q %q, want %q %q", tc.sql, normalized, output, tc.normalized, tc.output)
+		}
+	}
+}
+
+func TestNormalizeSQLSharesLiterals(t *testing.T) {
+	first, _ := normalizeSQL("SELECT * FROM t WHERE id = 1 AND name = 'a'")
+	second, _ := normalizeSQL("SELECT * FROM t WHERE id = 22 AND name = 'bb'")
+	if first != second {
+		t.Error(first, second)
+	}
+}
@@ -0,0 +1,193 @@
+package pinpoint
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Values of Config.Sampling.Type.
+const (
+	// SamplingTypeCounter samples one in Config.SamplingRate of the new
+	// traces.
+	SamplingTypeCounter = "counter"
+	// SamplingTypePercent samples Config.Sampling.Percent percent of the
+	// new traces at random.
+	SamplingTypePercent = "percent"
+	// SamplingTypeThroughput samples at most Config.Sampling.PerSecond new
+	// traces per second.
+	SamplingTypeThroughput = "throughput"
+	// SamplingTypeAdaptive adjusts the share of the new traces sampled
+	// every minute to sample about Config.Sampling.PerMinute of them.
+	SamplingTypeAdaptive = "adaptive"
+)
+
+// SamplingRequest describes a transaction starting a new trace.
+type SamplingRequest struct {
+	// Name is the name the transaction was started with.
+	Name string
+	// Method and Path are those of the request of web transactions, and
+	// empty for other transactions.
+	Method string
+	Path   string
+}
+
+// Sampler decides which of the transactions starting a new trace are
+// sampled.  Transactions continuing a trace follow the decision of their
+// caller instead.  IsSampled is called concurrently.
+type Sampler interface {
+	IsSampled(req SamplingRequest) bool
+}
+
+// SamplingRule sets the percentage of the new traces sampled for the
+// transactions it matches.
+type SamplingRule struct {
+	// Name, Path and Method match the transaction name, the request path
+	// and the request method.  Empty fields match anything.  Name and Path
+	// ending with "*" match by prefix.  Method is case insensitive.
+	Name   string
+	Path   string
+	Method string
+	// Percent of the matching transactions sampled, from 0, never, to
+	// 100, always.
+	Percent float64
+}
+
+func (rule SamplingRule) matches(req SamplingRequest) bool {
+	return samplingPatternMatches(rule.Name, req.Name) &&
+		samplingPatternMatches(rule.Path, req.Path) &&
+		("" == rule.Method || strings.EqualFold(rule.Method, req.Method))
+}
+
+func samplingPatternMatches(pattern string, s string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(s, pattern[:len(pattern)-1])
+	}
+	return "" == pattern || pattern == s
+}
+
+type counterSampler struct {
+	rate    int64
+	counter int64
+}
+
+// NewCounterSampler returns a Sampler sampling one in rate of the new traces,
+// starting with the first.  Rates below 2 sample every trace.
+func NewCounterSampler(rate int) Sampler {
+	return &counterSampler{rate: int64(rate)}
+}
+
+func (s *counterSampler) IsSampled(req SamplingRequest) bool {
+	if s.rate <= 1 {
+		return true
+	}
+	n := atomic.AddInt64(&s.counter, 1)
+	return (n-1)%s.rate == 0
+}
+
+type percentSampler struct {
+	percent float64
+}
+
+// NewPercentSampler returns a Sampler sampling percent percent of the new
+// traces at random.
+func NewPercentSampler(percent float64) Sampler {
+	return percentSampler{percent: percent}
+}
+
+func (s percentSampler) IsSampled(req SamplingRequest) bool {
+	return samplePercent(s.percent)
+}
+
+func samplePercent(percent float64) bool {
+	switch {
+	case percent <= 0:
+		return false
+	case percent >= 100:
+		return true
+	}
+	return rand.Float64()*100 < percent
+}
+
+// throughputSampler is a token bucket holding up to perSecond tokens, which
+// it is refilled with every second.
+type throughputSampler struct {
+	sync.Mutex
+	perSecond float64
+	tokens    float64
+	last      time.Time
+	now       func() time.Time
+}
+
+// NewThroughputSampler returns a Sampler sampling at most perSecond new traces
+// per second.  Up to perSecond traces are sampled in a burst.
+func NewThroughputSampler(perSecond int) Sampler {
+	return &throughputSampler{
+		perSecond: float64(perSecond),
+		tokens:    float64(perSecond),
+		now:       time.Now,
+	}
+}
+
+func (s *throughputSampler) IsSampled(req SamplingRequest) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.now()
+	if !s.last.IsZero() {
+		s.tokens += now.Sub(s.last).Seconds() * s.perSecond
+		if s.tokens > s.perSecond {
+			s.tokens = s.perSecond
+		}
+	}
+	s.last = now
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+type ruleSampler struct {
+	rules    []SamplingRule
+	fallback Sampler
+}
+
+// NewRuleSampler returns a Sampler deciding with the first of rules matching
+// the transaction, and with fallback when none does.
+func NewRuleSampler(rules []SamplingRule, fallback Sampler) Sampler {
+	return ruleSampler{rules: rules, fallback: fallback}
+}
+
+func (s ruleSampler) IsSampled(req SamplingRequest) bool {
+	for _, rule := range s.rules {
+		if rule.matches(req) {
+			return samplePercent(rule.Percent)
+		}
+	}
+	return s.fallback.IsSampled(req)
+}
+
+// newSampler returns the sampler configured by c: Config.Sampling.Sampler or
+// the one of Config.Sampling.Type, behind the Config.Sampling.Rules.
+func newSampler(c Config) Sampler {
+	sampler := c.Sampling.Sampler
+	if nil == sampler {
+		switch c.Sampling.Type {
+		case SamplingTypePercent:
+			sampler = NewPercentSampler(c.Sampling.Percent)
+		case SamplingTypeThroughput:
+			sampler = NewThroughputSampler(c.Sampling.PerSecond)
+		case SamplingTypeAdaptive:
+			sampler = newTargetSampler(c.Sampling.PerMinute, c.Logger)
+		default:
+			sampler = NewCounterSampler(c.SamplingRate)
+		}
+	}
+	if len(c.Sampling.Rules) > 0 {
+		sampler = NewRuleSampler(c.Sampling.Rules, sampler)
+	}
+	return sampler
+}
@@ -0,0 +1,128 @@
+package pinpoint
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCounterSampler(t *testing.T) {
+	s := NewCounterSampler(3)
+	var got []bool
+	for i := 0; i < 6; i++ {
+		got = append(got, s.IsSampled(SamplingRequest{}))
+	}
+	if expect := []bool{true, false, false, true, false, false}; !reflect.DeepEqual(got, expect) {
+		t.Error(got)
+	}
+	s = NewCounterSampler(0)
+	for i := 0; i < 3; i++ {
+		if !s.IsSampled(SamplingRequest{}) {
+			t.Error(i)
+		}
+	}
+}
+
+func TestPercentSampler(t *testing.T) {
+	never := NewPercentSampler(0)
+	always := NewPercentSampler(100)
+	for i := 0; i < 100; i++ {
+		if never.IsSampled(SamplingRequest{}) || !always.IsSampled(SamplingRequest{}) {
+			t.Fatal(i)
+		}
+	}
+}
+
+func TestThroughputSampler(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewThroughputSampler(2).(*throughputSampler)
+	s.now = func() time.Time { return now }
+
+	sampled := func() (n int) {
+		for i := 0; i < 5; i++ {
+			if s.IsSampled(SamplingRequest{}) {
+				n++
+			}
+		}
+		return
+	}
+	if n := sampled(); n != 2 {
+		t.Error(n)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if n := sampled(); n != 1 {
+		t.Error(n)
+	}
+	// the bucket does not hold more than a second of traces
+	now = now.Add(time.Minute)
+	if n := sampled(); n != 2 {
+		t.Error(n)
+	}
+}
+
+func TestSamplingRuleMatches(t *testing.T) {
+	req := SamplingRequest{Name: "GET /users", Method: "GET", Path: "/users/42"}
+	for _, tc := range []struct {
+		rule    SamplingRule
+		matches bool
+	}{
+		{SamplingRule{}, true},
+		{SamplingRule{Path: "/users/42"}, true},
+		{SamplingRule{Path: "/users"}, false},
+		{SamplingRule{Path: "/users/*"}, true},
+		{SamplingRule{Method: "get", Path: "/users/*"}, true},
+		{SamplingRule{Method: "POST", Path: "/users/*"}, false},
+		{SamplingRule{Name: "GET *"}, true},
+		{SamplingRule{Name: "GET /users", Path: "/orders*"}, false},
+	} {
+		if m := tc.rule.matches(req); m != tc.matches {
+			t.Error(tc.rule, m)
+		}
+	}
+}
+
+func TestRuleSampler(t *testing.T) {
+	s := NewRuleSampler([]SamplingRule{
+		{Path: "/healthz", Percent: 0},
+		{Path: "/checkout*", Percent: 100},
+	}, NewCounterSampler(2))
+
+	if s.IsSampled(SamplingRequest{Path: "/healthz"}) {
+		t.Error("healthz sampled")
+	}
+	if !s.IsSampled(SamplingRequest{Path: "/checkout/confirm"}) {
+		t.Error("checkout unsampled")
+	}
+	// unmatched requests fall back to the counter
+	if !s.IsSampled(SamplingRequest{Path: "/"}) || s.IsSampled(SamplingRequest{Path: "/"}) {
+		t.Error("fallback not used")
+	}
+}
+
+type constSampler bool
+
+func (s constSampler) IsSampled(req SamplingRequest) bool { return bool(s) }
+
+func TestNewSampler(t *testing.T) {
+	c := defaultConfig()
+	c.SamplingRate = 5
+	if s, ok := newSampler(c).(*counterSampler); !ok || s.rate != 5 {
+		t.Error(s)
+	}
+	c.Sampling.Type = SamplingTypePercent
+	c.Sampling.Percent = 25
+	if s, ok := newSampler(c).(percentSampler); !ok || s.percent != 25 {
+		t.Error(s)
+	}
+	c.Sampling.Type = SamplingTypeThroughput
+	c.Sampling.PerSecond = 7
+	if s, ok := newSampler(c).(*throughputSampler); !ok || s.perSecond != 7 {
+		t.Error(s)
+	}
+	c.Sampling.Sampler = constSampler(false)
+	c.Sampling.Rules = []SamplingRule{{Path: "/checkout", Percent: 100}}
+	s := newSampler(c)
+	if s.IsSampled(SamplingRequest{Path: "/"}) || !s.IsSampled(SamplingRequest{Path: "/checkout"}) {
+		t.Error(s)
+	}
+}
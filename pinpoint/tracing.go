@@ -89,6 +89,12 @@ type txnData struct {
 	rootSpanID              string
 	rootSpanErrData         *errorData
 	SpanEvents              []*spanEvent
+	// SpanEventLimit bounds SpanEvents below maxSpanEvents when positive.
+	SpanEventLimit int
+	// ShouldRecordSegments reports whether the segments are recorded as
+	// trace segments and span events, or only counted.  They are all
+	// recorded when it is nil.
+	ShouldRecordSegments func() bool
 
 	customSegments    map[string]*metricData
 	datastoreSegments map[datastoreMetricKey]*metricData
@@ -334,6 +340,22 @@ type segmentEnd struct {
 	userAttributes   spanAttributeMap
 }
 
+// recordsSegments reports whether the segments of the transaction are
+// recorded.
+func (t *txnData) recordsSegments() bool {
+	fn := t.ShouldRecordSegments
+	return nil == fn || fn()
+}
+
+// spanEvent returns the span event of end, or nil if the segments of the
+// transaction are not recorded.
+func (t *txnData) spanEvent(end segmentEnd) *spanEvent {
+	if !t.recordsSegments() {
+		return nil
+	}
+	return end.spanEvent()
+}
+
 func (end segmentEnd) spanEvent() *spanEvent {
 	/*
 		if "" == end.SpanID {
@@ -441,7 +463,11 @@ func (t *txnData) CurrentSpanIdentifier(thread *tracingThread) string {
 
 func (t *txnData) saveSpanEvent(e *spanEvent) {
 	e.AgentAttributes = t.Attrs.filterSpanAttributes(e.AgentAttributes, destSpan)
-	if len(t.SpanEvents) < maxSpanEvents {
+	limit := maxSpanEvents
+	if t.SpanEventLimit > 0 && t.SpanEventLimit < limit {
+		limit = t.SpanEventLimit
+	}
+	if len(t.SpanEvents) < limit {
 		t.SpanEvents = append(t.SpanEvents, e)
 	}
 }
@@ -543,12 +569,12 @@ func endBasicSegment(t *txnData, thread *tracingThread, start segmentStartTime,
 		t.customSegments[name] = cpy
 	}
 
-	if t.TxnTrace.considerNode(end) {
+	if t.recordsSegments() && t.TxnTrace.considerNode(end) {
 		attributes := end.agentAttributes.copy()
 		t.saveTraceSegment(end, customSegmentMetric(name), attributes, "")
 	}
 
-	if evt := end.spanEvent(); evt != nil {
+	if evt := t.spanEvent(end); evt != nil {
 		evt.Name = customSegmentMetric(name)
 		evt.Category = spanCategoryGeneric
 		t.saveSpanEvent(evt)
@@ -638,7 +664,7 @@ func endExternalSegment(p endExternalParams) error {
 		t.externalSegments[key] = cpy
 	}
 
-	if t.TxnTrace.considerNode(end) {
+	if t.recordsSegments() && t.TxnTrace.considerNode(end) {
 		attributes := end.agentAttributes.copy()
 		if p.Library == "http" {
 			attributes.addString(SpanAttributeHTTPURL, safeURL(p.URL))
@@ -646,7 +672,7 @@ func endExternalSegment(p endExternalParams) error {
 		t.saveTraceSegment(end, key.scopedMetric(), attributes, transactionGUID)
 	}
 
-	if evt := end.spanEvent(); evt != nil {
+	if evt := t.spanEvent(end); evt != nil {
 		if p.URL != nil {
 			rpc := safeURL(p.URL)
 			evt.rpc = &rpc
@@ -716,12 +742,12 @@ func endMessageSegment(p endMessageParams) error {
 		t.messageSegments[key] = cpy
 	}
 
-	if t.TxnTrace.considerNode(end) {
+	if t.recordsSegments() && t.TxnTrace.considerNode(end) {
 		attributes := end.agentAttributes.copy()
 		t.saveTraceSegment(end, key.Name(), attributes, "")
 	}
 
-	if evt := end.spanEvent(); evt != nil {
+	if evt := t.spanEvent(end); evt != nil {
 		evt.Name = key.Name()
 		evt.Category = spanCategoryGeneric
 		t.saveSpanEvent(evt)
@@ -841,7 +867,7 @@ func endDatastoreSegment(p endDatastoreParams) error {
 	// errors in QueryParameters must not stop the recording of the segment
 	queryParams, err := vetQueryParameters(p.QueryParameters)
 
-	if p.TxnData.TxnTrace.considerNode(end) {
+	if p.TxnData.recordsSegments() && p.TxnData.TxnTrace.considerNode(end) {
 		attributes := end.agentAttributes.copy()
 		attributes.addString(SpanAttributeDBStatement, p.ParameterizedQuery)
 		attributes.addString(SpanAttributeDBInstance, p.Database)
@@ -853,7 +879,7 @@ func endDatastoreSegment(p endDatastoreParams) error {
 		p.TxnData.saveTraceSegment(end, scopedMetric, attributes, "")
 	}
 
-	if p.TxnData.slowQueryWorthy(end.duration) {
+	if p.TxnData.recordsSegments() && p.TxnData.slowQueryWorthy(end.duration) {
 		if nil == p.TxnData.SlowQueries {
 			p.TxnData.SlowQueries = newSlowQueries(maxTxnSlowQueries)
 		}
@@ -869,7 +895,7 @@ func endDatastoreSegment(p endDatastoreParams) error {
 		})
 	}
 
-	if evt := end.spanEvent(); evt != nil {
+	if evt := p.TxnData.spanEvent(end); evt != nil {
 		evt.Name = scopedMetric
 		evt.Category = spanCategoryDatastore
 		evt.Kind = "client"
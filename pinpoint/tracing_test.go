@@ -679,6 +679,25 @@ func TestSpanEventNotCollected(t *testing.T) {
 	}
 }
 
+func TestSpanEventLimit(t *testing.T) {
+	start := time.Date(2014, time.November, 28, 1, 1, 0, 0, time.UTC)
+	txndata := &txnData{
+		TraceIDGenerator:        internal.NewTraceIDGenerator(12345),
+		ShouldCollectSpanEvents: trueFunc,
+		ShouldCreateSpanGUID:    trueFunc,
+		SpanEventLimit:          2,
+	}
+	thread := &tracingThread{}
+
+	for i := 0; i < 3; i++ {
+		t1 := startSegment(txndata, thread, start.Add(1*time.Second))
+		endBasicSegment(txndata, thread, t1, start.Add(3*time.Second), "t1")
+	}
+	if 2 != len(txndata.SpanEvents) {
+		t.Error(len(txndata.SpanEvents))
+	}
+}
+
 func TestDatastoreSpanEventCreation(t *testing.T) {
 	start := time.Date(2014, time.November, 28, 1, 1, 0, 0, time.UTC)
 	txndata := &txnData{
@@ -130,11 +130,12 @@ func (txn *Transaction) SetWebRequestHTTP(r *http.Request) {
 		return
 	}
 	wr := WebRequest{
-		Header:    r.Header,
-		URL:       r.URL,
-		Method:    r.Method,
-		Transport: transport(r),
-		Host:      r.Host,
+		Header:     r.Header,
+		URL:        r.URL,
+		Method:     r.Method,
+		Transport:  transport(r),
+		Host:       r.Host,
+		RemoteAddr: r.RemoteAddr,
 	}
 	txn.SetWebRequest(wr)
 }
@@ -244,6 +245,9 @@ func (txn *Transaction) NextSpanID() int64 {
 // distributed tracing header, but can be configured based on the
 // Config.DistributedTracer.ExcludeNewRelicHeader option.
 //
+// Transactions which are not sampled only insert the Pinpoint-Sampled header,
+// so that the services they call do not sample the trace either.
+//
 // StartExternalSegment calls InsertDistributedTraceHeaders, so you don't need
 // to use it for outbound HTTP calls: Just use StartExternalSegment!
 func (txn *Transaction) InsertDistributedTraceHeaders(hdrs http.Header, nextSpanID int64) {
@@ -253,7 +257,7 @@ func (txn *Transaction) InsertDistributedTraceHeaders(hdrs http.Header, nextSpan
 	if nil == txn.thread {
 		return
 	}
-	txn.thread.CreateDistributedTracePayload(hdrs, nextSpanID)
+	txn.thread.CreateDistributedTracePayload(hdrs, nextSpanID, "")
 }
 
 // AcceptDistributedTraceHeaders links transactions by accepting distributed
@@ -433,6 +437,10 @@ type WebRequest struct {
 	// This is the value of the `Host` header. Go does not add it to the
 	// http.Header object and so must be passed separately.
 	Host string
+	// RemoteAddr is the network address of the peer which sent the
+	// request, usually "IP:port".  It is reported as the remote address of
+	// the span unless Config.RemoteAddrHeader is found in Header.
+	RemoteAddr string
 }
 
 // LinkingMetadata is returned by Transaction.GetLinkingMetadata.  It contains
@@ -74,6 +74,8 @@ type crossProcessMetadata struct {
 	PinpointPspanid        int64
 	PinpointSpanid         int64
 	PinpointFlags          string
+	PinpointSampled        string
+	PinpointHost           string
 	PinpointTraceidEncoded []byte
 }
 
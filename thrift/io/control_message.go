@@ -0,0 +1,193 @@
+package io
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// control message type characters
+const (
+	controlTypeNull      byte = 'N'
+	controlTypeBoolTrue  byte = 'T'
+	controlTypeBoolFalse byte = 'F'
+	controlTypeInt       byte = 'I'
+	controlTypeLong      byte = 'L'
+	controlTypeDouble    byte = 'D'
+	controlTypeString    byte = 'S'
+	controlListStart     byte = 'V'
+	controlMapStart      byte = 'M'
+	controlEnd           byte = 'z'
+)
+
+var errControlMessageTruncated = errors.New("control message truncated")
+
+// EncodeControlMessage encodes value with the pinpoint control message
+// format used by handshake packets.  Supported values are nil, bool, int,
+// int32, int64, float64, string, []interface{} and map[string]interface{}.
+// Map keys are written in sorted order.
+func EncodeControlMessage(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := encodeControlValue(&buffer, value)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func encodeControlValue(buffer *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buffer.WriteByte(controlTypeNull)
+	case bool:
+		if v {
+			buffer.WriteByte(controlTypeBoolTrue)
+		} else {
+			buffer.WriteByte(controlTypeBoolFalse)
+		}
+	case int:
+		buffer.WriteByte(controlTypeInt)
+		binary.Write(buffer, binary.BigEndian, int32(v))
+	case int32:
+		buffer.WriteByte(controlTypeInt)
+		binary.Write(buffer, binary.BigEndian, v)
+	case int64:
+		buffer.WriteByte(controlTypeLong)
+		binary.Write(buffer, binary.BigEndian, v)
+	case float64:
+		buffer.WriteByte(controlTypeDouble)
+		binary.Write(buffer, binary.BigEndian, math.Float64bits(v))
+	case string:
+		buffer.WriteByte(controlTypeString)
+		writeControlBytes(buffer, []byte(v))
+	case []interface{}:
+		buffer.WriteByte(controlListStart)
+		for _, item := range v {
+			if err := encodeControlValue(buffer, item); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(controlEnd)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buffer.WriteByte(controlMapStart)
+		for _, key := range keys {
+			encodeControlValue(buffer, key)
+			if err := encodeControlValue(buffer, v[key]); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(controlEnd)
+	default:
+		return fmt.Errorf("unsupported control message value type %T", value)
+	}
+	return nil
+}
+
+// writeControlBytes writes data prefixed with its varint length.
+func writeControlBytes(buffer *bytes.Buffer, data []byte) {
+	lengthBuf := make([]byte, binary.MaxVarintLen32)
+	buffer.Write(lengthBuf[:binary.PutUvarint(lengthBuf, uint64(len(data)))])
+	buffer.Write(data)
+}
+
+// DecodeControlMessage decodes a value written with the pinpoint control
+// message format.  Integers are returned as int32, longs as int64, lists as
+// []interface{} and maps as map[string]interface{}.
+func DecodeControlMessage(data []byte) (interface{}, error) {
+	reader := bytes.NewReader(data)
+	value, err := decodeControlValue(reader)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// errControlEnd is returned by decodeControlValue when the end of a list or
+// map is reached.
+var errControlEnd = errors.New("control message end")
+
+func decodeControlValue(reader *bytes.Reader) (interface{}, error) {
+	typ, err := reader.ReadByte()
+	if err != nil {
+		return nil, errControlMessageTruncated
+	}
+
+	switch typ {
+	case controlTypeNull:
+		return nil, nil
+	case controlTypeBoolTrue:
+		return true, nil
+	case controlTypeBoolFalse:
+		return false, nil
+	case controlTypeInt:
+		var v int32
+		if err := binary.Read(reader, binary.BigEndian, &v); err != nil {
+			return nil, errControlMessageTruncated
+		}
+		return v, nil
+	case controlTypeLong:
+		var v int64
+		if err := binary.Read(reader, binary.BigEndian, &v); err != nil {
+			return nil, errControlMessageTruncated
+		}
+		return v, nil
+	case controlTypeDouble:
+		var v uint64
+		if err := binary.Read(reader, binary.BigEndian, &v); err != nil {
+			return nil, errControlMessageTruncated
+		}
+		return math.Float64frombits(v), nil
+	case controlTypeString:
+		length, err := binary.ReadUvarint(reader)
+		if err != nil || length > uint64(reader.Len()) {
+			return nil, errControlMessageTruncated
+		}
+		data := make([]byte, length)
+		reader.Read(data)
+		return string(data), nil
+	case controlListStart:
+		list := []interface{}{}
+		for {
+			item, err := decodeControlValue(reader)
+			if err == errControlEnd {
+				return list, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+	case controlMapStart:
+		m := make(map[string]interface{})
+		for {
+			key, err := decodeControlValue(reader)
+			if err == errControlEnd {
+				return m, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeControlValue(reader)
+			if err != nil {
+				if err == errControlEnd {
+					err = errControlMessageTruncated
+				}
+				return nil, err
+			}
+			m[fmt.Sprint(key)] = value
+		}
+	case controlEnd:
+		return nil, errControlEnd
+	default:
+		return nil, fmt.Errorf("unknown control message type %q", typ)
+	}
+}
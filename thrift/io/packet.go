@@ -0,0 +1,226 @@
+package io
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// MaxPacketPayloadSize limits the payload size accepted by ReadPacket.
+const MaxPacketPayloadSize = 16 * 1024 * 1024
+
+// Packet is a pinpoint rpc packet read from a tcp connection.
+type Packet struct {
+	Type uint16
+	// RequestID is the request id of request, response and handshake
+	// packets, and the ping id of ping packets.
+	RequestID uint32
+	// StateVersion and StateCode are only set on ping payload packets.
+	StateVersion uint8
+	StateCode    uint8
+	// StreamID is the stream channel id of stream packets, and StreamCode
+	// the code of stream create fail and stream close packets.
+	StreamID   uint32
+	StreamCode int16
+	Payload    []byte
+}
+
+// ReadPacket reads the next packet from reader.
+func ReadPacket(reader io.Reader) (*Packet, error) {
+	packet := &Packet{}
+	err := binary.Read(reader, binary.BigEndian, &packet.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	switch packet.Type {
+	case RequestTypeAppRequest, RequestTypeAppResponse,
+		RequestTypeControlHandshake, RequestTypeControlHandshakeResponse:
+		err = binary.Read(reader, binary.BigEndian, &packet.RequestID)
+		if err != nil {
+			return nil, err
+		}
+		packet.Payload, err = readPayload(reader)
+	case RequestTypeAppSend, RequestTypeControlClientClose, RequestTypeControlServerClose:
+		packet.Payload, err = readPayload(reader)
+	case RequestTypeControlPing, RequestTypeControlPingPayload:
+		err = binary.Read(reader, binary.BigEndian, &packet.RequestID)
+		if err != nil {
+			return nil, err
+		}
+		err = binary.Read(reader, binary.BigEndian, &packet.StateVersion)
+		if err != nil {
+			return nil, err
+		}
+		err = binary.Read(reader, binary.BigEndian, &packet.StateCode)
+	case RequestTypeControlPong, RequestTypeControlPingSimple:
+	case RequestTypeAppStreamCreate, RequestTypeAppStreamResponse:
+		err = binary.Read(reader, binary.BigEndian, &packet.StreamID)
+		if err != nil {
+			return nil, err
+		}
+		packet.Payload, err = readPayload(reader)
+	case RequestTypeAppStreamCreateSuccess:
+		err = binary.Read(reader, binary.BigEndian, &packet.StreamID)
+	case RequestTypeAppStreamCreateFail, RequestTypeAppStreamClose:
+		err = binary.Read(reader, binary.BigEndian, &packet.StreamID)
+		if err != nil {
+			return nil, err
+		}
+		err = binary.Read(reader, binary.BigEndian, &packet.StreamCode)
+	case RequestTypeAppStreamPing, RequestTypeAppStreamPong:
+		err = binary.Read(reader, binary.BigEndian, &packet.StreamID)
+		if err != nil {
+			return nil, err
+		}
+		err = binary.Read(reader, binary.BigEndian, &packet.RequestID)
+	default:
+		return nil, fmt.Errorf("unknown packet type %d", packet.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return packet, nil
+}
+
+func readPayload(reader io.Reader) ([]byte, error) {
+	var length uint32
+	err := binary.Read(reader, binary.BigEndian, &length)
+	if err != nil {
+		return nil, err
+	}
+	if length > MaxPacketPayloadSize {
+		return nil, fmt.Errorf("packet payload too large: %d", length)
+	}
+
+	payload := make([]byte, length)
+	_, err = io.ReadFull(reader, payload)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// encodeRequestPacket encodes packets laid out as
+// type(2) + request id(4) + payload length(4) + payload.
+func encodeRequestPacket(requestType uint16, requestID uint32, payload []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	// request type >H 2
+	err := binary.Write(&buffer, binary.BigEndian, requestType)
+	if err != nil {
+		return nil, err
+	}
+	// message id   >I 4
+	err = binary.Write(&buffer, binary.BigEndian, requestID)
+	if err != nil {
+		return nil, err
+	}
+	// data length  >I 4
+	var length uint32 = uint32(len(payload))
+	err = binary.Write(&buffer, binary.BigEndian, length)
+	if err != nil {
+		return nil, err
+	}
+	// payload
+	_, err = buffer.Write(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// EncodeControlHandshake encodes a handshake packet carrying properties.
+func EncodeControlHandshake(requestID uint32, properties map[string]interface{}) ([]byte, error) {
+	payload, err := EncodeControlMessage(properties)
+	if err != nil {
+		return nil, err
+	}
+	return encodeRequestPacket(RequestTypeControlHandshake, requestID, payload)
+}
+
+// EncodeControlHandshakeResponse encodes a handshake response packet.
+func EncodeControlHandshakeResponse(requestID uint32, code int32, subCode int32) ([]byte, error) {
+	payload, err := EncodeControlMessage(map[string]interface{}{
+		"code":    code,
+		"subCode": subCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return encodeRequestPacket(RequestTypeControlHandshakeResponse, requestID, payload)
+}
+
+// EncodePingPayload encodes a ping packet carrying the socket state.
+func EncodePingPayload(pingID uint32, stateVersion uint8, stateCode uint8) []byte {
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint16(buffer[0:], RequestTypeControlPingPayload)
+	binary.BigEndian.PutUint32(buffer[2:], pingID)
+	buffer[6] = stateVersion
+	buffer[7] = stateCode
+	return buffer
+}
+
+// EncodePong encodes a pong packet.
+func EncodePong() []byte {
+	buffer := make([]byte, 2)
+	binary.BigEndian.PutUint16(buffer, RequestTypeControlPong)
+	return buffer
+}
+
+// EncodeControlClientClose encodes the packet telling the collector the
+// agent closes the connection because it is shutting down.
+func EncodeControlClientClose() []byte {
+	buffer := make([]byte, 6)
+	binary.BigEndian.PutUint16(buffer[0:], RequestTypeControlClientClose)
+	return buffer
+}
+
+// EncodeStreamCreate encodes a stream create packet carrying payload.
+func EncodeStreamCreate(streamID uint32, payload []byte) ([]byte, error) {
+	return encodeRequestPacket(RequestTypeAppStreamCreate, streamID, payload)
+}
+
+// EncodeStreamResponse encodes a stream response packet carrying payload.
+func EncodeStreamResponse(streamID uint32, payload []byte) ([]byte, error) {
+	return encodeRequestPacket(RequestTypeAppStreamResponse, streamID, payload)
+}
+
+// EncodeStreamCreateSuccess encodes the answer to an accepted stream create
+// packet.
+func EncodeStreamCreateSuccess(streamID uint32) []byte {
+	buffer := make([]byte, 6)
+	binary.BigEndian.PutUint16(buffer[0:], RequestTypeAppStreamCreateSuccess)
+	binary.BigEndian.PutUint32(buffer[2:], streamID)
+	return buffer
+}
+
+// EncodeStreamCreateFail encodes the answer to a refused stream create
+// packet.
+func EncodeStreamCreateFail(streamID uint32, code int16) []byte {
+	return encodeStreamCodePacket(RequestTypeAppStreamCreateFail, streamID, code)
+}
+
+// EncodeStreamClose encodes a stream close packet.
+func EncodeStreamClose(streamID uint32, code int16) []byte {
+	return encodeStreamCodePacket(RequestTypeAppStreamClose, streamID, code)
+}
+
+func encodeStreamCodePacket(packetType uint16, streamID uint32, code int16) []byte {
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint16(buffer[0:], packetType)
+	binary.BigEndian.PutUint32(buffer[2:], streamID)
+	binary.BigEndian.PutUint16(buffer[6:], uint16(code))
+	return buffer
+}
+
+// EncodeStreamPong encodes the answer to a stream ping packet.
+func EncodeStreamPong(streamID uint32, requestID uint32) []byte {
+	buffer := make([]byte, 10)
+	binary.BigEndian.PutUint16(buffer[0:], RequestTypeAppStreamPong)
+	binary.BigEndian.PutUint32(buffer[2:], streamID)
+	binary.BigEndian.PutUint32(buffer[6:], requestID)
+	return buffer
+}
@@ -1,5 +1,34 @@
 package io
 
+// Pinpoint rpc packet types.
 const (
-	RequestTypeAppRequest uint16 = 5
+	RequestTypeAppSend     uint16 = 1
+	RequestTypeAppRequest  uint16 = 5
+	RequestTypeAppResponse uint16 = 6
+
+	RequestTypeAppStreamCreate        uint16 = 10
+	RequestTypeAppStreamCreateSuccess uint16 = 12
+	RequestTypeAppStreamCreateFail    uint16 = 14
+	RequestTypeAppStreamClose         uint16 = 15
+	RequestTypeAppStreamPing          uint16 = 17
+	RequestTypeAppStreamPong          uint16 = 18
+	RequestTypeAppStreamResponse      uint16 = 20
+
+	RequestTypeControlClientClose       uint16 = 100
+	RequestTypeControlServerClose       uint16 = 110
+	RequestTypeControlHandshake         uint16 = 150
+	RequestTypeControlHandshakeResponse uint16 = 151
+	RequestTypeControlPing              uint16 = 200
+	RequestTypeControlPong              uint16 = 201
+	RequestTypeControlPingSimple        uint16 = 210
+	RequestTypeControlPingPayload       uint16 = 211
+)
+
+// Stream codes sent with stream create fail and stream close packets.
+const (
+	StreamCodeOK            int16 = 0
+	StreamCodeIDNotFound    int16 = 113
+	StreamCodeStateClosed   int16 = 122
+	StreamCodeTypeUnknown   int16 = 131
+	StreamCodeTypeUnsupport int16 = 132
 )
@@ -3,6 +3,7 @@ package io
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 
 	"git.apache.org/thrift.git/lib/go/thrift"
 )
@@ -52,6 +53,17 @@ func EncodeTstruct(ttype uint16, tstruct thrift.TStruct) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// EncodedSize returns the size of tstruct written with the compact protocol,
+// which is also its size as an element of a list.
+func EncodedSize(tstruct thrift.TStruct) (int, error) {
+	tmem := thrift.NewTMemoryBuffer()
+	err := tstruct.Write(thrift.NewTCompactProtocol(tmem))
+	if err != nil {
+		return 0, err
+	}
+	return tmem.Len(), nil
+}
+
 // EncodeTCPTTstuctRequest ...
 func EncodeTCPTTstuctRequest(messageID uint32, ttype uint16, tstruct thrift.TStruct) ([]byte, error) {
 	tstructData, err := EncodeTstruct(ttype, tstruct)
@@ -59,29 +71,56 @@ func EncodeTCPTTstuctRequest(messageID uint32, ttype uint16, tstruct thrift.TStr
 		return nil, err
 	}
 
-	// buffer := bytes.NewBuffer(make([]byte, 10))
-	var buffer bytes.Buffer
-	// request type >H 2
-	err = binary.Write(&buffer, binary.BigEndian, RequestTypeAppRequest)
+	return EncodeTCPRequest(messageID, tstructData)
+}
+
+// EncodeTCPRequest wraps tstructData, the output of EncodeTstruct, in a
+// request packet with messageID.
+func EncodeTCPRequest(messageID uint32, tstructData []byte) ([]byte, error) {
+	return encodeRequestPacket(RequestTypeAppRequest, messageID, tstructData)
+}
+
+// EncodeTCPTStructResponse encodes tstruct as the response to the request
+// with messageID.
+func EncodeTCPTStructResponse(messageID uint32, ttype uint16, tstruct thrift.TStruct) ([]byte, error) {
+	tstructData, err := EncodeTstruct(ttype, tstruct)
 	if err != nil {
 		return nil, err
 	}
-	// message id   >I 4
-	err = binary.Write(&buffer, binary.BigEndian, messageID)
+
+	return encodeRequestPacket(RequestTypeAppResponse, messageID, tstructData)
+}
+
+// DecodeTstruct decodes data written by EncodeTstruct.
+func DecodeTstruct(data []byte) (thrift.TStruct, error) {
+	reader := bytes.NewReader(data)
+	var signature int8
+	err := binary.Read(reader, binary.BigEndian, &signature)
 	if err != nil {
 		return nil, err
 	}
-	// data length  >I 4
-	var length uint32 = uint32(len(tstructData))
-	err = binary.Write(&buffer, binary.BigEndian, length)
+	if signature != Signature {
+		return nil, fmt.Errorf("invalid signature %d", signature)
+	}
+	var version uint8
+	err = binary.Read(reader, binary.BigEndian, &version)
 	if err != nil {
 		return nil, err
 	}
-	// tstruct data
-	_, err = buffer.Write(tstructData)
+	var ttype uint16
+	err = binary.Read(reader, binary.BigEndian, &ttype)
 	if err != nil {
 		return nil, err
 	}
 
-	return buffer.Bytes(), nil
+	tstruct, err := NewTStruct(ttype)
+	if err != nil {
+		return nil, err
+	}
+	tmem := thrift.TMemoryBuffer{Buffer: bytes.NewBuffer(data[len(data)-reader.Len():])}
+	err = tstruct.Read(thrift.NewTCompactProtocol(&tmem))
+	if err != nil {
+		return nil, err
+	}
+	return tstruct, nil
 }
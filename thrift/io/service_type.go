@@ -5,7 +5,9 @@ const (
 	ServiceTypeUnkonwnDB         = 2050
 	ServiceTypeMysql             = 2100
 	ServiceTypeMysqlExecuteQuery = 2101
+	ServiceTypeMssql             = 2200
 	ServiceTypeOracle            = 2300
+	ServiceTypePostgresql        = 2500
 
 	ServiceTypePython             = 1550
 	ServiceTypePythonMethod       = 1551
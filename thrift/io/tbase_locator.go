@@ -1,5 +1,14 @@
 package io
 
+import (
+	"fmt"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/command"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/pinpoint"
+	"github.com/dingyalin/pinpoint-go-agent/thrift/dto/trace"
+)
+
 const (
 	TTypeSpan           = 40
 	TTypeAgentInfo      = 50
@@ -7,5 +16,70 @@ const (
 	TTypeAgentStatBatch = 56
 	TTypeSpanChunk      = 70
 	TTypeSpanEvent      = 80
+	TTypeSQLMetadata    = 300
 	TTypeAPIMetadata    = 310
+	TTypeResult         = 320
+	TTypeStringMetadata = 330
+
+	TTypeCommandTransfer             = 700
+	TTypeCommandTransferResponse     = 701
+	TTypeCommandEcho                 = 710
+	TTypeCommandThreadDump           = 720
+	TTypeCommandThreadDumpResponse   = 721
+	TTypeCmdActiveThreadCount        = 730
+	TTypeCmdActiveThreadCountRes     = 731
+	TTypeCmdActiveThreadDump         = 740
+	TTypeCmdActiveThreadDumpRes      = 741
+	TTypeCmdActiveThreadLightDump    = 750
+	TTypeCmdActiveThreadLightDumpRes = 751
 )
+
+// NewTStruct returns an empty TStruct for ttype.
+func NewTStruct(ttype uint16) (thrift.TStruct, error) {
+	switch ttype {
+	case TTypeSpan:
+		return trace.NewTSpan(), nil
+	case TTypeAgentInfo:
+		return pinpoint.NewTAgentInfo(), nil
+	case TTypeAgentStat:
+		return pinpoint.NewTAgentStat(), nil
+	case TTypeAgentStatBatch:
+		return pinpoint.NewTAgentStatBatch(), nil
+	case TTypeSpanChunk:
+		return trace.NewTSpanChunk(), nil
+	case TTypeSpanEvent:
+		return trace.NewTSpanEvent(), nil
+	case TTypeSQLMetadata:
+		return trace.NewTSqlMetaData(), nil
+	case TTypeAPIMetadata:
+		return trace.NewTApiMetaData(), nil
+	case TTypeResult:
+		return trace.NewTResult_(), nil
+	case TTypeStringMetadata:
+		return trace.NewTStringMetaData(), nil
+	case TTypeCommandTransfer:
+		return command.NewTCommandTransfer(), nil
+	case TTypeCommandTransferResponse:
+		return command.NewTCommandTransferResponse(), nil
+	case TTypeCommandEcho:
+		return command.NewTCommandEcho(), nil
+	case TTypeCommandThreadDump:
+		return command.NewTCommandThreadDump(), nil
+	case TTypeCommandThreadDumpResponse:
+		return command.NewTCommandThreadDumpResponse(), nil
+	case TTypeCmdActiveThreadCount:
+		return command.NewTCmdActiveThreadCount(), nil
+	case TTypeCmdActiveThreadCountRes:
+		return command.NewTCmdActiveThreadCountRes(), nil
+	case TTypeCmdActiveThreadDump:
+		return command.NewTCmdActiveThreadDump(), nil
+	case TTypeCmdActiveThreadDumpRes:
+		return command.NewTCmdActiveThreadDumpRes(), nil
+	case TTypeCmdActiveThreadLightDump:
+		return command.NewTCmdActiveThreadLightDump(), nil
+	case TTypeCmdActiveThreadLightDumpRes:
+		return command.NewTCmdActiveThreadLightDumpRes(), nil
+	default:
+		return nil, fmt.Errorf("unknown tstruct type %d", ttype)
+	}
+}